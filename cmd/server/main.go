@@ -7,7 +7,10 @@ import (
 	"delivery-control/internal/api/handlers"
 	"delivery-control/internal/api/routes"
 	"delivery-control/internal/config"
+	"delivery-control/internal/logging"
+	"delivery-control/internal/repository"
 	"delivery-control/internal/services"
+	"delivery-control/internal/webhook"
 
 	"github.com/joho/godotenv"
 	"github.com/labstack/echo/v4"
@@ -20,24 +23,71 @@ func main() {
 	// Carrega a configuração
 	cfg := config.Load()
 
+	// Configura o destino dos logs antes de iniciar os serviços para capturar os logs de inicialização
+	logOutput := logging.Setup(cfg.Log)
+
 	// Valida a configuração obrigatória
-	if cfg.Auth.BearerToken == "" {
-		log.Fatal("A variábel de ambiente BEARER_TOKEN é obrigatória")
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("Configuração inválida: %v", err)
+	}
+	if err := cfg.Auth.Err(); err != nil {
+		log.Printf("AVISO: rotas protegidas em manutenção (AUTH_FAIL_MODE=maintenance): %v", err)
+	}
+
+	// Inicializa os repositórios e os serviços
+	repositories, err := repository.New(cfg.Storage)
+	if err != nil {
+		log.Fatalf("Erro ao inicializar os repositórios: %v", err)
+	}
+	platformService := services.NewPlatformService(cfg, repositories)
+
+	// Pré-carrega os catálogos em segundo plano, sem bloquear a inicialização
+	if cfg.Cache.WarmupOnStart {
+		platformService.WarmUp()
+	}
+
+	idMapper, err := services.NewIDMapper(cfg.IDMapPath)
+	if err != nil {
+		log.Fatalf("Erro ao carregar o mapeamento de IDs: %v", err)
 	}
 
-	// Inicializa os serviços
-	platformService := services.NewPlatformService(cfg)
+	webhooks := webhook.New(cfg.Webhook)
+	if cfg.Webhook.URL != "" {
+		log.Printf("Webhooks habilitados (modo %s)", cfg.Webhook.Mode)
+	}
+
+	maintenance := services.NewMaintenanceMode(cfg.Server.Maintenance)
 
 	// Inicializa os handlers
-	healthHandler := handlers.NewHealthHandler()
-	storeHandler := handlers.NewStoreHandler(platformService)
+	healthHandler := handlers.NewHealthHandler(maintenance)
+	storeHandler := handlers.NewStoreHandler(platformService, idMapper, webhooks)
+	cacheHandler := handlers.NewCacheHandler(platformService)
+	webhookHandler := handlers.NewWebhookHandler(webhooks)
 	docsHandler := handlers.NewDocsHandler()
+	adminHandler := handlers.NewAdminHandler(maintenance)
+
+	// Verifica se a documentação pode ser servida (não impede a inicialização)
+	if !cfg.Docs.Enabled {
+		log.Printf("Documentação desabilitada (DOCS_ENABLED=false)")
+	} else if err := docsHandler.CheckOpenAPI(); err != nil {
+		log.Printf("AVISO: /docs ficará indisponível: %v", err)
+	}
+
+	if cfg.Debug.Endpoints {
+		log.Printf("AVISO: endpoints de diagnóstico habilitados (DEBUG_ENDPOINTS=true)")
+	}
+	if cfg.Debug.AllowURLOverride {
+		log.Printf("AVISO: substituição da URL das plataformas habilitada (ALLOW_URL_OVERRIDE=true); não use em produção")
+	}
 
 	// Cria a instância do Echo
 	e := echo.New()
+	if cfg.Log.File != "" {
+		e.Logger.SetOutput(logOutput)
+	}
 
 	// Configura as rotas
-	routes.SetupRoutes(e, cfg, healthHandler, storeHandler, docsHandler)
+	routes.SetupRoutes(e, cfg, healthHandler, storeHandler, cacheHandler, webhookHandler, docsHandler, adminHandler)
 
 	// Inicia o servidor
 	address := fmt.Sprintf(":%s", cfg.Server.Port)
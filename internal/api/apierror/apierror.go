@@ -0,0 +1,83 @@
+package apierror
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"delivery-control/internal/models"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Respond escreve uma resposta de erro padronizada com o status HTTP informado
+func Respond(c echo.Context, statusCode int, tipoErro models.TipoErro, mensagem string) error {
+	return JSON(c, statusCode, models.RespostaErro{
+		Error:    tipoErro,
+		Mensagem: mensagem,
+	})
+}
+
+// JSON escreve a resposta de erro preenchendo o timestamp e o ID da requisição,
+// permitindo que o cliente informe esses dados ao reportar o problema
+func JSON(c echo.Context, statusCode int, resposta models.RespostaErro) error {
+	resposta.Timestamp = time.Now().UTC().Format(time.RFC3339)
+	resposta.RequestID = c.Response().Header().Get(echo.HeaderXRequestID)
+	return c.JSON(statusCode, resposta)
+}
+
+// Handler é o HTTPErrorHandler do Echo. Converte os erros não tratados pelos handlers
+// (rotas inexistentes, métodos não permitidos, panics recuperados) no formato RespostaErro
+func Handler(err error, c echo.Context) {
+	if c.Response().Committed {
+		return
+	}
+
+	statusCode := http.StatusInternalServerError
+	mensagem := "Erro interno do servidor"
+
+	var httpErr *echo.HTTPError
+	if errors.As(err, &httpErr) {
+		statusCode = httpErr.Code
+		mensagem = fmt.Sprint(httpErr.Message)
+	}
+
+	if statusCode >= http.StatusInternalServerError {
+		log.Printf("[Error] request_id=%s metodo=%s rota=%s erro=%v",
+			c.Response().Header().Get(echo.HeaderXRequestID), c.Request().Method, c.Path(), err)
+	}
+
+	if writeErr := Respond(c, statusCode, tipoErroForStatus(statusCode), mensagem); writeErr != nil {
+		log.Printf("[Error] Falha ao escrever resposta de erro: %v", writeErr)
+	}
+}
+
+// tipoErroForStatus retorna o tipo de erro correspondente ao status HTTP
+func tipoErroForStatus(statusCode int) models.TipoErro {
+	switch {
+	case statusCode == http.StatusUnauthorized:
+		return models.ErroNaoAutorizado
+	case statusCode == http.StatusForbidden:
+		return models.ErroProibido
+	case statusCode == http.StatusNotFound:
+		return models.ErroNaoEncontrado
+	case statusCode == http.StatusConflict:
+		return models.ErroConflito
+	case statusCode == http.StatusPreconditionRequired:
+		return models.ErroConfirmacaoNecessaria
+	case statusCode == http.StatusUnprocessableEntity:
+		return models.ErroEntidadeNaoProcessavel
+	case statusCode == http.StatusBadGateway:
+		return models.ErroBadGateway
+	case statusCode == http.StatusServiceUnavailable:
+		return models.ErroServicoIndisponivel
+	case statusCode == http.StatusGatewayTimeout:
+		return models.ErroTempoEsgotado
+	case statusCode >= http.StatusInternalServerError:
+		return models.ErroInternoServidor
+	default:
+		return models.ErroRequisicaoInvalida
+	}
+}
@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"net/http"
+
+	"delivery-control/internal/api/apierror"
+	"delivery-control/internal/models"
+	"delivery-control/internal/services"
+
+	"github.com/labstack/echo/v4"
+)
+
+// AdminHandler gerencia requisições administrativas da API, como o modo de manutenção
+type AdminHandler struct {
+	maintenance *services.MaintenanceMode
+}
+
+// NewAdminHandler cria um novo handler administrativo
+func NewAdminHandler(maintenance *services.MaintenanceMode) *AdminHandler {
+	return &AdminHandler{
+		maintenance: maintenance,
+	}
+}
+
+// GetMaintenance gerencia GET /admin/manutencao
+func (h *AdminHandler) GetMaintenance(c echo.Context) error {
+	return c.JSON(http.StatusOK, h.maintenance.State())
+}
+
+// SetMaintenance gerencia POST /admin/manutencao
+// Ativa ({"ativo": true, "motivo": "..."}) ou desativa ({"ativo": false}) o modo de manutenção
+func (h *AdminHandler) SetMaintenance(c echo.Context) error {
+	var req models.RequisicaoManutencao
+	if err := c.Bind(&req); err != nil {
+		return apierror.Respond(c, http.StatusBadRequest, models.ErroRequisicaoInvalida, "Body da requisição inválido: "+err.Error())
+	}
+	if req.Ativo == nil {
+		return apierror.Respond(c, http.StatusBadRequest, models.ErroRequisicaoInvalida, "Campo 'ativo' é obrigatório")
+	}
+
+	return c.JSON(http.StatusOK, h.maintenance.Set(*req.Ativo, req.Motivo))
+}
+
+// RejectDuringMaintenance recusa com 503 as rotas que alteram lojas enquanto o modo de
+// manutenção está ativo, sem chamar a plataforma
+func (h *AdminHandler) RejectDuringMaintenance(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if !h.maintenance.Active() {
+			return next(c)
+		}
+
+		mensagem := "API em manutenção: operações de ativação e desativação estão suspensas"
+		if motivo := h.maintenance.State().Motivo; motivo != "" {
+			mensagem += " (" + motivo + ")"
+		}
+		return apierror.Respond(c, http.StatusServiceUnavailable, models.ErroServicoIndisponivel, mensagem)
+	}
+}
@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"net/http"
+
+	"delivery-control/internal/api/apierror"
+	"delivery-control/internal/models"
+	"delivery-control/internal/services"
+
+	"github.com/labstack/echo/v4"
+)
+
+// CacheHandler gerencia requisições administrativas do cache de status
+type CacheHandler struct {
+	platformService *services.PlatformService
+}
+
+// NewCacheHandler cria um novo handler de cache
+func NewCacheHandler(platformService *services.PlatformService) *CacheHandler {
+	return &CacheHandler{
+		platformService: platformService,
+	}
+}
+
+// Clear gerencia POST /plataformas/{plataforma}/cache/limpar e POST /cache/limpar
+// Sem o parâmetro plataforma, limpa o cache de todas as plataformas
+func (h *CacheHandler) Clear(c echo.Context) error {
+	response, err := h.platformService.ClearStatusCache(models.Plataforma(c.Param("plataforma")))
+	if err != nil {
+		return apierror.Respond(c, http.StatusNotFound, models.ErroNaoEncontrado, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
@@ -1,11 +1,16 @@
 package handlers
 
 import (
+	"fmt"
 	"net/http"
+	"os"
 
 	"github.com/labstack/echo/v4"
 )
 
+// openAPIPath é o caminho do arquivo de especificação OpenAPI servido em /docs/openapi.yml
+const openAPIPath = "docs/openapi.yml"
+
 // DocsHandler gerencia requisições de documentação
 type DocsHandler struct{}
 
@@ -49,5 +54,24 @@ func (h *DocsHandler) ServeHTML(c echo.Context) error {
 // ServeOpenAPI gerencia GET /docs/openapi.yml
 func (h *DocsHandler) ServeOpenAPI(c echo.Context) error {
 	c.Response().Header().Set("Content-Type", "application/x-yaml")
-	return c.File("docs/openapi.yml")
+	return c.File(openAPIPath)
+}
+
+// CheckOpenAPI verifica se o arquivo OpenAPI existe e pode ser lido
+func (h *DocsHandler) CheckOpenAPI() error {
+	file, err := os.Open(openAPIPath)
+	if err != nil {
+		return fmt.Errorf("arquivo de documentação indisponível: %w", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("erro ao inspecionar arquivo de documentação: %w", err)
+	}
+	if info.IsDir() || info.Size() == 0 {
+		return fmt.Errorf("arquivo de documentação inválido: %s", openAPIPath)
+	}
+
+	return nil
 }
@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// lojaFields são os campos do DTO Loja que podem ser selecionados com ?fields=
+var lojaFields = []string{"id", "id_plataforma", "plataforma", "nome_fantasia", "documento", "documento_tipo", "status", "is_active", "versao", "detalhes", "observacao"}
+
+// statusFields são os campos de StatusLojaDetalhes que podem ser selecionados com ?fields= e ?formato=legado
+var statusFields = []string{"id_loja", "id_plataforma", "status", "documento", "nome_fantasia", "versao", "detalhes"}
+
+// parseFields separa os campos solicitados em conhecidos (entre os permitidos) e desconhecidos, preservando a ordem
+func parseFields(value string, permitidos []string) ([]string, []string) {
+	var known, unknown []string
+	for _, field := range parseIDList(value) {
+		if slices.Contains(permitidos, field) {
+			known = append(known, field)
+		} else {
+			unknown = append(unknown, field)
+		}
+	}
+	return known, unknown
+}
+
+// selectFields serializa apenas os campos solicitados de cada loja. Campos omitidos pelo
+// formato padrão (ex.: id_plataforma vazio) continuam omitidos mesmo quando solicitados
+func selectFields[T any](lojas []T, fields []string) ([]map[string]json.RawMessage, error) {
+	selecionadas := make([]map[string]json.RawMessage, 0, len(lojas))
+	for i, loja := range lojas {
+		data, err := json.Marshal(loja)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao serializar loja %d: %w", i, err)
+		}
+
+		var completa map[string]json.RawMessage
+		if err := json.Unmarshal(data, &completa); err != nil {
+			return nil, fmt.Errorf("erro ao serializar loja %d: %w", i, err)
+		}
+
+		selecionada := make(map[string]json.RawMessage, len(fields))
+		for _, field := range fields {
+			if value, ok := completa[field]; ok {
+				selecionada[field] = value
+			}
+		}
+		selecionadas = append(selecionadas, selecionada)
+	}
+	return selecionadas, nil
+}
+
+// ignoredFieldsWarning monta o header Warning informando os campos desconhecidos ignorados
+func ignoredFieldsWarning(unknown []string) string {
+	return fmt.Sprintf(`299 - "campos desconhecidos ignorados: %s"`, strings.Join(unknown, ","))
+}
@@ -3,24 +3,38 @@ package handlers
 import (
 	"net/http"
 
+	"delivery-control/internal/metrics"
 	"delivery-control/internal/models"
+	"delivery-control/internal/services"
 
 	"github.com/labstack/echo/v4"
 )
 
 // HealthHandler gerencia requisições de verificação de saúde
-type HealthHandler struct{}
+type HealthHandler struct {
+	maintenance *services.MaintenanceMode
+}
 
 // NewHealthHandler cria um novo handler de saúde
-func NewHealthHandler() *HealthHandler {
-	return &HealthHandler{}
+func NewHealthHandler(maintenance *services.MaintenanceMode) *HealthHandler {
+	return &HealthHandler{
+		maintenance: maintenance,
+	}
 }
 
 // Check gerencia GET /health
 func (h *HealthHandler) Check(c echo.Context) error {
 	response := models.RespostaSaude{
-		Status: "ok",
+		Status:     "ok",
+		Manutencao: h.maintenance.Active(),
 	}
 
 	return c.JSON(http.StatusOK, response)
 }
+
+// Stats gerencia GET /stats
+// Retorna em JSON os contadores também expostos em /metrics (operações por plataforma, idade dos
+// tokens e taxa de acerto do cache), para ambientes sem Prometheus
+func (h *HealthHandler) Stats(c echo.Context) error {
+	return c.JSON(http.StatusOK, metrics.TakeSnapshot())
+}
@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"delivery-control/internal/api/apierror"
+	"delivery-control/internal/api/middleware"
+	"delivery-control/internal/config"
+	"delivery-control/internal/models"
+	"delivery-control/internal/services"
+
+	"github.com/labstack/echo/v4"
+)
+
+// HeaderPlatformBaseURL direciona a requisição para outra URL da plataforma (ex.: sandbox)
+const HeaderPlatformBaseURL = "X-Platform-Base-URL"
+
+// platformServiceKey guarda no contexto o serviço escolhido por X-Platform-Base-URL
+const platformServiceKey = "platform_service"
+
+// PlatformURLOverride é o middleware que aplica o header X-Platform-Base-URL. Só é aceito com
+// ALLOW_URL_OVERRIDE=true, de tokens com escopo write e em rotas de uma plataforma; nos demais casos
+// a requisição é recusada, para que o cliente nunca opere em produção achando que está usando a sandbox
+func (sh *StoreHandler) PlatformURLOverride(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		baseURL := c.Request().Header.Get(HeaderPlatformBaseURL)
+		if baseURL == "" {
+			return next(c)
+		}
+
+		if scope, _ := c.Get(middleware.ScopeKey).(string); scope != config.ScopeWrite {
+			return apierror.Respond(c, http.StatusForbidden, models.ErroProibido, "Header "+HeaderPlatformBaseURL+" exige um token com escopo "+config.ScopeWrite)
+		}
+
+		plataforma := models.Plataforma(c.Param("plataforma"))
+		if plataforma == "" {
+			return apierror.Respond(c, http.StatusBadRequest, models.ErroRequisicaoInvalida, "Header "+HeaderPlatformBaseURL+" só é aceito em rotas de uma plataforma")
+		}
+
+		platformService, err := sh.platformService.WithBaseURL(plataforma, baseURL)
+		if err != nil {
+			return apierror.Respond(c, http.StatusBadRequest, models.ErroRequisicaoInvalida, err.Error())
+		}
+
+		log.Printf("[Override] %s %s direcionada para %s", c.Request().Method, c.Request().URL.Path, baseURL)
+		c.Set(platformServiceKey, platformService)
+		return next(c)
+	}
+}
+
+// service retorna o serviço de plataforma da requisição: o escolhido por X-Platform-Base-URL,
+// quando informado, ou o serviço padrão
+func (sh *StoreHandler) service(c echo.Context) *services.PlatformService {
+	if platformService, ok := c.Get(platformServiceKey).(*services.PlatformService); ok {
+		return platformService
+	}
+	return sh.platformService
+}
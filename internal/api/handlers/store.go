@@ -1,166 +1,1525 @@
 package handlers
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"log"
+	"maps"
 	"net/http"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
+	"delivery-control/internal/api/apierror"
 	"delivery-control/internal/models"
 	"delivery-control/internal/services"
+	"delivery-control/internal/utils"
+	"delivery-control/internal/webhook"
 
 	"github.com/labstack/echo/v4"
+	"golang.org/x/sync/errgroup"
 )
 
 // StoreHandler gerencia requisições relacionadas às lojas
 type StoreHandler struct {
 	platformService *services.PlatformService
+	idMapper        *services.IDMapper
+	webhooks        *webhook.Notifier
 }
 
 // NewStoreHandler cria um novo handler de loja
-func NewStoreHandler(platformService *services.PlatformService) *StoreHandler {
+func NewStoreHandler(platformService *services.PlatformService, idMapper *services.IDMapper, webhooks *webhook.Notifier) *StoreHandler {
 	return &StoreHandler{
 		platformService: platformService,
+		idMapper:        idMapper,
+		webhooks:        webhooks,
 	}
 }
 
+// toPlatformIDs traduz os IDs recebidos para os IDs da plataforma, retornando também
+// o mapa reverso usado para devolver ao cliente os mesmos IDs que ele enviou
+func (sh *StoreHandler) toPlatformIDs(plataforma models.Plataforma, ids []string) ([]string, map[string]string) {
+	platformIDs := make([]string, 0, len(ids))
+	originais := make(map[string]string, len(ids))
+	for _, id := range ids {
+		platformID := sh.idMapper.ToPlatform(plataforma, id)
+		platformIDs = append(platformIDs, platformID)
+		originais[platformID] = id
+	}
+	return platformIDs, originais
+}
+
+// fromPlatformID devolve o ID enviado pelo cliente ou, se não houver, o ID interno mapeado
+func (sh *StoreHandler) fromPlatformID(plataforma models.Plataforma, originais map[string]string, id string) string {
+	if original, ok := originais[id]; ok {
+		return original
+	}
+	return sh.idMapper.FromPlatform(plataforma, id)
+}
+
+// resolveIDs retorna o ID a ser devolvido ao cliente e, quando for diferente dele, o ID usado na plataforma
+func (sh *StoreHandler) resolveIDs(plataforma models.Plataforma, originais map[string]string, platformID string) (string, string) {
+	original := sh.fromPlatformID(plataforma, originais, platformID)
+	if original == platformID {
+		return original, ""
+	}
+	return original, platformID
+}
+
 // handlePlatformError trata erros específicos das plataformas
 func (sh *StoreHandler) handlePlatformError(c echo.Context, err error) error {
+	// Verifica se é erro de plataforma não suportada
+	if err.Error() == "plataforma não suportada: "+c.Param("plataforma") {
+		return apierror.Respond(c, http.StatusNotFound, models.ErroNaoEncontrado, err.Error())
+	}
+
+	statusCode, resposta := platformErrorResponse(err)
+	// Com ?verbose=true, identifica a chamada à plataforma que falhou
+	if c.QueryParam("verbose") == "true" {
+		resposta.Chamada = services.PlatformCall(err)
+	}
+	return apierror.JSON(c, statusCode, resposta)
+}
+
+// platformErrorResponse converte um erro de plataforma no status HTTP e corpo de erro correspondentes
+func platformErrorResponse(err error) (int, models.RespostaErro) {
+	// O prazo pedido pelo cliente em X-Request-Timeout terminou antes da resposta da plataforma. Vem antes dos
+	// erros das plataformas, que podem embrulhar o context.DeadlineExceeded da chamada interrompida
+	if errors.Is(err, services.ErrPrazoEsgotado) {
+		return http.StatusGatewayTimeout, models.RespostaErro{
+			Error:    models.ErroTempoEsgotado,
+			Mensagem: "Tempo limite da requisição excedido antes da resposta da plataforma",
+		}
+	}
+
 	// Verifica se é um erro específico do DeliveryVip
 	var deliveryVipErr *services.DeliveryVipError
 	if errors.As(err, &deliveryVipErr) {
-		var statusCode int
-		switch deliveryVipErr.TipoErro {
-		case models.ErroNaoEncontrado:
-			statusCode = http.StatusNotFound
-		case models.ErroNaoAutorizado:
-			statusCode = http.StatusUnauthorized
-		case models.ErroRequisicaoInvalida:
-			statusCode = http.StatusBadRequest
-		default:
-			statusCode = http.StatusBadGateway
+		return statusForTipoErro(deliveryVipErr.TipoErro), models.RespostaErro{
+			Error:            deliveryVipErr.TipoErro,
+			Mensagem:         deliveryVipErr.Mensagem,
+			CodigoPlataforma: deliveryVipErr.Codigo,
 		}
+	}
 
-		return c.JSON(statusCode, models.RespostaErro{
-			Error:    deliveryVipErr.TipoErro,
-			Mensagem: deliveryVipErr.Mensagem,
-		})
+	// Verifica se é um erro específico do AnotaAI
+	var anotaAiErr *services.AnotaAiError
+	if errors.As(err, &anotaAiErr) {
+		return statusForTipoErro(anotaAiErr.TipoErro), models.RespostaErro{
+			Error:            anotaAiErr.TipoErro,
+			Mensagem:         anotaAiErr.Mensagem,
+			CodigoPlataforma: anotaAiErr.MensagemPlataforma,
+		}
 	}
 
-	// Verifica se é erro de plataforma não suportada
-	if err.Error() == "plataforma não suportada: "+c.Param("plataforma") {
-		return c.JSON(http.StatusNotFound, models.RespostaErro{
+	// Operação não oferecida pela plataforma - não chega a ser enviada
+	if errors.Is(err, services.ErrOperacaoNaoSuportada) {
+		return http.StatusNotImplemented, models.RespostaErro{
+			Error:    models.ErroOperacaoNaoSuportada,
+			Mensagem: err.Error(),
+		}
+	}
+
+	// Loja protegida contra desativação - não chega a ser enviada
+	if errors.Is(err, services.ErrLojaProtegida) {
+		return http.StatusForbidden, models.RespostaErro{
+			Error:    models.ErroProibido,
+			Mensagem: err.Error(),
+		}
+	}
+
+	// ID malformado - não chega a ser enviado
+	if errors.Is(err, services.ErrIDInvalido) {
+		return http.StatusBadRequest, models.RespostaErro{
+			Error:    models.ErroRequisicaoInvalida,
+			Mensagem: err.Error(),
+		}
+	}
+
+	// A loja mudou desde a versão informada em If-Match
+	if errors.Is(err, services.ErrVersaoDivergente) {
+		return http.StatusConflict, models.RespostaErro{
+			Error:    models.ErroConflito,
+			Mensagem: err.Error(),
+		}
+	}
+
+	// Loja ausente na listagem da plataforma
+	if errors.Is(err, services.ErrLojaNaoEncontrada) {
+		return http.StatusNotFound, models.RespostaErro{
 			Error:    models.ErroNaoEncontrado,
 			Mensagem: err.Error(),
-		})
+		}
+	}
+
+	// Plataforma ainda sem token (primeiro login pendente ou falhando) - indisponibilidade temporária
+	if errors.Is(err, services.ErrTokenIndisponivel) {
+		return http.StatusServiceUnavailable, models.RespostaErro{
+			Error:    models.ErroServicoIndisponivel,
+			Mensagem: "Plataforma temporariamente indisponível: " + err.Error(),
+		}
+	}
+
+	// Configuração inválida da plataforma - não é falha de comunicação
+	if errors.Is(err, services.ErrPlataformaMalConfigurada) {
+		return http.StatusInternalServerError, models.RespostaErro{
+			Error:    models.ErroInternoServidor,
+			Mensagem: err.Error(),
+		}
 	}
 
 	// Erro genérico - bad gateway
-	return c.JSON(http.StatusBadGateway, models.RespostaErro{
+	return http.StatusBadGateway, models.RespostaErro{
 		Error:    models.ErroBadGateway,
 		Mensagem: "Erro ao comunicar com a plataforma: " + err.Error(),
-	})
+	}
+}
+
+// statusForTipoErro retorna o status HTTP correspondente ao tipo de erro reportado pela plataforma
+func statusForTipoErro(tipoErro models.TipoErro) int {
+	switch tipoErro {
+	case models.ErroNaoEncontrado:
+		return http.StatusNotFound
+	case models.ErroNaoAutorizado:
+		return http.StatusUnauthorized
+	case models.ErroRequisicaoInvalida:
+		return http.StatusBadRequest
+	case models.ErroEntidadeNaoProcessavel:
+		return http.StatusUnprocessableEntity
+	case models.ErroServicoIndisponivel:
+		return http.StatusServiceUnavailable
+	case models.ErroTempoEsgotado:
+		return http.StatusGatewayTimeout
+	default:
+		return http.StatusBadGateway
+	}
 }
 
 // handleBulkOperation gerencia operações em lote (ativar/desativar) com validação comum
-func (sh *StoreHandler) handleBulkOperation(c echo.Context, operation func(string, []string) (*models.RespostaOperacaoMultiplasLojas, error)) error {
+func (sh *StoreHandler) handleBulkOperation(c echo.Context, operacao models.Operacao, operation func(plataforma string, idsLojas []string, motivo string) (*models.RespostaOperacaoMultiplasLojas, error)) error {
 	plataforma := models.Plataforma(c.Param("plataforma"))
 
 	// Valida parâmetro obrigatório
 	if plataforma == "" {
-		return c.JSON(http.StatusBadRequest, models.RespostaErro{
-			Error:    models.ErroRequisicaoInvalida,
-			Mensagem: "Parâmetro plataforma é obrigatório",
-		})
+		return apierror.Respond(c, http.StatusBadRequest, models.ErroRequisicaoInvalida, "Parâmetro plataforma é obrigatório")
 	}
 
-	// Decodifica o body da requisição
-	var req models.RequisicaoMultiplasLojas
-	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, models.RespostaErro{
-			Error:    models.ErroRequisicaoInvalida,
-			Mensagem: "Body da requisição inválido: " + err.Error(),
-		})
+	formato, ok := parseFormato(c.QueryParam("formato"))
+	if !ok {
+		return apierror.Respond(c, http.StatusBadRequest, models.ErroRequisicaoInvalida, invalidFormatoMessage)
+	}
+
+	// Decodifica o body da requisição (JSON ou arquivo de IDs)
+	req, err := bindBulkRequest(c)
+	if err != nil {
+		return apierror.Respond(c, http.StatusBadRequest, models.ErroRequisicaoInvalida, "Body da requisição inválido: "+err.Error())
 	}
 
-	// Valida se há IDs no body
+	prioridades, err := mergePriorityGroups(&req)
+	if err != nil {
+		return apierror.Respond(c, http.StatusBadRequest, models.ErroRequisicaoInvalida, err.Error())
+	}
+
+	// Sem IDs no body, aceita os IDs separados por vírgula no query param "ids"
+	// (o body tem precedência quando ambos são informados)
 	if len(req.IdsLojas) == 0 {
-		return c.JSON(http.StatusBadRequest, models.RespostaErro{
+		req.IdsLojas = parseIDList(c.QueryParam("ids"))
+	}
+
+	// Valida se há IDs no body ou na query
+	if len(req.IdsLojas) == 0 {
+		return apierror.Respond(c, http.StatusBadRequest, models.ErroRequisicaoInvalida, "Campo 'ids_lojas' (ou query param 'ids') é obrigatório e deve conter pelo menos um ID")
+	}
+
+	// Valida todos os IDs antes de rejeitar, para que o cliente saiba exatamente quais corrigir
+	if detalhes := validateIDList(req.IdsLojas); len(detalhes) > 0 {
+		return apierror.JSON(c, http.StatusBadRequest, models.RespostaErro{
 			Error:    models.ErroRequisicaoInvalida,
-			Mensagem: "Campo 'ids_lojas' é obrigatório e deve conter pelo menos um ID",
+			Mensagem: fmt.Sprintf("%d IDs inválidos em 'ids_lojas'", len(detalhes)),
+			Detalhes: detalhes,
 		})
 	}
 
+	if maxIDs := sh.platformService.MaxBulkIDs(); maxIDs > 0 && len(req.IdsLojas) > maxIDs {
+		return apierror.Respond(c, http.StatusBadRequest, models.ErroRequisicaoInvalida,
+			fmt.Sprintf("Quantidade de lojas (%d) excede o limite de %d por requisição", len(req.IdsLojas), maxIDs))
+	}
+
+	// Lotes grandes exigem que o cliente confirme a quantidade de lojas afetadas em X-Confirm-Bulk
+	if threshold := sh.platformService.BulkConfirmThreshold(); threshold > 0 && len(req.IdsLojas) > threshold {
+		if !confirmsBulk(c.Request().Header.Get(headerConfirmBulk), len(req.IdsLojas)) {
+			return apierror.Respond(c, http.StatusPreconditionRequired, models.ErroConfirmacaoNecessaria,
+				fmt.Sprintf("A operação afeta %d lojas (acima de %d): confirme enviando o header %s: %d", len(req.IdsLojas), threshold, headerConfirmBulk, len(req.IdsLojas)))
+		}
+	}
+
+	// Traduz os IDs internos para os IDs da plataforma
+	platformIDs, originais := sh.toPlatformIDs(plataforma, req.IdsLojas)
+
+	// Com If-Match, a operação só é aplicada se a loja ainda estiver na versão informada
+	if ifMatch := c.Request().Header.Get(headerIfMatch); ifMatch != "" {
+		if len(platformIDs) != 1 {
+			return apierror.Respond(c, http.StatusBadRequest, models.ErroRequisicaoInvalida, "Header If-Match só é aceito em operações com uma única loja")
+		}
+		if err := sh.service(c).CheckStoreVersion(c.Request().Context(), plataforma, platformIDs[0], parseIfMatch(ifMatch)); err != nil {
+			return sh.handlePlatformError(c, err)
+		}
+	}
+
 	// Executa a operação específica
-	response, err := operation(string(plataforma), req.IdsLojas)
+	inicio := time.Now()
+	response, err := operation(string(plataforma), platformIDs, req.Motivo)
 	if err != nil {
 		return sh.handlePlatformError(c, err)
 	}
 
+	verbose := c.QueryParam("verbose") == "true"
+	for i := range response.Resultados {
+		resultado := &response.Resultados[i]
+		resultado.IdLoja, resultado.IdPlataforma = sh.resolveIDs(plataforma, originais, resultado.IdLoja)
+		resultado.Prioridade = prioridades[resultado.IdLoja]
+		// A chamada que falhou é um detalhe de diagnóstico, retornado apenas com ?verbose=true
+		if !verbose {
+			resultado.Chamada = nil
+		}
+		// A loja montada pelo serviço recebe os IDs do cliente; o formato legado não a inclui
+		if formato == models.FormatoLegado {
+			resultado.Loja = nil
+		} else if resultado.Loja != nil {
+			resultado.Loja.Id, resultado.Loja.IdPlataforma = resultado.IdLoja, resultado.IdPlataforma
+		}
+	}
+
+	sh.notifyBulk(response, operacao, time.Since(inicio))
+
+	// Lojas não processadas por causa do prazo do lote tornam o resultado parcial
+	if services.HasUnprocessed(response.Resultados) {
+		return c.JSON(http.StatusMultiStatus, response)
+	}
+	return c.JSON(http.StatusOK, response)
+}
+
+// notifyBulk envia os webhooks da operação em lote conforme WEBHOOK_MODE: um evento loja.operacao
+// por loja e/ou um único lote.concluido com o resumo. O id do lote é devolvido na resposta
+func (sh *StoreHandler) notifyBulk(response *models.RespostaOperacaoMultiplasLojas, operacao models.Operacao, duracao time.Duration) {
+	if !sh.webhooks.NotifyStores() && !sh.webhooks.NotifyBatches() {
+		return
+	}
+	response.IdLote = webhook.NewBatchID()
+
+	if sh.webhooks.NotifyStores() {
+		for _, resultado := range response.Resultados {
+			sh.webhooks.Send(webhook.EventoLojaOperacao, models.EventoOperacaoLoja{
+				IdLote:     response.IdLote,
+				Plataforma: response.Plataforma,
+				Operacao:   operacao,
+				Resultado:  resultado,
+			})
+		}
+	}
+
+	if sh.webhooks.NotifyBatches() {
+		sucesso, naoEncontrado, falha := services.CountBulkResults(response.Resultados)
+		sh.webhooks.Send(webhook.EventoLoteConcluido, models.ResumoLote{
+			IdLote:        response.IdLote,
+			Plataforma:    response.Plataforma,
+			Operacao:      operacao,
+			Total:         len(response.Resultados),
+			Sucesso:       sucesso,
+			NaoEncontrado: naoEncontrado,
+			Falha:         falha,
+			DuracaoMs:     duracao.Milliseconds(),
+		})
+	}
+}
+
+// ValidateBulk gerencia POST /plataformas/{plataforma}/lojas/validar
+// Recebe o mesmo body das operações em lote e devolve a lista de IDs limpa (sem vazios, duplicados
+// e IDs longos demais), a contagem e os avisos encontrados, sem nenhuma chamada à plataforma.
+// valido só é true quando a lista enviada seria aceita como está por ativar/desativar
+func (sh *StoreHandler) ValidateBulk(c echo.Context) error {
+	plataforma := models.Plataforma(c.Param("plataforma"))
+	if !slices.Contains(sh.platformService.SupportedPlatforms(), plataforma) {
+		return apierror.Respond(c, http.StatusNotFound, models.ErroNaoEncontrado, "plataforma não suportada: "+string(plataforma))
+	}
+
+	req, err := bindBulkRequest(c)
+	if err != nil {
+		return apierror.Respond(c, http.StatusBadRequest, models.ErroRequisicaoInvalida, "Body da requisição inválido: "+err.Error())
+	}
+	if _, err := mergePriorityGroups(&req); err != nil {
+		return apierror.Respond(c, http.StatusBadRequest, models.ErroRequisicaoInvalida, err.Error())
+	}
+	if len(req.IdsLojas) == 0 {
+		req.IdsLojas = parseIDList(c.QueryParam("ids"))
+	}
+
+	// Aplica as mesmas regras de validateIDList das operações em lote: a lista só é válida se não
+	// tiver IDs rejeitados, e a lista limpa devolvida contém apenas os IDs aceitos
+	detalhes := validateIDList(req.IdsLojas)
+	rejeitados := make(map[int]bool, len(detalhes))
+	porMotivo := make(map[string]int)
+	for _, detalhe := range detalhes {
+		rejeitados[detalhe.Indice] = true
+		porMotivo[detalhe.Motivo]++
+	}
+	ids := make([]string, 0, len(req.IdsLojas))
+	for i, id := range req.IdsLojas {
+		if !rejeitados[i] {
+			ids = append(ids, strings.TrimSpace(id))
+		}
+	}
+
+	response := models.RespostaValidacaoLote{
+		Plataforma: plataforma,
+		Total:      len(ids),
+		IdsLojas:   ids,
+		Avisos:     []string{},
+	}
+
+	if n := porMotivo[models.MotivoIDVazio]; n > 0 {
+		response.Avisos = append(response.Avisos, fmt.Sprintf("%d IDs vazios removidos", n))
+	}
+	if n := porMotivo[models.MotivoIDDuplicado]; n > 0 {
+		response.Avisos = append(response.Avisos, fmt.Sprintf("%d IDs duplicados removidos", n))
+	}
+	if n := porMotivo[models.MotivoIDMuitoLongo]; n > 0 {
+		response.Avisos = append(response.Avisos, fmt.Sprintf("%d IDs com mais de %d caracteres removidos", n, maxIDLength))
+	}
+	if len(ids) == 0 {
+		response.Avisos = append(response.Avisos, "Nenhum ID informado")
+	}
+	maxIDs := sh.platformService.MaxBulkIDs()
+	if maxIDs > 0 && len(ids) > maxIDs {
+		response.Avisos = append(response.Avisos, fmt.Sprintf("Quantidade de lojas (%d) excede o limite de %d por requisição", len(ids), maxIDs))
+	}
+	response.Valido = len(detalhes) == 0 && len(ids) > 0 && (maxIDs <= 0 || len(ids) <= maxIDs)
+
 	return c.JSON(http.StatusOK, response)
 }
 
-// ActivateMultiple gerencia PATCH /plataformas/{plataforma}/lojas/ativar
+// ActivateMultiple gerencia PATCH e POST /plataformas/{plataforma}/lojas/ativar
 func (sh *StoreHandler) ActivateMultiple(c echo.Context) error {
-	return sh.handleBulkOperation(c, sh.platformService.ActivateMultipleStores)
+	return sh.handleBulkOperation(c, models.OperacaoAtivar, func(plataforma string, idsLojas []string, _ string) (*models.RespostaOperacaoMultiplasLojas, error) {
+		return sh.service(c).ActivateMultipleStores(c.Request().Context(), plataforma, idsLojas)
+	})
 }
 
-// DeactivateMultiple gerencia PATCH /plataformas/{plataforma}/lojas/desativar
+// DeactivateMultiple gerencia PATCH e POST /plataformas/{plataforma}/lojas/desativar
 func (sh *StoreHandler) DeactivateMultiple(c echo.Context) error {
-	return sh.handleBulkOperation(c, sh.platformService.DeactivateMultipleStores)
+	return sh.handleBulkOperation(c, models.OperacaoDesativar, func(plataforma string, idsLojas []string, motivo string) (*models.RespostaOperacaoMultiplasLojas, error) {
+		return sh.service(c).DeactivateMultipleStores(c.Request().Context(), plataforma, idsLojas, motivo)
+	})
+}
+
+// EnsureActive gerencia POST /plataformas/{plataforma}/lojas/garantir-ativas
+// Consulta o status atual e só ativa as lojas que ainda não estão ativas
+func (sh *StoreHandler) EnsureActive(c echo.Context) error {
+	return sh.handleBulkOperation(c, models.OperacaoAtivar, func(plataforma string, idsLojas []string, _ string) (*models.RespostaOperacaoMultiplasLojas, error) {
+		return sh.service(c).EnsureStoresStatus(c.Request().Context(), plataforma, idsLojas, models.StatusAtivo, "")
+	})
+}
+
+// EnsureBlocked gerencia POST /plataformas/{plataforma}/lojas/garantir-bloqueadas
+// Consulta o status atual e só desativa as lojas que ainda não estão bloqueadas
+func (sh *StoreHandler) EnsureBlocked(c echo.Context) error {
+	return sh.handleBulkOperation(c, models.OperacaoDesativar, func(plataforma string, idsLojas []string, motivo string) (*models.RespostaOperacaoMultiplasLojas, error) {
+		return sh.service(c).EnsureStoresStatus(c.Request().Context(), plataforma, idsLojas, models.StatusBloqueado, motivo)
+	})
 }
 
 // GetMultipleStatus gerencia GET /plataformas/{plataforma}/lojas/status
 // Os IDs das lojas podem ser passados no header "X-Lojas-IDs" separados por vírgula
 // Se não informar o header, retorna o status de todas as lojas da plataforma
+// Com ?verbose=true, inclui os dados brutos da plataforma em "detalhes"
+// Com ?incluir_inativas=false, omite as páginas arquivadas do AnotaAI ao listar todas as lojas
+// Com ?sort=nome, ordena a listagem completa pelo nome fantasia em vez do ID (padrão)
+// Com ?fields=id_loja,status, retorna apenas os campos informados de cada loja (campos
+// desconhecidos são ignorados e informados no header Warning)
+// Com ?agrupar=true, retorna os IDs agrupados por status em vez da lista de lojas
+// As lojas usam o DTO Loja; com ?formato=legado, retorna o formato anterior (StatusLojaDetalhes)
+// Com ?formato_documento=formatado, retorna o CPF/CNPJ com a máscara em vez de apenas os dígitos
 func (sh *StoreHandler) GetMultipleStatus(c echo.Context) error {
 	plataforma := models.Plataforma(c.Param("plataforma"))
 	idsParam := c.Request().Header.Get("X-Lojas-IDs")
+	verbose := c.QueryParam("verbose") == "true"
+	incluirInativas := c.QueryParam("incluir_inativas") != "false"
+	agrupar := c.QueryParam("agrupar") == "true"
 
 	// Valida parâmetros obrigatórios
 	if plataforma == "" {
-		return c.JSON(http.StatusBadRequest, models.RespostaErro{
-			Error:    models.ErroRequisicaoInvalida,
-			Mensagem: "Parâmetro plataforma é obrigatório",
-		})
+		return apierror.Respond(c, http.StatusBadRequest, models.ErroRequisicaoInvalida, "Parâmetro plataforma é obrigatório")
+	}
+
+	ordenacao, ok := parseOrdenacao(c.QueryParam("sort"))
+	if !ok {
+		return apierror.Respond(c, http.StatusBadRequest, models.ErroRequisicaoInvalida, "Parâmetro sort deve ser 'id' ou 'nome'")
+	}
+	if agrupar && c.QueryParam("fields") != "" {
+		return apierror.Respond(c, http.StatusBadRequest, models.ErroRequisicaoInvalida, "Parâmetros agrupar e fields não podem ser usados juntos")
+	}
+	formato, ok := parseFormato(c.QueryParam("formato"))
+	if !ok {
+		return apierror.Respond(c, http.StatusBadRequest, models.ErroRequisicaoInvalida, invalidFormatoMessage)
+	}
+	legado := formato == models.FormatoLegado
+	formatoDocumento, ok := parseFormatoDocumento(c.QueryParam("formato_documento"))
+	if !ok {
+		return apierror.Respond(c, http.StatusBadRequest, models.ErroRequisicaoInvalida, invalidFormatoDocumentoMessage)
 	}
 
 	// Processa os IDs se fornecidos
 	var idsLojas []string
 	if idsParam != "" {
-		// Separa os IDs por vírgula e remove espaços em branco
-		for _, id := range strings.Split(idsParam, ",") {
-			id = strings.TrimSpace(id)
-			if id != "" {
-				idsLojas = append(idsLojas, id)
-			}
-		}
-
+		idsLojas = parseIDList(idsParam)
 		if len(idsLojas) == 0 {
-			return c.JSON(http.StatusBadRequest, models.RespostaErro{
-				Error:    models.ErroRequisicaoInvalida,
-				Mensagem: "IDs inválidos no header X-Lojas-IDs",
-			})
+			return apierror.Respond(c, http.StatusBadRequest, models.ErroRequisicaoInvalida, "IDs inválidos no header X-Lojas-IDs")
 		}
 	}
 	// Se idsParam estiver vazio, idsLojas será nil e o service retornará todas as lojas
 
+	// Traduz os IDs internos para os IDs da plataforma
+	var originais map[string]string
+	if len(idsLojas) > 0 {
+		idsLojas, originais = sh.toPlatformIDs(plataforma, idsLojas)
+	}
+
 	// Chama o serviço da plataforma
-	response, err := sh.platformService.GetMultipleStoreStatus(plataforma, idsLojas)
+	response, err := sh.service(c).GetMultipleStoreStatus(c.Request().Context(), plataforma, idsLojas, incluirInativas, ordenacao)
 	if err != nil {
-		// Verifica o tipo de erro para determinar o status HTTP apropriado
-		if strings.Contains(err.Error(), "plataforma não suportada") {
-			return c.JSON(http.StatusNotFound, models.RespostaErro{
-				Error:    models.ErroNaoEncontrado,
-				Mensagem: err.Error(),
-			})
+		return sh.handlePlatformError(c, err)
+	}
+
+	sh.presentStatus(plataforma, originais, response, verbose, formatoDocumento)
+	setCacheHeaders(c, response.Cache)
+
+	// Com ?agrupar=true, devolve apenas os IDs agrupados pelo status
+	if agrupar {
+		return c.JSON(http.StatusOK, groupByStatus(response))
+	}
+
+	// Com ?fields=, serializa apenas os campos solicitados de cada loja
+	if fieldsParam := c.QueryParam("fields"); fieldsParam != "" {
+		permitidos := lojaFields
+		if legado {
+			permitidos = statusFields
+		}
+		fields, unknown := parseFields(fieldsParam, permitidos)
+		if len(unknown) > 0 {
+			c.Response().Header().Add("Warning", ignoredFieldsWarning(unknown))
+		}
+		if len(fields) > 0 {
+			var lojas []map[string]json.RawMessage
+			var err error
+			if legado {
+				lojas, err = selectFields(response.Lojas, fields)
+			} else {
+				lojas, err = selectFields(services.NewRespostaLojas(response).Lojas, fields)
+			}
+			if err != nil {
+				return apierror.Respond(c, http.StatusInternalServerError, models.ErroInternoServidor, err.Error())
+			}
+			return c.JSON(http.StatusOK, struct {
+				Plataforma    models.Plataforma            `json:"plataforma"`
+				Lojas         []map[string]json.RawMessage `json:"lojas"`
+				CatalogoVazio bool                         `json:"catalogo_vazio,omitempty"`
+			}{response.Plataforma, lojas, response.CatalogoVazio})
+		}
+	}
+
+	if legado {
+		return c.JSON(http.StatusOK, response)
+	}
+	return c.JSON(http.StatusOK, services.NewRespostaLojas(response))
+}
+
+// groupByStatus agrupa os IDs das lojas pelo status, mantendo a ordem da listagem em cada grupo.
+// Apenas os status presentes na resposta aparecem como grupo
+func groupByStatus(response *models.RespostaStatusMultiplasLojas) models.RespostaStatusAgrupado {
+	agrupado := models.RespostaStatusAgrupado{
+		Plataforma:    response.Plataforma,
+		Total:         len(response.Lojas),
+		Grupos:        make(map[models.Status][]string),
+		CatalogoVazio: response.CatalogoVazio,
+	}
+	for _, loja := range response.Lojas {
+		agrupado.Grupos[loja.Status] = append(agrupado.Grupos[loja.Status], loja.IdLoja)
+	}
+	return agrupado
+}
+
+// streamFlushInterval define a cada quantas lojas a resposta NDJSON é enviada ao cliente
+const streamFlushInterval = 100
+
+// headerIDsSource informa qual lista de IDs foi usada quando o header X-Lojas-IDs e o body divergem
+const headerIDsSource = "X-IDs-Source"
+
+// StreamMultipleStatus gerencia POST /plataformas/{plataforma}/lojas/status
+// Recebe os IDs no body (mesmo formato de ativar/desativar) e devolve o status de cada loja
+// como NDJSON (um objeto por linha), permitindo ao cliente processar listas muito grandes
+// de forma incremental. Erros após o início do envio só podem ser registrados em log
+// Também aceita os IDs no header "X-Lojas-IDs", para clientes migrando do GET; ver statusRequestIDs
+// Cada linha é uma Loja; com ?formato=legado, um StatusLojaDetalhes
+func (sh *StoreHandler) StreamMultipleStatus(c echo.Context) error {
+	plataforma := models.Plataforma(c.Param("plataforma"))
+	verbose := c.QueryParam("verbose") == "true"
+	formato, ok := parseFormato(c.QueryParam("formato"))
+	if !ok {
+		return apierror.Respond(c, http.StatusBadRequest, models.ErroRequisicaoInvalida, invalidFormatoMessage)
+	}
+	formatoDocumento, ok := parseFormatoDocumento(c.QueryParam("formato_documento"))
+	if !ok {
+		return apierror.Respond(c, http.StatusBadRequest, models.ErroRequisicaoInvalida, invalidFormatoDocumentoMessage)
+	}
+
+	var req models.RequisicaoMultiplasLojas
+	if err := c.Bind(&req); err != nil {
+		return apierror.Respond(c, http.StatusBadRequest, models.ErroRequisicaoInvalida, "Body da requisição inválido: "+err.Error())
+	}
+	idsLojas, ok := sh.statusRequestIDs(c, req.IdsLojas)
+	if !ok {
+		return apierror.Respond(c, http.StatusBadRequest, models.ErroRequisicaoInvalida, "Informe os IDs no body ou no header X-Lojas-IDs, não em ambos")
+	}
+	if len(idsLojas) == 0 {
+		return apierror.Respond(c, http.StatusBadRequest, models.ErroRequisicaoInvalida, "Campo 'ids_lojas' é obrigatório e deve conter pelo menos um ID")
+	}
+
+	platformIDs, originais := sh.toPlatformIDs(plataforma, idsLojas)
+
+	response := c.Response()
+	encoder := json.NewEncoder(response)
+	enviadas := 0
+
+	err := sh.service(c).StreamStoreStatus(c.Request().Context(), plataforma, platformIDs, func(loja models.StatusLojaDetalhes, cache *models.IdadeCache) error {
+		// O status HTTP só é enviado depois que o catálogo foi carregado com sucesso,
+		// para que falhas da plataforma ainda possam ser reportadas como erro
+		if !response.Committed {
+			response.Header().Set(echo.HeaderContentType, "application/x-ndjson")
+			setCacheHeaders(c, cache)
+			response.WriteHeader(http.StatusOK)
+		}
+
+		sh.presentLoja(plataforma, originais, &loja, verbose, formatoDocumento)
+		var linha any = services.NewLoja(plataforma, loja)
+		if formato == models.FormatoLegado {
+			linha = loja
+		}
+		if err := encoder.Encode(linha); err != nil {
+			return err
 		}
 
-		// Erro genérico - poderia ser bad gateway em implementação real
-		return c.JSON(http.StatusBadGateway, models.RespostaErro{
-			Error:    models.ErroBadGateway,
-			Mensagem: "Erro ao comunicar com a plataforma: " + err.Error(),
+		enviadas++
+		if enviadas%streamFlushInterval == 0 {
+			response.Flush()
+		}
+		return nil
+	})
+	if err != nil {
+		if response.Committed {
+			log.Printf("[Status] Envio NDJSON interrompido após %d lojas: %v", enviadas, err)
+			return nil
+		}
+		return sh.handlePlatformError(c, err)
+	}
+
+	response.Flush()
+	return nil
+}
+
+// statusRequestIDs escolhe os IDs da consulta entre o body e o header X-Lojas-IDs. O body tem
+// precedência: quando os dois trazem listas diferentes, o body é usado e a resposta informa
+// X-IDs-Source: body. Com STRICT_INPUT=true, informar os dois é rejeitado (retorna false)
+func (sh *StoreHandler) statusRequestIDs(c echo.Context, bodyIDs []string) ([]string, bool) {
+	headerIDs := parseIDList(c.Request().Header.Get("X-Lojas-IDs"))
+	if len(headerIDs) == 0 {
+		return bodyIDs, true
+	}
+	if len(bodyIDs) == 0 {
+		return headerIDs, true
+	}
+
+	if sh.platformService.StrictInput() {
+		return nil, false
+	}
+	body, _, _ := cleanIDList(bodyIDs)
+	header, _, _ := cleanIDList(headerIDs)
+	slices.Sort(body)
+	slices.Sort(header)
+	if !slices.Equal(body, header) {
+		c.Response().Header().Set(headerIDsSource, "body")
+	}
+	return bodyIDs, true
+}
+
+// Eventos enviados pelo stream de status
+const (
+	eventoSnapshot = "snapshot"
+	eventoLoja     = "loja"
+	eventoErro     = "erro"
+)
+
+// StatusEventStream gerencia GET /plataformas/{plataforma}/lojas/status/stream
+// Envia o status das lojas por Server-Sent Events: um evento "snapshot" com todas as lojas e,
+// a cada STATUS_STREAM_INTERVAL, um evento "loja" para cada loja nova ou alterada (lojas que
+// deixam de ser listadas são enviadas como nao_encontrado). As consultas usam o cache de status
+// e o envio termina quando o cliente se desconecta
+// Com ?incluir_inativas=false, omite as páginas arquivadas do AnotaAI
+// Com ?intervalo=5s, substitui STATUS_STREAM_INTERVAL, entre STATUS_STREAM_MIN_INTERVAL e STATUS_STREAM_MAX_INTERVAL
+// As lojas dos eventos usam o DTO Loja; com ?formato=legado, o formato anterior (StatusLojaDetalhes)
+func (sh *StoreHandler) StatusEventStream(c echo.Context) error {
+	plataforma := models.Plataforma(c.Param("plataforma"))
+	incluirInativas := c.QueryParam("incluir_inativas") != "false"
+	ctx := c.Request().Context()
+	platformService := sh.service(c)
+
+	formato, ok := parseFormato(c.QueryParam("formato"))
+	if !ok {
+		return apierror.Respond(c, http.StatusBadRequest, models.ErroRequisicaoInvalida, invalidFormatoMessage)
+	}
+	legado := formato == models.FormatoLegado
+
+	formatoDocumento, ok := parseFormatoDocumento(c.QueryParam("formato_documento"))
+	if !ok {
+		return apierror.Respond(c, http.StatusBadRequest, models.ErroRequisicaoInvalida, invalidFormatoDocumentoMessage)
+	}
+
+	intervalo := sh.platformService.StatusStreamInterval()
+	if value := c.QueryParam("intervalo"); value != "" {
+		minimo, maximo := sh.platformService.StatusStreamBounds()
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed <= 0 {
+			return apierror.Respond(c, http.StatusBadRequest, models.ErroRequisicaoInvalida, "Parâmetro intervalo inválido. Use uma duração como '5s'")
+		}
+		// O mínimo protege a plataforma de consultas frequentes demais; acima do máximo, o intervalo é limitado
+		if parsed < minimo {
+			return apierror.Respond(c, http.StatusBadRequest, models.ErroRequisicaoInvalida,
+				fmt.Sprintf("Parâmetro intervalo deve ser de pelo menos %s", minimo))
+		}
+		intervalo = min(parsed, maximo)
+	}
+
+	// O snapshot é consultado antes de enviar os headers, para que falhas da plataforma
+	// ainda possam ser reportadas como erro
+	snapshot, err := platformService.GetMultipleStoreStatus(ctx, plataforma, nil, incluirInativas, models.OrdenarPorID)
+	if err != nil {
+		return sh.handlePlatformError(c, err)
+	}
+	sh.presentStatus(plataforma, nil, snapshot, false, formatoDocumento)
+
+	response := c.Response()
+	response.Header().Set(echo.HeaderContentType, "text/event-stream")
+	response.Header().Set(echo.HeaderCacheControl, "no-cache")
+	// Evita que proxies como o nginx acumulem os eventos antes de repassá-los
+	response.Header().Set("X-Accel-Buffering", "no")
+	response.WriteHeader(http.StatusOK)
+
+	var dados any = services.NewRespostaLojas(snapshot)
+	if legado {
+		dados = snapshot
+	}
+	if err := writeEvent(response, eventoSnapshot, dados); err != nil {
+		return nil
+	}
+	anteriores := indexLojas(snapshot.Lojas)
+
+	ticker := time.NewTicker(intervalo)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Printf("[Stream] Cliente desconectado do stream de status da plataforma %s", plataforma)
+			return nil
+		case <-ticker.C:
+		}
+
+		atual, err := platformService.GetMultipleStoreStatus(ctx, plataforma, nil, incluirInativas, models.OrdenarPorID)
+		if err != nil {
+			// Falhas da plataforma não encerram o stream; o cliente é avisado e a próxima consulta tenta novamente
+			log.Printf("[Stream] Erro ao consultar status da plataforma %s: %v", plataforma, err)
+			_, resposta := platformErrorResponse(err)
+			resposta.Timestamp = time.Now().UTC().Format(time.RFC3339)
+			if err := writeEvent(response, eventoErro, resposta); err != nil {
+				return nil
+			}
+			continue
+		}
+		sh.presentStatus(plataforma, nil, atual, false, formatoDocumento)
+
+		atuais := indexLojas(atual.Lojas)
+		alteradas := statusDelta(anteriores, atuais)
+		anteriores = atuais
+
+		// Sem alterações, envia um comentário para manter a conexão aberta através de proxies
+		if len(alteradas) == 0 {
+			if _, err := fmt.Fprint(response, ": keep-alive\n\n"); err != nil {
+				return nil
+			}
+			response.Flush()
+			continue
+		}
+		for _, loja := range alteradas {
+			var dados any = services.NewLoja(plataforma, loja)
+			if legado {
+				dados = loja
+			}
+			if err := writeEvent(response, eventoLoja, dados); err != nil {
+				return nil
+			}
+		}
+	}
+}
+
+// writeEvent envia um evento Server-Sent Events com os dados serializados em JSON
+func writeEvent(response *echo.Response, evento string, dados any) error {
+	data, err := json.Marshal(dados)
+	if err != nil {
+		log.Printf("[Stream] Erro ao serializar evento %s: %v", evento, err)
+		return err
+	}
+	if _, err := fmt.Fprintf(response, "event: %s\ndata: %s\n\n", evento, data); err != nil {
+		return err
+	}
+	response.Flush()
+	return nil
+}
+
+// indexLojas indexa as lojas pelo ID
+func indexLojas(lojas []models.StatusLojaDetalhes) map[string]models.StatusLojaDetalhes {
+	indice := make(map[string]models.StatusLojaDetalhes, len(lojas))
+	for _, loja := range lojas {
+		indice[loja.IdLoja] = loja
+	}
+	return indice
+}
+
+// statusDelta retorna, ordenadas por ID, as lojas novas ou alteradas entre duas consultas e as
+// lojas que deixaram de ser listadas, estas com status nao_encontrado
+func statusDelta(anteriores, atuais map[string]models.StatusLojaDetalhes) []models.StatusLojaDetalhes {
+	var alteradas []models.StatusLojaDetalhes
+	for id, loja := range atuais {
+		if anterior, ok := anteriores[id]; !ok || anterior != loja {
+			alteradas = append(alteradas, loja)
+		}
+	}
+	for id, loja := range anteriores {
+		if _, ok := atuais[id]; !ok && loja.Status != models.StatusNaoEncontrado {
+			alteradas = append(alteradas, models.StatusLojaDetalhes{IdLoja: loja.IdLoja, IdPlataforma: loja.IdPlataforma, Status: models.StatusNaoEncontrado})
+		}
+	}
+	slices.SortFunc(alteradas, func(a, b models.StatusLojaDetalhes) int {
+		return strings.Compare(a.IdLoja, b.IdLoja)
+	})
+	return alteradas
+}
+
+// Limites da consulta de status com espera (?aguardar=)
+const (
+	defaultWaitTimeout = 10 * time.Second
+	maxWaitTimeout     = 60 * time.Second
+	waitPollInterval   = 2 * time.Second
+)
+
+// GetStoreStatus gerencia GET /plataformas/{plataforma}/lojas/{idLoja}/status
+// Com ?aguardar=<status>, consulta a loja periodicamente até atingir o status desejado ou
+// até esgotar o ?timeout= (padrão 10s, máximo 60s), retornando o último status obtido. O
+// ?timeout limita também as consultas: uma consulta ainda em andamento quando ele termina é
+// abandonada e vale o status da anterior (sem nenhuma consulta concluída, a resposta é 504).
+// Útil para confirmar bloqueios do DeliveryVip, que são processados de forma assíncrona
+// A loja usa o DTO Loja; com ?formato=legado, retorna o formato anterior (StatusLojaDetalhes)
+// Com ?formato_documento=formatado, retorna o CPF/CNPJ com a máscara
+func (sh *StoreHandler) GetStoreStatus(c echo.Context) error {
+	plataforma := models.Plataforma(c.Param("plataforma"))
+	idLoja := c.Param("idLoja")
+	aguardar := models.Status(c.QueryParam("aguardar"))
+	verbose := c.QueryParam("verbose") == "true"
+
+	formato, ok := parseFormato(c.QueryParam("formato"))
+	if !ok {
+		return apierror.Respond(c, http.StatusBadRequest, models.ErroRequisicaoInvalida, invalidFormatoMessage)
+	}
+	formatoDocumento, ok := parseFormatoDocumento(c.QueryParam("formato_documento"))
+	if !ok {
+		return apierror.Respond(c, http.StatusBadRequest, models.ErroRequisicaoInvalida, invalidFormatoDocumentoMessage)
+	}
+
+	if aguardar != "" && !aguardar.IsValid() {
+		return apierror.Respond(c, http.StatusBadRequest, models.ErroRequisicaoInvalida, "Status inválido no parâmetro aguardar: "+string(aguardar))
+	}
+
+	timeout := defaultWaitTimeout
+	if value := c.QueryParam("timeout"); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed <= 0 {
+			return apierror.Respond(c, http.StatusBadRequest, models.ErroRequisicaoInvalida, "Parâmetro timeout inválido. Use uma duração como '10s'")
+		}
+		timeout = min(parsed, maxWaitTimeout)
+	}
+
+	platformIDs, originais := sh.toPlatformIDs(plataforma, []string{idLoja})
+
+	// O ?timeout só se aplica à espera; uma consulta simples segue os prazos da plataforma
+	ctx := c.Request().Context()
+	if aguardar != "" {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	response := &models.RespostaStatusLoja{Plataforma: plataforma}
+	for {
+		// A primeira consulta vai à plataforma; as seguintes aceitam o catálogo buscado no último
+		// intervalo, compartilhado com as demais esperas em andamento
+		var maxAge time.Duration
+		if response.Tentativas > 0 {
+			maxAge = waitPollInterval
+		}
+		loja, err := sh.service(c).PollStoreStatus(ctx, plataforma, platformIDs[0], maxAge)
+		if err != nil {
+			// O ?timeout terminou durante uma nova consulta: vale o último status obtido
+			if response.Tentativas > 0 && ctx.Err() != nil && c.Request().Context().Err() == nil {
+				break
+			}
+			return sh.handlePlatformError(c, err)
+		}
+		response.Loja = *loja
+		response.Tentativas++
+
+		if aguardar == "" || loja.Status == aguardar || !sleepContext(ctx, waitPollInterval) {
+			break
+		}
+	}
+
+	if aguardar != "" {
+		alcancado := response.Loja.Status == aguardar
+		response.Aguardado = aguardar
+		response.StatusAlcancado = &alcancado
+	}
+
+	statusResponse := &models.RespostaStatusMultiplasLojas{Lojas: []models.StatusLojaDetalhes{response.Loja}}
+	sh.presentStatus(plataforma, originais, statusResponse, verbose, formatoDocumento)
+	response.Loja = statusResponse.Lojas[0]
+
+	setETag(c, response.Loja.Versao)
+	if formato == models.FormatoLegado {
+		return c.JSON(http.StatusOK, response)
+	}
+	return c.JSON(http.StatusOK, models.RespostaLoja{
+		Plataforma:      response.Plataforma,
+		Loja:            services.NewLoja(plataforma, response.Loja),
+		Aguardado:       response.Aguardado,
+		StatusAlcancado: response.StatusAlcancado,
+		Tentativas:      response.Tentativas,
+	})
+}
+
+// Headers que informam a idade do catálogo em cache usado na resposta
+const (
+	headerCacheAge = "X-Cache-Age"
+	// staleWarning é o aviso do RFC 7234 para respostas desatualizadas
+	staleWarning = `110 - "Response is Stale"`
+)
+
+// setCacheHeaders informa em X-Cache-Age a idade, em segundos, do catálogo usado na resposta e,
+// quando ele está perto do fim do TTL, acrescenta o Warning 110. Com o cache desabilitado, nada é enviado
+func setCacheHeaders(c echo.Context, cache *models.IdadeCache) {
+	if cache == nil {
+		return
+	}
+	c.Response().Header().Set(headerCacheAge, strconv.Itoa(int(cache.Idade.Seconds())))
+	if cache.Desatualizado {
+		c.Response().Header().Add("Warning", staleWarning)
+	}
+}
+
+// SyncStore gerencia POST /plataformas/{plataforma}/lojas/{idLoja}/sincronizar
+// Consulta o status atual da loja na plataforma, atualiza o cache e o histórico e devolve o status obtido
+// A loja usa o DTO Loja; com ?formato=legado, retorna o formato anterior (StatusLojaDetalhes)
+func (sh *StoreHandler) SyncStore(c echo.Context) error {
+	plataforma := models.Plataforma(c.Param("plataforma"))
+	verbose := c.QueryParam("verbose") == "true"
+	formato, ok := parseFormato(c.QueryParam("formato"))
+	if !ok {
+		return apierror.Respond(c, http.StatusBadRequest, models.ErroRequisicaoInvalida, invalidFormatoMessage)
+	}
+	formatoDocumento, ok := parseFormatoDocumento(c.QueryParam("formato_documento"))
+	if !ok {
+		return apierror.Respond(c, http.StatusBadRequest, models.ErroRequisicaoInvalida, invalidFormatoDocumentoMessage)
+	}
+	platformIDs, originais := sh.toPlatformIDs(plataforma, []string{c.Param("idLoja")})
+
+	loja, err := sh.service(c).SyncStoreStatus(c.Request().Context(), plataforma, platformIDs[0])
+	if err != nil {
+		return sh.handlePlatformError(c, err)
+	}
+
+	sh.presentLoja(plataforma, originais, loja, verbose, formatoDocumento)
+	setETag(c, loja.Versao)
+	if formato == models.FormatoLegado {
+		return c.JSON(http.StatusOK, models.RespostaStatusLoja{
+			Plataforma: plataforma,
+			Loja:       *loja,
+			Tentativas: 1,
 		})
 	}
+	return c.JSON(http.StatusOK, models.RespostaLoja{
+		Plataforma: plataforma,
+		Loja:       services.NewLoja(plataforma, *loja),
+		Tentativas: 1,
+	})
+}
+
+// SetStoreStatus gerencia PUT /plataformas/{plataforma}/lojas/{idLoja}/status
+// Recebe o status alvo ({"status": "ativo"} ou {"status": "bloqueado"}) e só chama a plataforma
+// quando a loja não está nele. Aceita If-Match com a versão da loja, como as operações em lote
+// A resposta traz a loja no DTO Loja; com ?formato=legado, ela é omitida
+func (sh *StoreHandler) SetStoreStatus(c echo.Context) error {
+	plataforma := models.Plataforma(c.Param("plataforma"))
+	formato, ok := parseFormato(c.QueryParam("formato"))
+	if !ok {
+		return apierror.Respond(c, http.StatusBadRequest, models.ErroRequisicaoInvalida, invalidFormatoMessage)
+	}
 
+	var req models.RequisicaoDefinicaoStatus
+	if err := c.Bind(&req); err != nil {
+		return apierror.Respond(c, http.StatusBadRequest, models.ErroRequisicaoInvalida, "Body da requisição inválido: "+err.Error())
+	}
+	if req.Status != models.StatusAtivo && req.Status != models.StatusBloqueado {
+		// Um status conhecido, mas que a plataforma não permite definir (ex.: cancelado), é semanticamente inválido
+		if req.Status.IsValid() {
+			return apierror.Respond(c, http.StatusUnprocessableEntity, models.ErroEntidadeNaoProcessavel,
+				fmt.Sprintf("O status '%s' não pode ser definido por esta API; use '%s' ou '%s'", req.Status, models.StatusAtivo, models.StatusBloqueado))
+		}
+		return apierror.Respond(c, http.StatusBadRequest, models.ErroRequisicaoInvalida,
+			fmt.Sprintf("Campo 'status' deve ser '%s' ou '%s'", models.StatusAtivo, models.StatusBloqueado))
+	}
+
+	platformIDs, originais := sh.toPlatformIDs(plataforma, []string{c.Param("idLoja")})
+
+	var versoes []string
+	if ifMatch := c.Request().Header.Get(headerIfMatch); ifMatch != "" {
+		versoes = parseIfMatch(ifMatch)
+	}
+
+	response, err := sh.service(c).SetStoreStatus(c.Request().Context(), plataforma, platformIDs[0], req.Status, req.Motivo, versoes)
+	if err != nil {
+		return sh.handlePlatformError(c, err)
+	}
+
+	response.IdLoja, response.IdPlataforma = sh.resolveIDs(plataforma, originais, response.IdLoja)
+	if formato == models.FormatoLegado {
+		response.Loja = nil
+	} else if response.Loja != nil {
+		response.Loja.Id, response.Loja.IdPlataforma = response.IdLoja, response.IdPlataforma
+	}
 	return c.JSON(http.StatusOK, response)
 }
+
+// GetRawStore gerencia GET /plataformas/{plataforma}/lojas/{idLoja}/raw
+// Retorna o JSON da loja exatamente como a plataforma o lista. Disponível apenas com DEBUG_ENDPOINTS=true
+func (sh *StoreHandler) GetRawStore(c echo.Context) error {
+	plataforma := models.Plataforma(c.Param("plataforma"))
+	platformID := sh.idMapper.ToPlatform(plataforma, c.Param("idLoja"))
+
+	raw, err := sh.service(c).GetRawStore(plataforma, platformID)
+	if err != nil {
+		return sh.handlePlatformError(c, err)
+	}
+
+	return c.JSONBlob(http.StatusOK, raw)
+}
+
+// sleepContext aguarda o intervalo informado, retornando false se o contexto terminar antes
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// GetAllPlatformsStatus gerencia GET /lojas/status
+// Consulta o status das mesmas lojas em todas as plataformas concorrentemente. Se uma
+// plataforma falhar, as demais são retornadas normalmente e a resposta usa o status 207
+// Os IDs podem vir no header "X-Lojas-IDs" ou no query param "ids" (o header tem precedência)
+// As lojas de cada plataforma usam o DTO Loja; com ?formato=legado, o formato anterior (StatusLojaDetalhes)
+// Com ?formato_documento=formatado, retorna o CPF/CNPJ com a máscara
+func (sh *StoreHandler) GetAllPlatformsStatus(c echo.Context) error {
+	idsParam := c.Request().Header.Get("X-Lojas-IDs")
+	if idsParam == "" {
+		idsParam = c.QueryParam("ids")
+	}
+	verbose := c.QueryParam("verbose") == "true"
+	incluirInativas := c.QueryParam("incluir_inativas") != "false"
+
+	ordenacao, ok := parseOrdenacao(c.QueryParam("sort"))
+	if !ok {
+		return apierror.Respond(c, http.StatusBadRequest, models.ErroRequisicaoInvalida, "Parâmetro sort deve ser 'id' ou 'nome'")
+	}
+	formato, ok := parseFormato(c.QueryParam("formato"))
+	if !ok {
+		return apierror.Respond(c, http.StatusBadRequest, models.ErroRequisicaoInvalida, invalidFormatoMessage)
+	}
+	formatoDocumento, ok := parseFormatoDocumento(c.QueryParam("formato_documento"))
+	if !ok {
+		return apierror.Respond(c, http.StatusBadRequest, models.ErroRequisicaoInvalida, invalidFormatoDocumentoMessage)
+	}
+
+	var idsLojas []string
+	if idsParam != "" {
+		idsLojas = parseIDList(idsParam)
+		if len(idsLojas) == 0 {
+			return apierror.Respond(c, http.StatusBadRequest, models.ErroRequisicaoInvalida, "IDs inválidos no header X-Lojas-IDs ou no query param ids")
+		}
+	}
+
+	plataformas := sh.platformService.SupportedPlatforms()
+	resultados := make([]models.ResultadoStatusPlataforma, len(plataformas))
+
+	// Cada goroutine registra o próprio erro e retorna nil, para que a falha
+	// de uma plataforma não cancele as consultas das demais. PLATFORM_FANOUT_CONCURRENCY
+	// limita quantas plataformas são consultadas ao mesmo tempo
+	var g errgroup.Group
+	if limite := sh.platformService.FanoutConcurrency(); limite > 0 {
+		g.SetLimit(limite)
+	}
+	for i, plataforma := range plataformas {
+		g.Go(func() error {
+			var originais map[string]string
+			ids := idsLojas
+			if len(ids) > 0 {
+				ids, originais = sh.toPlatformIDs(plataforma, ids)
+			}
+
+			response, err := sh.platformService.GetMultipleStoreStatus(c.Request().Context(), plataforma, ids, incluirInativas, ordenacao)
+			if err != nil {
+				_, resposta := platformErrorResponse(err)
+				resultados[i] = models.ResultadoStatusPlataforma{
+					RespostaStatusMultiplasLojas: &models.RespostaStatusMultiplasLojas{
+						Plataforma: plataforma,
+						Lojas:      []models.StatusLojaDetalhes{},
+					},
+					Erro: &resposta,
+				}
+				return nil
+			}
+
+			sh.presentStatus(plataforma, originais, response, verbose, formatoDocumento)
+			resultados[i] = models.ResultadoStatusPlataforma{RespostaStatusMultiplasLojas: response}
+			return nil
+		})
+	}
+	_ = g.Wait()
+
+	response := make(models.RespostaStatusPlataformas, len(resultados))
+	falhas := 0
+	for _, resultado := range resultados {
+		if resultado.Erro != nil {
+			falhas++
+		}
+		response[resultado.Plataforma] = resultado
+	}
+
+	statusCode := http.StatusOK
+	switch {
+	case falhas == len(resultados):
+		statusCode = http.StatusBadGateway
+	case falhas > 0:
+		statusCode = http.StatusMultiStatus
+	}
+
+	if formato == models.FormatoLegado {
+		return c.JSON(statusCode, response)
+	}
+	lojas := make(models.RespostaLojasPlataformas, len(response))
+	for plataforma, resultado := range response {
+		lojas[plataforma] = models.ResultadoLojasPlataforma{
+			RespostaLojas: services.NewRespostaLojas(resultado.RespostaStatusMultiplasLojas),
+			Erro:          resultado.Erro,
+		}
+	}
+	return c.JSON(statusCode, lojas)
+}
+
+// FindDuplicates gerencia GET /lojas/duplicadas
+// Consulta o catálogo completo de todas as plataformas e retorna os documentos (CPF/CNPJ) com lojas
+// em mais de uma delas. Se alguma plataforma falhar, a resposta é parcial (207) e informa o erro
+// da plataforma em "erros"; se todas falharem, 502
+// As lojas usam o DTO Loja; com ?formato=legado, o formato anterior (LojaDuplicada)
+func (sh *StoreHandler) FindDuplicates(c echo.Context) error {
+	formato, ok := parseFormato(c.QueryParam("formato"))
+	if !ok {
+		return apierror.Respond(c, http.StatusBadRequest, models.ErroRequisicaoInvalida, invalidFormatoMessage)
+	}
+
+	plataformas := sh.platformService.SupportedPlatforms()
+	lojas := make([][]models.StatusLojaDetalhes, len(plataformas))
+	erros := make([]*models.RespostaErro, len(plataformas))
+
+	// Como em GetAllPlatformsStatus, a falha de uma plataforma não cancela as demais
+	var g errgroup.Group
+	if limite := sh.platformService.FanoutConcurrency(); limite > 0 {
+		g.SetLimit(limite)
+	}
+	for i, plataforma := range plataformas {
+		g.Go(func() error {
+			response, err := sh.platformService.GetMultipleStoreStatus(c.Request().Context(), plataforma, nil, true, models.OrdenarPorID)
+			if err != nil {
+				_, resposta := platformErrorResponse(err)
+				erros[i] = &resposta
+				return nil
+			}
+			sh.presentStatus(plataforma, nil, response, false, models.FormatoDocumentoLimpo)
+			lojas[i] = response.Lojas
+			return nil
+		})
+	}
+	_ = g.Wait()
+
+	lojasPorPlataforma := make(map[models.Plataforma][]models.StatusLojaDetalhes, len(plataformas))
+	response := models.RespostaLojasDuplicadas{}
+	for i, plataforma := range plataformas {
+		if erros[i] != nil {
+			if response.Erros == nil {
+				response.Erros = make(map[models.Plataforma]models.RespostaErro)
+			}
+			response.Erros[plataforma] = *erros[i]
+			continue
+		}
+		lojasPorPlataforma[plataforma] = lojas[i]
+	}
+	response.Duplicadas = services.FindDuplicateDocuments(lojasPorPlataforma)
+	response.Total = len(response.Duplicadas)
+
+	statusCode := http.StatusOK
+	switch {
+	case len(response.Erros) == len(plataformas):
+		statusCode = http.StatusBadGateway
+	case len(response.Erros) > 0:
+		statusCode = http.StatusMultiStatus
+	}
+	if formato == models.FormatoLegado {
+		return c.JSON(statusCode, models.RespostaLojasDuplicadasLegado{
+			Total:      response.Total,
+			Duplicadas: services.LegacyDuplicates(response.Duplicadas),
+			Erros:      response.Erros,
+		})
+	}
+	return c.JSON(statusCode, response)
+}
+
+// Ping gerencia GET /plataformas/{plataforma}/ping
+// Executa uma chamada autenticada mínima na plataforma. Retorna 503 se a plataforma
+// estiver inacessível ou recusar as credenciais
+func (sh *StoreHandler) Ping(c echo.Context) error {
+	plataforma := models.Plataforma(c.Param("plataforma"))
+
+	response, err := sh.service(c).Ping(plataforma)
+	if err != nil {
+		return sh.handlePlatformError(c, err)
+	}
+
+	if !response.Alcancavel || !response.Autenticado {
+		return c.JSON(http.StatusServiceUnavailable, response)
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// ListPlatforms gerencia GET /plataformas
+// Lista as plataformas suportadas e as operações oferecidas por cada uma
+func (sh *StoreHandler) ListPlatforms(c echo.Context) error {
+	return c.JSON(http.StatusOK, sh.platformService.Platforms())
+}
+
+// GetStatusSummary gerencia GET /plataformas/{plataforma}/lojas/resumo
+// Conta as lojas por status, considerando os IDs do header "X-Lojas-IDs" ou, sem o header, todo o
+// catálogo (com ?incluir_inativas=false, sem as páginas arquivadas do AnotaAI). total_plataforma
+// traz a quantidade de lojas que a plataforma conhece, para conciliar com as lojas gerenciadas
+func (sh *StoreHandler) GetStatusSummary(c echo.Context) error {
+	plataforma := models.Plataforma(c.Param("plataforma"))
+	incluirInativas := c.QueryParam("incluir_inativas") != "false"
+
+	var idsLojas []string
+	if idsParam := c.Request().Header.Get("X-Lojas-IDs"); idsParam != "" {
+		idsLojas = parseIDList(idsParam)
+		if len(idsLojas) == 0 {
+			return apierror.Respond(c, http.StatusBadRequest, models.ErroRequisicaoInvalida, "IDs inválidos no header X-Lojas-IDs")
+		}
+		idsLojas, _ = sh.toPlatformIDs(plataforma, idsLojas)
+	}
+
+	response, err := sh.service(c).GetStatusSummary(c.Request().Context(), plataforma, idsLojas, incluirInativas)
+	if err != nil {
+		return sh.handlePlatformError(c, err)
+	}
+	return c.JSON(http.StatusOK, response)
+}
+
+// recentOperationsDefaultLimit é a quantidade de operações retornadas sem o parâmetro limit
+const recentOperationsDefaultLimit = 50
+
+// ListRecentOperations gerencia GET /operacoes/recentes
+// Retorna as últimas operações executadas nas lojas, da mais recente para a mais antiga, mantidas
+// em memória (até RECENT_OPERATIONS_SIZE). Com ?limit=N, retorna no máximo N operações (padrão 50)
+func (sh *StoreHandler) ListRecentOperations(c echo.Context) error {
+	limite := recentOperationsDefaultLimit
+	if value := c.QueryParam("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 {
+			return apierror.Respond(c, http.StatusBadRequest, models.ErroRequisicaoInvalida, "Parâmetro limit deve ser um número inteiro positivo")
+		}
+		limite = parsed
+	}
+
+	return c.JSON(http.StatusOK, sh.platformService.RecentOperations(limite))
+}
+
+// ReconcileDiff gerencia POST /plataformas/{plataforma}/lojas/reconciliar/diff
+// Recebe as lojas que deveriam estar ativas e bloqueadas e devolve o que precisaria ser bloqueado,
+// desbloqueado, o que já está correto e o que não foi encontrado, sem alterar nenhuma loja
+func (sh *StoreHandler) ReconcileDiff(c echo.Context) error {
+	plataforma := models.Plataforma(c.Param("plataforma"))
+
+	var req models.RequisicaoReconciliacao
+	if err := c.Bind(&req); err != nil {
+		return apierror.Respond(c, http.StatusBadRequest, models.ErroRequisicaoInvalida, "Body da requisição inválido: "+err.Error())
+	}
+	ativas, _, _ := cleanIDList(req.Ativas)
+	bloqueadas, _, _ := cleanIDList(req.Bloqueadas)
+	if len(ativas) == 0 && len(bloqueadas) == 0 {
+		return apierror.Respond(c, http.StatusBadRequest, models.ErroRequisicaoInvalida, "Informe ao menos um ID em 'ativas' ou 'bloqueadas'")
+	}
+	// A requisição é bem formada, mas pede dois status para a mesma loja
+	if conflito := slices.IndexFunc(ativas, func(id string) bool { return slices.Contains(bloqueadas, id) }); conflito >= 0 {
+		return apierror.Respond(c, http.StatusUnprocessableEntity, models.ErroEntidadeNaoProcessavel,
+			fmt.Sprintf("A loja %s está em 'ativas' e em 'bloqueadas'", ativas[conflito]))
+	}
+	if maxIDs := sh.platformService.MaxBulkIDs(); maxIDs > 0 && len(ativas)+len(bloqueadas) > maxIDs {
+		return apierror.Respond(c, http.StatusBadRequest, models.ErroRequisicaoInvalida,
+			fmt.Sprintf("Quantidade de lojas (%d) excede o limite de %d por requisição", len(ativas)+len(bloqueadas), maxIDs))
+	}
+
+	// Traduz os IDs internos para os IDs da plataforma
+	ativas, originaisAtivas := sh.toPlatformIDs(plataforma, ativas)
+	bloqueadas, originais := sh.toPlatformIDs(plataforma, bloqueadas)
+	maps.Copy(originais, originaisAtivas)
+
+	response, err := sh.service(c).ReconcileDiff(c.Request().Context(), plataforma, ativas, bloqueadas)
+	if err != nil {
+		return sh.handlePlatformError(c, err)
+	}
+
+	for _, itens := range [][]models.ItemReconciliacao{response.Bloquear, response.Desbloquear, response.Corretas, response.NaoEncontradas} {
+		for i := range itens {
+			itens[i].IdLoja, itens[i].IdPlataforma = sh.resolveIDs(plataforma, originais, itens[i].IdLoja)
+		}
+	}
+	return c.JSON(http.StatusOK, response)
+}
+
+// GetStatusMapping gerencia GET /plataformas/{plataforma}/mapeamento-status
+// Retorna como os status brutos da plataforma são classificados nos status da API
+func (sh *StoreHandler) GetStatusMapping(c echo.Context) error {
+	response, err := sh.platformService.StatusMapping(models.Plataforma(c.Param("plataforma")))
+	if err != nil {
+		return sh.handlePlatformError(c, err)
+	}
+	return c.JSON(http.StatusOK, response)
+}
+
+// presentStatus devolve os IDs originais do cliente, remove os detalhes quando não solicitados e
+// aplica o formato do documento
+func (sh *StoreHandler) presentStatus(plataforma models.Plataforma, originais map[string]string, response *models.RespostaStatusMultiplasLojas, verbose bool, formatoDocumento models.FormatoDocumento) {
+	for i := range response.Lojas {
+		sh.presentLoja(plataforma, originais, &response.Lojas[i], verbose, formatoDocumento)
+	}
+}
+
+// presentLoja devolve o ID original do cliente para uma loja, remove os detalhes quando não
+// solicitados e aplica o formato do documento
+func (sh *StoreHandler) presentLoja(plataforma models.Plataforma, originais map[string]string, loja *models.StatusLojaDetalhes, verbose bool, formatoDocumento models.FormatoDocumento) {
+	loja.IdLoja, loja.IdPlataforma = sh.resolveIDs(plataforma, originais, loja.IdLoja)
+	// A versão usa o documento limpo, para que o If-Match não dependa do formato pedido
+	if loja.Status != models.StatusNaoEncontrado && loja.Status != models.StatusInvalido {
+		loja.Versao = services.StoreVersion(loja.Status, loja.Documento)
+	}
+	if formatoDocumento == models.FormatoDocumentoFormatado {
+		loja.Documento = utils.FormatDocument(loja.Documento)
+	}
+
+	// Remove os detalhes quando não solicitados para manter o formato padrão
+	if !verbose {
+		loja.Detalhes = nil
+	}
+}
+
+// cleanIDList remove espaços, IDs vazios e duplicados (mantendo a primeira ocorrência),
+// retornando também quantos vazios e duplicados foram descartados
+func cleanIDList(ids []string) ([]string, int, int) {
+	limpos := make([]string, 0, len(ids))
+	vistos := make(map[string]bool, len(ids))
+	vazios, duplicados := 0, 0
+	for _, id := range ids {
+		id = strings.TrimSpace(id)
+		switch {
+		case id == "":
+			vazios++
+		case vistos[id]:
+			duplicados++
+		default:
+			vistos[id] = true
+			limpos = append(limpos, id)
+		}
+	}
+	return limpos, vazios, duplicados
+}
+
+// parseOrdenacao interpreta o query param sort, usando a ordenação por ID quando ausente
+func parseOrdenacao(value string) (models.OrdenacaoLojas, bool) {
+	if value == "" {
+		return models.OrdenarPorID, true
+	}
+	ordenacao := models.OrdenacaoLojas(value)
+	return ordenacao, ordenacao.IsValid()
+}
+
+// parseFormato interpreta o query param formato, usando o DTO Loja quando ausente
+func parseFormato(value string) (models.FormatoLojas, bool) {
+	if value == "" {
+		return models.FormatoNormalizado, true
+	}
+	formato := models.FormatoLojas(value)
+	return formato, formato.IsValid()
+}
+
+// invalidFormatoMessage é a mensagem de erro para um query param formato desconhecido
+const invalidFormatoMessage = "Parâmetro formato deve ser 'loja' ou 'legado'"
+
+// parseFormatoDocumento interpreta o query param formato_documento, mantendo o documento limpo quando ausente
+func parseFormatoDocumento(value string) (models.FormatoDocumento, bool) {
+	if value == "" {
+		return models.FormatoDocumentoLimpo, true
+	}
+	formato := models.FormatoDocumento(value)
+	return formato, formato.IsValid()
+}
+
+// invalidFormatoDocumentoMessage é a mensagem de erro para um query param formato_documento desconhecido
+const invalidFormatoDocumentoMessage = "Parâmetro formato_documento deve ser 'limpo' ou 'formatado'"
+
+// errIDsEPrioridades rejeita um body com 'ids_lojas' e os grupos de prioridade ao mesmo tempo
+var errIDsEPrioridades = errors.New("Informe 'ids_lojas' ou 'prioridade_alta'/'prioridade_normal', não ambos")
+
+// mergePriorityGroups move para req.IdsLojas os IDs agrupados por prioridade, com os de prioridade
+// alta à frente da lista. O pool de workers despacha as lojas na ordem recebida, então elas são
+// processadas antes das demais. Usado pelas operações em lote e por /validar, que aceitam o mesmo body
+func mergePriorityGroups(req *models.RequisicaoMultiplasLojas) (map[string]models.Prioridade, error) {
+	if len(req.PrioridadeAlta) == 0 && len(req.PrioridadeNormal) == 0 {
+		return nil, nil
+	}
+	if len(req.IdsLojas) > 0 {
+		return nil, errIDsEPrioridades
+	}
+	var prioridades map[string]models.Prioridade
+	req.IdsLojas, prioridades = groupByPriority(req.PrioridadeAlta, req.PrioridadeNormal)
+	return prioridades, nil
+}
+
+// groupByPriority junta os grupos de prioridade em uma única lista, os de prioridade alta primeiro,
+// retornando também o grupo de cada ID para identificá-lo nos resultados
+func groupByPriority(alta, normal []string) ([]string, map[string]models.Prioridade) {
+	ids := make([]string, 0, len(alta)+len(normal))
+	prioridades := make(map[string]models.Prioridade, len(alta)+len(normal))
+	for _, grupo := range []struct {
+		ids        []string
+		prioridade models.Prioridade
+	}{{alta, models.PrioridadeAlta}, {normal, models.PrioridadeNormal}} {
+		for _, id := range grupo.ids {
+			ids = append(ids, id)
+			if _, exists := prioridades[id]; !exists {
+				prioridades[id] = grupo.prioridade
+			}
+		}
+	}
+	return ids, prioridades
+}
+
+// headerConfirmBulk confirma a quantidade de lojas de um lote acima de BULK_CONFIRM_THRESHOLD
+const headerConfirmBulk = "X-Confirm-Bulk"
+
+// confirmsBulk indica se o valor de X-Confirm-Bulk é exatamente a quantidade de lojas do lote
+func confirmsBulk(value string, total int) bool {
+	confirmado, err := strconv.Atoi(strings.TrimSpace(value))
+	return err == nil && confirmado == total
+}
+
+// Headers de controle de concorrência otimista
+const (
+	headerIfMatch = "If-Match"
+	headerETag    = "ETag"
+)
+
+// parseIfMatch extrai as versões de um header If-Match, removendo aspas e o prefixo W/
+func parseIfMatch(value string) []string {
+	var versoes []string
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimPrefix(strings.TrimSpace(item), "W/")
+		if item = strings.Trim(item, `"`); item != "" {
+			versoes = append(versoes, item)
+		}
+	}
+	return versoes
+}
+
+// setETag informa a versão da loja no header ETag, para ser devolvida em If-Match
+func setETag(c echo.Context, versao string) {
+	if versao != "" {
+		c.Response().Header().Set(headerETag, `"`+versao+`"`)
+	}
+}
+
+// maxIDLength é o tamanho máximo aceito para um ID de loja
+const maxIDLength = 128
+
+// validateIDList verifica cada ID da lista, retornando um detalhe para cada ID vazio, duplicado
+// (a primeira ocorrência é aceita) ou maior que maxIDLength
+func validateIDList(ids []string) []models.DetalheValidacao {
+	var detalhes []models.DetalheValidacao
+	vistos := make(map[string]bool, len(ids))
+	for i, id := range ids {
+		motivo := ""
+		switch trimmed := strings.TrimSpace(id); {
+		case trimmed == "":
+			motivo = models.MotivoIDVazio
+		case len(trimmed) > maxIDLength:
+			motivo = models.MotivoIDMuitoLongo
+		case vistos[trimmed]:
+			motivo = models.MotivoIDDuplicado
+		default:
+			vistos[trimmed] = true
+			continue
+		}
+		detalhes = append(detalhes, models.DetalheValidacao{Indice: i, IdLoja: id, Motivo: motivo})
+	}
+	return detalhes
+}
+
+// parseIDList separa uma lista de IDs por vírgula e remove espaços e itens vazios
+func parseIDList(value string) []string {
+	var ids []string
+	for _, id := range strings.Split(value, ",") {
+		id = strings.TrimSpace(id)
+		if id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+
+	"delivery-control/internal/models"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Campos do formulário multipart aceito pelas operações em lote
+const (
+	formArquivo = "arquivo"
+	formMotivo  = "motivo"
+)
+
+// utf8BOM é a marca de ordem de bytes gravada por algumas planilhas no início do arquivo
+const utf8BOM = "\ufeff"
+
+// maxIDsFileSize limita o tamanho do arquivo de IDs enviado nas operações em lote
+const maxIDsFileSize = 5 << 20
+
+// idColumns são os nomes de coluna reconhecidos como a coluna de IDs em um CSV com cabeçalho
+var idColumns = []string{"id", "id_loja", "ids_lojas", "idloja"}
+
+// bindBulkRequest decodifica o body das operações em lote. Além do JSON, aceita
+// multipart/form-data com um arquivo de IDs no campo "arquivo" (um ID por linha ou um CSV
+// com cabeçalho contendo a coluna "id"/"id_loja") e o motivo opcional no campo "motivo".
+// Linhas vazias e IDs repetidos do arquivo são descartados
+func bindBulkRequest(c echo.Context) (models.RequisicaoMultiplasLojas, error) {
+	var req models.RequisicaoMultiplasLojas
+	if !strings.HasPrefix(c.Request().Header.Get(echo.HeaderContentType), echo.MIMEMultipartForm) {
+		err := c.Bind(&req)
+		return req, err
+	}
+
+	// Limita o body ao tamanho do arquivo com folga para os demais campos do formulário
+	c.Request().Body = http.MaxBytesReader(c.Response(), c.Request().Body, maxIDsFileSize+1<<20)
+	header, err := c.FormFile(formArquivo)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return req, fmt.Errorf("arquivo excede o limite de %d MB", maxIDsFileSize>>20)
+		}
+		return req, fmt.Errorf("campo '%s' com o arquivo de IDs é obrigatório", formArquivo)
+	}
+	if header.Size > maxIDsFileSize {
+		return req, fmt.Errorf("arquivo excede o limite de %d MB", maxIDsFileSize>>20)
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		return req, fmt.Errorf("erro ao abrir o arquivo: %w", err)
+	}
+	defer file.Close()
+
+	ids, err := readIDsFile(file)
+	if err != nil {
+		return req, fmt.Errorf("arquivo %s inválido: %w", header.Filename, err)
+	}
+	req.IdsLojas, _, _ = cleanIDList(ids)
+	req.Motivo = c.FormValue(formMotivo)
+	return req, nil
+}
+
+// readIDsFile lê os IDs de um arquivo com um ID por linha ou de um CSV (separado por vírgula ou
+// ponto e vírgula). Com cabeçalho contendo uma coluna de IDs, usa essa coluna; caso contrário,
+// usa a primeira coluna de todas as linhas
+func readIDsFile(file io.Reader) ([]string, error) {
+	buffered := bufio.NewReader(file)
+	// Planilhas costumam gravar o BOM do UTF-8 no início do arquivo
+	if bom, _ := buffered.Peek(len(utf8BOM)); string(bom) == utf8BOM {
+		_, _ = buffered.Discard(len(utf8BOM))
+	}
+
+	reader := csv.NewReader(buffered)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	reader.ReuseRecord = true
+	if firstLine, _ := buffered.Peek(4096); detectSemicolon(firstLine) {
+		reader.Comma = ';'
+	}
+
+	var ids []string
+	coluna := 0
+	for linha := 0; ; linha++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if linha == 0 {
+			if i := slices.IndexFunc(record, isIDColumn); i >= 0 {
+				coluna = i
+				continue
+			}
+		}
+		if coluna < len(record) {
+			ids = append(ids, record[coluna])
+		}
+	}
+	return ids, nil
+}
+
+// detectSemicolon indica se a primeira linha usa ponto e vírgula como separador, como nos CSVs
+// exportados por planilhas em português
+func detectSemicolon(data []byte) bool {
+	line, _, _ := strings.Cut(string(data), "\n")
+	return strings.Contains(line, ";") && !strings.Contains(line, ",")
+}
+
+// isIDColumn indica se o nome da coluna identifica a coluna de IDs
+func isIDColumn(nome string) bool {
+	return slices.Contains(idColumns, strings.ToLower(strings.TrimSpace(nome)))
+}
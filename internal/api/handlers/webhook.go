@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"net/http"
+
+	"delivery-control/internal/api/apierror"
+	"delivery-control/internal/models"
+	"delivery-control/internal/webhook"
+
+	"github.com/labstack/echo/v4"
+)
+
+// WebhookHandler gerencia requisições administrativas dos webhooks
+type WebhookHandler struct {
+	webhooks *webhook.Notifier
+}
+
+// NewWebhookHandler cria um novo handler de webhooks
+func NewWebhookHandler(webhooks *webhook.Notifier) *WebhookHandler {
+	return &WebhookHandler{
+		webhooks: webhooks,
+	}
+}
+
+// Test gerencia POST /webhooks/test
+// Envia um evento webhook.teste assinado para WEBHOOK_URL e aguarda a entrega, incluindo as
+// novas tentativas, para validar o endpoint e a assinatura antes de depender dos eventos reais.
+// Falhas na entrega são informadas no corpo (sucesso=false), com status 200; a espera respeita
+// X-Request-Timeout e a desconexão do cliente (504 quando o prazo termina antes da entrega)
+func (h *WebhookHandler) Test(c echo.Context) error {
+	if !h.webhooks.Enabled() {
+		return apierror.Respond(c, http.StatusBadRequest, models.ErroRequisicaoInvalida, "Webhook não configurado (WEBHOOK_URL vazia)")
+	}
+
+	entrega, err := h.webhooks.Deliver(c.Request().Context(), webhook.EventoTeste, map[string]string{
+		"mensagem":   "Evento de teste enviado por POST /webhooks/test",
+		"request_id": c.Response().Header().Get(echo.HeaderXRequestID),
+	})
+	if err != nil {
+		return apierror.Respond(c, http.StatusInternalServerError, models.ErroInternoServidor, err.Error())
+	}
+	// O prazo de X-Request-Timeout terminou (ou o cliente desconectou) antes da entrega
+	if c.Request().Context().Err() != nil {
+		return apierror.Respond(c, http.StatusGatewayTimeout, models.ErroTempoEsgotado, "Tempo limite da requisição excedido antes da entrega do webhook")
+	}
+
+	response := models.RespostaTesteWebhook{
+		URL:        h.webhooks.URL(),
+		Evento:     webhook.EventoTeste,
+		Sucesso:    entrega.Err == nil,
+		StatusHTTP: entrega.StatusHTTP,
+		Tentativas: entrega.Tentativas,
+		LatenciaMs: entrega.Duracao.Milliseconds(),
+	}
+	if entrega.Err != nil {
+		response.Erro = entrega.Err.Error()
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
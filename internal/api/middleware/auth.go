@@ -4,51 +4,84 @@ import (
 	"net/http"
 	"strings"
 
+	"delivery-control/internal/api/apierror"
 	"delivery-control/internal/config"
 	"delivery-control/internal/models"
 
 	"github.com/labstack/echo/v4"
 )
 
+// ScopeKey é a chave do contexto do Echo com o escopo do token autenticado
+const ScopeKey = "auth_scope"
+
 // AuthMiddleware cria um novo middleware de autenticação
+// O escopo do token (read ou write) é guardado no contexto em ScopeKey, para uso em RequireScope.
+// Se os tokens configurados forem inválidos, nenhum token é aceito: a resposta é 401 ou, com
+// AUTH_FAIL_MODE=maintenance, 503
 func AuthMiddleware(cfg *config.Config) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
+			if err := cfg.Auth.Err(); err != nil {
+				if cfg.Auth.FailMode == config.AuthFailMaintenance {
+					return apierror.Respond(c, http.StatusServiceUnavailable, models.ErroServicoIndisponivel, "Serviço em manutenção")
+				}
+				return apierror.Respond(c, http.StatusUnauthorized, models.ErroNaoAutorizado, "Token inválido")
+			}
+
 			// Extrai o header de Authorization
 			authHeader := c.Request().Header.Get("Authorization")
 			if authHeader == "" {
-				return c.JSON(http.StatusUnauthorized, models.RespostaErro{
-					Error:    models.ErroNaoAutorizado,
-					Mensagem: "Token de autorização é obrigatório",
-				})
+				return apierror.Respond(c, http.StatusUnauthorized, models.ErroNaoAutorizado, "Token de autorização é obrigatório")
 			}
 
 			// Verifica se começa com "Bearer "
 			if !strings.HasPrefix(authHeader, "Bearer ") {
-				return c.JSON(http.StatusUnauthorized, models.RespostaErro{
-					Error:    models.ErroNaoAutorizado,
-					Mensagem: "Formato do token inválido. Use 'Bearer <token>'",
-				})
+				return apierror.Respond(c, http.StatusUnauthorized, models.ErroNaoAutorizado, "Formato do token inválido. Use 'Bearer <token>'")
 			}
 
 			// Extrai o token
 			token := strings.TrimPrefix(authHeader, "Bearer ")
 			if token == "" {
-				return c.JSON(http.StatusUnauthorized, models.RespostaErro{
-					Error:    models.ErroNaoAutorizado,
-					Mensagem: "Token não fornecido",
-				})
+				return apierror.Respond(c, http.StatusUnauthorized, models.ErroNaoAutorizado, "Token não fornecido")
 			}
 
-			// Valida o token contra o token configurado
-			if token != cfg.Auth.BearerToken {
-				return c.JSON(http.StatusUnauthorized, models.RespostaErro{
-					Error:    models.ErroNaoAutorizado,
-					Mensagem: "Token inválido",
-				})
+			// Valida o token contra os tokens configurados
+			scope, ok := cfg.Auth.ScopeFor(token)
+			if !ok {
+				return apierror.Respond(c, http.StatusUnauthorized, models.ErroNaoAutorizado, "Token inválido")
 			}
 
 			// Token é válido, prossegue para o próximo handler
+			c.Set(ScopeKey, scope)
+			return next(c)
+		}
+	}
+}
+
+// MetricsAuth protege o /metrics: aceita o token de METRICS_TOKEN, exclusivo do coletor de métricas,
+// e qualquer token da API, validado como em AuthMiddleware
+func MetricsAuth(cfg *config.Config) echo.MiddlewareFunc {
+	auth := AuthMiddleware(cfg)
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		authenticated := auth(next)
+		return func(c echo.Context) error {
+			if cfg.Auth.MetricsToken != "" && c.Request().Header.Get("Authorization") == "Bearer "+cfg.Auth.MetricsToken {
+				return next(c)
+			}
+			return authenticated(c)
+		}
+	}
+}
+
+// RequireScope restringe a rota aos tokens com o escopo informado, respondendo 403 aos demais.
+// Deve ser usado após AuthMiddleware. Tokens write atendem também as rotas read
+func RequireScope(scope string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			tokenScope, _ := c.Get(ScopeKey).(string)
+			if tokenScope != scope && tokenScope != config.ScopeWrite {
+				return apierror.Respond(c, http.StatusForbidden, models.ErroProibido, "Token sem permissão para esta operação (escopo necessário: "+scope+")")
+			}
 			return next(c)
 		}
 	}
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"delivery-control/internal/config"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestMetricsAuth(t *testing.T) {
+	cfg := &config.Config{Auth: config.AuthConfig{
+		BearerToken:  "api-token",
+		FailMode:     config.AuthFailClosed,
+		MetricsToken: "metrics-token",
+	}}
+	e := echo.New()
+	e.GET("/metrics", func(c echo.Context) error { return c.String(http.StatusOK, "ok") }, MetricsAuth(cfg))
+
+	tests := []struct {
+		name          string
+		authorization string
+		want          int
+	}{
+		{name: "sem token", want: http.StatusUnauthorized},
+		{name: "token de métricas", authorization: "Bearer metrics-token", want: http.StatusOK},
+		{name: "token da API", authorization: "Bearer api-token", want: http.StatusOK},
+		{name: "token desconhecido", authorization: "Bearer outro", want: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, esperado %d", rec.Code, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	echomiddleware "github.com/labstack/echo/v4/middleware"
+)
+
+// gzipSkippedPaths são as rotas que não passam pela compressão: o /metrics já é comprimido pelo
+// promhttp, e compressão duplicada corromperia a resposta
+var gzipSkippedPaths = []string{"/metrics"}
+
+// Gzip comprime as respostas dos clientes que enviam "Accept-Encoding: gzip", a partir de
+// minLength bytes (respostas menores não compensam o custo da compressão). As respostas em
+// streaming (Server-Sent Events e NDJSON) não são comprimidas, para que cada evento chegue ao
+// cliente assim que é enviado, sem ficar retido no buffer do gzip
+func Gzip(minLength int) echo.MiddlewareFunc {
+	return echomiddleware.GzipWithConfig(echomiddleware.GzipConfig{
+		MinLength: minLength,
+		Skipper:   skipGzip,
+	})
+}
+
+// skipGzip indica se a requisição deve ser respondida sem compressão
+func skipGzip(c echo.Context) bool {
+	path := c.Request().URL.Path
+	for _, skipped := range gzipSkippedPaths {
+		if path == skipped {
+			return true
+		}
+	}
+	// GET .../lojas/status/stream (SSE) e POST .../lojas/status (NDJSON)
+	if strings.HasSuffix(path, "/stream") || strings.Contains(c.Request().Header.Get(echo.HeaderAccept), "text/event-stream") {
+		return true
+	}
+	return c.Request().Method == http.MethodPost && strings.HasSuffix(path, "/lojas/status")
+}
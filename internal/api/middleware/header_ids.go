@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"delivery-control/internal/api/apierror"
+	"delivery-control/internal/models"
+
+	"github.com/labstack/echo/v4"
+)
+
+// HeaderLojasIDs é o header com os IDs das lojas separados por vírgula nas consultas de status
+const HeaderLojasIDs = "X-Lojas-IDs"
+
+// MaxHeaderIDs rejeita com 400 as requisições com mais de limite IDs no header X-Lojas-IDs,
+// orientando o cliente a enviar os IDs no body. Proxies costumam limitar o tamanho dos headers e
+// truncar ou recusar a requisição antes que ela chegue à API, com um erro difícil de diagnosticar.
+// Com limite 0 o middleware não restringe nada
+func MaxHeaderIDs(limite int) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if limite <= 0 {
+				return next(c)
+			}
+
+			if quantidade := countIDs(c.Request().Header.Get(HeaderLojasIDs)); quantidade > limite {
+				return apierror.Respond(c, http.StatusBadRequest, models.ErroRequisicaoInvalida, fmt.Sprintf(
+					"Header %s com %d IDs excede o limite de %d; envie os IDs no body com POST /plataformas/{plataforma}/lojas/status",
+					HeaderLojasIDs, quantidade, limite))
+			}
+			return next(c)
+		}
+	}
+}
+
+// countIDs conta os IDs não vazios de uma lista separada por vírgula
+func countIDs(value string) int {
+	quantidade := 0
+	for id := range strings.SplitSeq(value, ",") {
+		if strings.TrimSpace(id) != "" {
+			quantidade++
+		}
+	}
+	return quantidade
+}
@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"delivery-control/internal/models"
+
+	"github.com/labstack/echo/v4"
+)
+
+// paramPlataforma é o parâmetro de rota com a plataforma
+const paramPlataforma = "plataforma"
+
+// CanonicalPlatform substitui o parâmetro :plataforma pelo identificador canônico, para que
+// /plataformas/AnotaAi/... seja atendida como /plataformas/anotaai/... e as respostas sempre
+// tragam a plataforma em minúsculas, independente de como o cliente escreveu o caminho
+func CanonicalPlatform(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		nomes := c.ParamNames()
+		for i, nome := range nomes {
+			if nome != paramPlataforma {
+				continue
+			}
+			valores := append([]string(nil), c.ParamValues()...)
+			if canonica := string(models.ParsePlataforma(valores[i])); canonica != valores[i] {
+				valores[i] = canonica
+				c.SetParamValues(valores...)
+			}
+			break
+		}
+		return next(c)
+	}
+}
@@ -0,0 +1,90 @@
+package middleware
+
+import (
+	"encoding/json"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	echomiddleware "github.com/labstack/echo/v4/middleware"
+)
+
+// requestLogLine tem os mesmos campos do formato padrão do logger do Echo, para não quebrar
+// quem já consome os logs de requisição
+type requestLogLine struct {
+	Time         string `json:"time"`
+	ID           string `json:"id"`
+	RemoteIP     string `json:"remote_ip"`
+	Host         string `json:"host"`
+	Method       string `json:"method"`
+	URI          string `json:"uri"`
+	UserAgent    string `json:"user_agent"`
+	Status       int    `json:"status"`
+	Error        string `json:"error"`
+	Latency      int64  `json:"latency"`
+	LatencyHuman string `json:"latency_human"`
+	BytesIn      string `json:"bytes_in"`
+	BytesOut     int64  `json:"bytes_out"`
+}
+
+// SampledRequestLogger registra as requisições como o logger padrão do Echo, mas registra apenas
+// a fração sampleRate (entre 0 e 1) das consultas (GET/HEAD) bem-sucedidas, que com o polling de
+// status dominariam o log. Alterações e respostas de erro (status >= 400) são sempre registradas
+func SampledRequestLogger(output io.Writer, sampleRate float64) echo.MiddlewareFunc {
+	return echomiddleware.RequestLoggerWithConfig(echomiddleware.RequestLoggerConfig{
+		// Converte o erro do handler na resposta antes do registro, para que o status logado seja o enviado
+		HandleError:      true,
+		LogLatency:       true,
+		LogRemoteIP:      true,
+		LogHost:          true,
+		LogMethod:        true,
+		LogURI:           true,
+		LogUserAgent:     true,
+		LogStatus:        true,
+		LogError:         true,
+		LogRequestID:     true,
+		LogContentLength: true,
+		LogResponseSize:  true,
+		LogValuesFunc: func(c echo.Context, v echomiddleware.RequestLoggerValues) error {
+			if !shouldLogRequest(v, sampleRate) {
+				return nil
+			}
+
+			line := requestLogLine{
+				Time:         v.StartTime.Format(time.RFC3339Nano),
+				ID:           v.RequestID,
+				RemoteIP:     v.RemoteIP,
+				Host:         v.Host,
+				Method:       v.Method,
+				URI:          v.URI,
+				UserAgent:    v.UserAgent,
+				Status:       v.Status,
+				Latency:      int64(v.Latency),
+				LatencyHuman: v.Latency.String(),
+				BytesIn:      v.ContentLength,
+				BytesOut:     v.ResponseSize,
+			}
+			if v.Error != nil {
+				line.Error = v.Error.Error()
+			}
+			data, err := json.Marshal(line)
+			if err != nil {
+				return err
+			}
+			_, err = output.Write(append(data, '\n'))
+			return err
+		},
+	})
+}
+
+// shouldLogRequest indica se a requisição deve ser registrada: apenas as consultas bem-sucedidas
+// passam pela amostragem
+func shouldLogRequest(v echomiddleware.RequestLoggerValues, sampleRate float64) bool {
+	consulta := v.Method == http.MethodGet || v.Method == http.MethodHead
+	if !consulta || v.Error != nil || v.Status >= http.StatusBadRequest {
+		return true
+	}
+	return sampleRate >= 1 || rand.Float64() < sampleRate
+}
@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"delivery-control/internal/api/apierror"
+	"delivery-control/internal/models"
+
+	"github.com/labstack/echo/v4"
+)
+
+// HeaderRequestTimeout é o header com o prazo que o cliente aceita aguardar pela resposta (ex.: "2s")
+const HeaderRequestTimeout = "X-Request-Timeout"
+
+// RequestTimeout aplica ao contexto da requisição o prazo informado pelo cliente em
+// X-Request-Timeout, limitado a maximo. As consultas à plataforma que não terminam no prazo
+// respondem 504, e as operações em lote reportam como nao_processado/tempo_esgotado as lojas
+// restantes. Sem o header, valem os prazos padrão do servidor
+func RequestTimeout(maximo time.Duration) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			value := c.Request().Header.Get(HeaderRequestTimeout)
+			if value == "" {
+				return next(c)
+			}
+
+			timeout, err := time.ParseDuration(value)
+			if err != nil || timeout <= 0 {
+				return apierror.Respond(c, http.StatusBadRequest, models.ErroRequisicaoInvalida,
+					fmt.Sprintf("Header %s inválido. Use uma duração positiva como '5s'", HeaderRequestTimeout))
+			}
+			timeout = min(timeout, maximo)
+
+			ctx, cancel := context.WithTimeout(c.Request().Context(), timeout)
+			defer cancel()
+			c.SetRequest(c.Request().WithContext(ctx))
+			return next(c)
+		}
+	}
+}
@@ -1,19 +1,33 @@
 package routes
 
 import (
+	"delivery-control/internal/api/apierror"
 	"delivery-control/internal/api/handlers"
 	"delivery-control/internal/api/middleware"
 	"delivery-control/internal/config"
 
 	"github.com/labstack/echo/v4"
 	echomiddleware "github.com/labstack/echo/v4/middleware"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 // SetupRoutes configura todas as rotas da aplicação
-func SetupRoutes(e *echo.Echo, cfg *config.Config, healthHandler *handlers.HealthHandler, storeHandler *handlers.StoreHandler, docsHandler *handlers.DocsHandler) {
+func SetupRoutes(e *echo.Echo, cfg *config.Config, healthHandler *handlers.HealthHandler, storeHandler *handlers.StoreHandler, cacheHandler *handlers.CacheHandler, webhookHandler *handlers.WebhookHandler, docsHandler *handlers.DocsHandler, adminHandler *handlers.AdminHandler) {
+	// Erros não tratados pelos handlers seguem o formato padrão de RespostaErro
+	e.HTTPErrorHandler = apierror.Handler
+
+	// A forma canônica das rotas é sem barra final: /lojas/status/ é reescrita para /lojas/status
+	// antes do roteamento, para que as duas formas cheguem ao mesmo handler
+	e.Pre(echomiddleware.RemoveTrailingSlash())
+
 	// Adiciona middleware comum
+	e.Use(echomiddleware.RequestID())
 	e.Use(echomiddleware.Recover())
 	e.Use(echomiddleware.CORS())
+	// Compressão das respostas das rotas públicas e protegidas (as de streaming são ignoradas)
+	if cfg.Server.Gzip {
+		e.Use(middleware.Gzip(cfg.Server.GzipMinLength))
+	}
 
 	// Cria um grupo para rotas públicas (sem autenticação)
 	public := e.Group("")
@@ -21,17 +35,92 @@ func SetupRoutes(e *echo.Echo, cfg *config.Config, healthHandler *handlers.Healt
 	// Health check
 	public.GET("/health", healthHandler.Check)
 
-	// Documentação da API
-	public.GET("/docs", docsHandler.ServeHTML)
-	public.GET("/docs/openapi.yml", docsHandler.ServeOpenAPI)
+	// Métricas Prometheus, fora do grupo protegido para não passar pelo log de requisições, mas
+	// autenticadas com METRICS_TOKEN ou com um token da API
+	public.GET("/metrics", echo.WrapHandler(promhttp.Handler()), middleware.MetricsAuth(cfg))
+
+	// Documentação da API (pode ser desabilitada para não expor a API em produção)
+	if cfg.Docs.Enabled {
+		public.GET("/docs", docsHandler.ServeHTML)
+		public.GET("/docs/openapi.yml", docsHandler.ServeOpenAPI)
+	} else {
+		// Sem estas rotas, /docs cairia no grupo protegido e responderia 401 em vez de 404
+		notFound := func(c echo.Context) error { return echo.ErrNotFound }
+		e.RouteNotFound("/docs", notFound)
+		e.RouteNotFound("/docs/*", notFound)
+	}
 
 	// Cria um grupo para rotas protegidas com logger
 	protected := e.Group("")
-	protected.Use(echomiddleware.Logger())
+	// Com LOG_SAMPLE_RATE < 1, apenas parte das consultas bem-sucedidas é registrada
+	protected.Use(middleware.SampledRequestLogger(e.Logger.Output(), cfg.Log.SampleRate))
 	protected.Use(middleware.AuthMiddleware(cfg))
+	// X-Request-Timeout define o prazo da requisição, limitado por REQUEST_TIMEOUT_MAX
+	protected.Use(middleware.RequestTimeout(cfg.Server.MaxRequestTimeout))
+	// Normaliza o :plataforma antes dos demais middlewares e handlers, que usam o identificador canônico
+	protected.Use(middleware.CanonicalPlatform)
+	// X-Platform-Base-URL (somente com ALLOW_URL_OVERRIDE=true) direciona a requisição para outra URL da plataforma
+	protected.Use(storeHandler.PlatformURLOverride)
+
+	// Rotas que alteram lojas ou o cache exigem um token com escopo write; as demais aceitam read
+	write := middleware.RequireScope(config.ScopeWrite)
+	// Com o modo de manutenção ativo, as rotas que alteram lojas respondem 503; as consultas continuam
+	mutation := adminHandler.RejectDuringMaintenance
+	// Consultas com IDs demais no header devem usar o body, já que proxies limitam o tamanho dos headers
+	headerIDs := middleware.MaxHeaderIDs(cfg.Server.MaxHeaderIDs)
 
 	// Operações de loja
-	protected.PATCH("/plataformas/:plataforma/lojas/ativar", storeHandler.ActivateMultiple)
-	protected.PATCH("/plataformas/:plataforma/lojas/desativar", storeHandler.DeactivateMultiple)
-	protected.GET("/plataformas/:plataforma/lojas/status", storeHandler.GetMultipleStatus)
+	protected.PATCH("/plataformas/:plataforma/lojas/ativar", storeHandler.ActivateMultiple, write, mutation)
+	protected.PATCH("/plataformas/:plataforma/lojas/desativar", storeHandler.DeactivateMultiple, write, mutation)
+	// Alternativas em POST para uso via linha de comando com ?ids=1,2,3
+	protected.POST("/plataformas/:plataforma/lojas/ativar", storeHandler.ActivateMultiple, write, mutation)
+	protected.POST("/plataformas/:plataforma/lojas/desativar", storeHandler.DeactivateMultiple, write, mutation)
+	// Versões idempotentes: só chamam a plataforma para as lojas que ainda não estão no status pretendido
+	protected.POST("/plataformas/:plataforma/lojas/garantir-ativas", storeHandler.EnsureActive, write, mutation)
+	protected.POST("/plataformas/:plataforma/lojas/garantir-bloqueadas", storeHandler.EnsureBlocked, write, mutation)
+	protected.POST("/plataformas/:plataforma/lojas/validar", storeHandler.ValidateBulk)
+	protected.POST("/plataformas/:plataforma/lojas/reconciliar/diff", storeHandler.ReconcileDiff)
+	protected.GET("/plataformas/:plataforma/lojas/status", storeHandler.GetMultipleStatus, headerIDs)
+	protected.POST("/plataformas/:plataforma/lojas/status", storeHandler.StreamMultipleStatus, headerIDs)
+	protected.GET("/plataformas/:plataforma/lojas/status/stream", storeHandler.StatusEventStream)
+	protected.GET("/plataformas/:plataforma/lojas/resumo", storeHandler.GetStatusSummary, headerIDs)
+	protected.GET("/plataformas/:plataforma/lojas/:idLoja/status", storeHandler.GetStoreStatus)
+	protected.POST("/plataformas/:plataforma/lojas/:idLoja/sincronizar", storeHandler.SyncStore, write)
+	protected.PUT("/plataformas/:plataforma/lojas/:idLoja/status", storeHandler.SetStoreStatus, write, mutation)
+	protected.GET("/lojas/status", storeHandler.GetAllPlatformsStatus, headerIDs)
+	protected.GET("/lojas/duplicadas", storeHandler.FindDuplicates)
+	// HEAD para ferramentas de monitoramento: mesmos headers do GET, sem corpo
+	// (o net/http descarta o corpo escrito em respostas a HEAD)
+	protected.HEAD("/plataformas/:plataforma/lojas/status", storeHandler.GetMultipleStatus, headerIDs)
+	protected.HEAD("/plataformas/:plataforma/lojas/:idLoja/status", storeHandler.GetStoreStatus)
+	protected.HEAD("/lojas/status", storeHandler.GetAllPlatformsStatus, headerIDs)
+
+	// Limpeza manual do cache de status
+	protected.POST("/plataformas/:plataforma/cache/limpar", cacheHandler.Clear, write)
+	protected.POST("/cache/limpar", cacheHandler.Clear, write)
+
+	// Modo de manutenção, que suspende as ativações e desativações sem reiniciar a API
+	protected.GET("/admin/manutencao", adminHandler.GetMaintenance)
+	protected.POST("/admin/manutencao", adminHandler.SetMaintenance, write)
+
+	// Envio de um evento de teste ao webhook configurado
+	protected.POST("/webhooks/test", webhookHandler.Test, write)
+
+	// Diagnóstico: dados brutos das plataformas, nunca expostos por padrão
+	if cfg.Debug.Endpoints {
+		protected.GET("/plataformas/:plataforma/lojas/:idLoja/raw", storeHandler.GetRawStore)
+	}
+
+	// Plataformas suportadas e suas operações
+	protected.GET("/plataformas", storeHandler.ListPlatforms)
+	protected.GET("/plataformas/:plataforma/mapeamento-status", storeHandler.GetStatusMapping)
+
+	// Verificação ativa de credenciais das plataformas
+	protected.GET("/plataformas/:plataforma/ping", storeHandler.Ping)
+
+	// Contadores em JSON, para ambientes sem Prometheus
+	protected.GET("/stats", healthHandler.Stats)
+
+	// Últimas operações executadas, mantidas em memória para depuração
+	protected.GET("/operacoes/recentes", storeHandler.ListRecentOperations)
 }
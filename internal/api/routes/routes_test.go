@@ -0,0 +1,528 @@
+package routes
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"delivery-control/internal/api/handlers"
+	"delivery-control/internal/api/middleware"
+	"delivery-control/internal/config"
+	"delivery-control/internal/models"
+	"delivery-control/internal/repository"
+	"delivery-control/internal/services"
+	"delivery-control/internal/testutil/fakeplatform"
+	"delivery-control/internal/webhook"
+
+	"github.com/labstack/echo/v4"
+)
+
+// newTestServer monta as rotas da API apontando para servidores falsos novos do AnotaAI e do
+// DeliveryVip. As chamadas aguardam o primeiro login em vez de falhar com token indisponível
+func newTestServer(t *testing.T, configure ...func(*config.Config)) (*echo.Echo, *fakeplatform.Server, *fakeplatform.Server) {
+	t.Helper()
+
+	anotaAi := fakeplatform.NewAnotaAi()
+	deliveryVip := fakeplatform.NewDeliveryVip()
+	t.Cleanup(anotaAi.Close)
+	t.Cleanup(deliveryVip.Close)
+
+	cfg := fakeplatform.Config(anotaAi, deliveryVip)
+	cfg.Platforms.TokenWaitTimeout = 5 * time.Second
+	for _, fn := range configure {
+		fn(cfg)
+	}
+
+	platformService := services.NewPlatformService(cfg, repository.NewMemory())
+	idMapper, err := services.NewIDMapper("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	notifier := webhook.New(cfg.Webhook)
+	maintenance := services.NewMaintenanceMode(cfg.Server.Maintenance)
+
+	e := echo.New()
+	SetupRoutes(e, cfg,
+		handlers.NewHealthHandler(maintenance),
+		handlers.NewStoreHandler(platformService, idMapper, notifier),
+		handlers.NewCacheHandler(platformService),
+		handlers.NewWebhookHandler(notifier),
+		handlers.NewDocsHandler(),
+		handlers.NewAdminHandler(maintenance),
+	)
+	return e, anotaAi, deliveryVip
+}
+
+// request executa uma requisição na API com o token informado e os pares de headers adicionais
+func request(e *echo.Echo, method, path, token, body string, headers ...string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestPlatformURLOverrideRequiresWriteScope(t *testing.T) {
+	sandbox := fakeplatform.NewAnotaAi()
+	t.Cleanup(sandbox.Close)
+
+	e, anotaAi, _ := newTestServer(t, func(cfg *config.Config) {
+		cfg.Auth.Tokens = []config.ScopedToken{{Token: "read-token", Scope: config.ScopeRead}}
+		cfg.Debug.AllowURLOverride = true
+		cfg.Debug.URLOverrideHosts = []string{"127.0.0.1"}
+		cfg.Debug.Sandbox = config.SandboxCredentials{AnotaAiEmail: "sandbox@example.com", AnotaAiPassword: "senha"}
+	})
+
+	rec := request(e, http.MethodGet, "/plataformas/anotaai/lojas/status", "read-token", "", handlers.HeaderPlatformBaseURL, sandbox.URL)
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("token read com %s: status %d, esperado %d (corpo: %s)", handlers.HeaderPlatformBaseURL, rec.Code, http.StatusForbidden, rec.Body)
+	}
+	if calls := sandbox.Calls(fakeplatform.RouteAnotaAiLogin); calls != 0 {
+		t.Errorf("logins na URL alternativa = %d, esperado 0 para token read", calls)
+	}
+
+	rec = request(e, http.MethodGet, "/plataformas/anotaai/lojas/status", "test-token", "", handlers.HeaderPlatformBaseURL, sandbox.URL)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("token write com %s: status %d, esperado %d (corpo: %s)", handlers.HeaderPlatformBaseURL, rec.Code, http.StatusOK, rec.Body)
+	}
+	if calls := sandbox.Calls(fakeplatform.RouteAnotaAiListPages); calls != 1 {
+		t.Errorf("listagens na URL alternativa = %d, esperada 1", calls)
+	}
+	if calls := anotaAi.Calls(fakeplatform.RouteAnotaAiListPages); calls != 0 {
+		t.Errorf("listagens na URL de produção = %d, esperado 0", calls)
+	}
+}
+
+func TestBulkDeadlineRespondsMultiStatus(t *testing.T) {
+	e, anotaAi, _ := newTestServer(t, func(cfg *config.Config) {
+		cfg.Bulk.Concurrency = 1
+		cfg.Bulk.Deadline = 100 * time.Millisecond
+	})
+	anotaAi.SetStoreResponse(fakeplatform.RouteAnotaAiActivate, "page-lenta", fakeplatform.Response{Status: http.StatusOK, Body: `{"success":true}`, Delay: 5 * time.Second})
+
+	rec := request(e, http.MethodPost, "/plataformas/anotaai/lojas/ativar", "test-token", `{"ids_lojas":["page-1","page-lenta","page-3"]}`)
+	if rec.Code != http.StatusMultiStatus {
+		t.Fatalf("status %d, esperado %d (corpo: %s)", rec.Code, http.StatusMultiStatus, rec.Body)
+	}
+
+	var resposta models.RespostaOperacaoMultiplasLojas
+	if err := json.Unmarshal(rec.Body.Bytes(), &resposta); err != nil {
+		t.Fatal(err)
+	}
+	want := []models.Status{models.StatusAtivo, models.StatusTempoEsgotado, models.StatusNaoProcessado}
+	for i, resultado := range resposta.Resultados {
+		if resultado.Status != want[i] {
+			t.Errorf("loja %s: status %q, esperado %q", resultado.IdLoja, resultado.Status, want[i])
+		}
+	}
+	if calls := anotaAi.Calls(fakeplatform.RouteAnotaAiActivate); calls != 2 {
+		t.Errorf("ativações na plataforma = %d, esperadas 2 (a loja não processada não é enviada)", calls)
+	}
+}
+
+func TestSetStatusRespectsRequestTimeout(t *testing.T) {
+	e, anotaAi, _ := newTestServer(t)
+	anotaAi.SetResponse(fakeplatform.RouteAnotaAiListPages, fakeplatform.JSON(http.StatusOK, fakeplatform.AnotaAiListPages(
+		fakeplatform.AnotaAiPage("page-1", "Loja 1", "12345678901", true),
+	)))
+	anotaAi.SetStoreResponse(fakeplatform.RouteAnotaAiBlock, "page-1", fakeplatform.Response{Status: http.StatusOK, Body: `{"success":true}`, Delay: 5 * time.Second})
+
+	inicio := time.Now()
+	rec := request(e, http.MethodPut, "/plataformas/anotaai/lojas/page-1/status", "test-token", `{"status":"bloqueado"}`,
+		middleware.HeaderRequestTimeout, "200ms")
+	if rec.Code != http.StatusGatewayTimeout {
+		t.Fatalf("status %d, esperado %d (corpo: %s)", rec.Code, http.StatusGatewayTimeout, rec.Body)
+	}
+	if duracao := time.Since(inicio); duracao > 2*time.Second {
+		t.Errorf("resposta em %s, esperada logo após o prazo de %s", duracao, "200ms")
+	}
+	if calls := anotaAi.Calls(fakeplatform.RouteAnotaAiBlock); calls != 1 {
+		t.Errorf("bloqueios na plataforma = %d, esperado 1", calls)
+	}
+}
+
+func TestWebhookTestRespectsRequestTimeout(t *testing.T) {
+	// Endpoint sempre falhando: sem prazo, as novas tentativas somariam 1s+2s+4s+8s+16s
+	destino := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	t.Cleanup(destino.Close)
+
+	e, _, _ := newTestServer(t, func(cfg *config.Config) {
+		cfg.Webhook.URL = destino.URL
+		cfg.Webhook.MaxRetries = 5
+		cfg.Webhook.Timeout = time.Second
+	})
+
+	inicio := time.Now()
+	rec := request(e, http.MethodPost, "/webhooks/test", "test-token", "", middleware.HeaderRequestTimeout, "300ms")
+	if rec.Code != http.StatusGatewayTimeout {
+		t.Fatalf("status %d, esperado %d (corpo: %s)", rec.Code, http.StatusGatewayTimeout, rec.Body)
+	}
+	if duracao := time.Since(inicio); duracao > 2*time.Second {
+		t.Errorf("resposta em %s, esperada logo após o prazo de %s", duracao, "300ms")
+	}
+}
+
+func TestWaitTimeoutBoundsPendingPoll(t *testing.T) {
+	e, anotaAi, _ := newTestServer(t)
+	lenta := fakeplatform.JSON(http.StatusOK, fakeplatform.AnotaAiListPages(
+		fakeplatform.AnotaAiPage("page-1", "Loja 1", "12345678901", true),
+	))
+	lenta.Delay = time.Second
+	anotaAi.SetResponse(fakeplatform.RouteAnotaAiListPages, lenta)
+
+	// A primeira consulta termina em ~1s e a segunda começa em ~3s; o timeout de 3,5s termina
+	// durante a segunda, que é abandonada em favor do status da primeira
+	inicio := time.Now()
+	rec := request(e, http.MethodGet, "/plataformas/anotaai/lojas/page-1/status?aguardar=bloqueado&timeout=3500ms", "test-token", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d, esperado %d (corpo: %s)", rec.Code, http.StatusOK, rec.Body)
+	}
+	if duracao := time.Since(inicio); duracao > 3900*time.Millisecond {
+		t.Errorf("resposta em %s, esperada ao fim do timeout de 3,5s", duracao)
+	}
+
+	resposta := decodeObject(t, rec.Body.Bytes())
+	if resposta["tentativas"] != float64(1) || resposta["status_alcancado"] != false {
+		t.Errorf("tentativas=%v status_alcancado=%v, esperado 1 e false", resposta["tentativas"], resposta["status_alcancado"])
+	}
+}
+
+func TestRecentOperationsListActivationsAndStatusUpdates(t *testing.T) {
+	e, anotaAi, _ := newTestServer(t, func(cfg *config.Config) { cfg.Debug.RecentOperations = 10 })
+	anotaAi.SetResponse(fakeplatform.RouteAnotaAiListPages, fakeplatform.JSON(http.StatusOK, fakeplatform.AnotaAiListPages(
+		fakeplatform.AnotaAiPage("page-2", "Loja 2", "12345678901", true),
+		fakeplatform.AnotaAiPage("page-3", "Loja 3", "12345678901", true),
+	)))
+
+	if rec := request(e, http.MethodPost, "/plataformas/anotaai/lojas/ativar", "test-token", `{"ids_lojas":["page-1"]}`); rec.Code != http.StatusOK {
+		t.Fatalf("ativação: status %d (corpo: %s)", rec.Code, rec.Body)
+	}
+	if rec := request(e, http.MethodPut, "/plataformas/anotaai/lojas/page-2/status", "test-token", `{"status":"bloqueado","motivo":"teste"}`); rec.Code != http.StatusOK {
+		t.Fatalf("PUT status bloqueado: status %d (corpo: %s)", rec.Code, rec.Body)
+	}
+	if rec := request(e, http.MethodPut, "/plataformas/anotaai/lojas/page-3/status", "test-token", `{"status":"ativo"}`); rec.Code != http.StatusOK {
+		t.Fatalf("PUT status ativo: status %d (corpo: %s)", rec.Code, rec.Body)
+	}
+
+	rec := request(e, http.MethodGet, "/operacoes/recentes", "test-token", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("operações recentes: status %d (corpo: %s)", rec.Code, rec.Body)
+	}
+	var resposta models.RespostaOperacoesRecentes
+	if err := json.Unmarshal(rec.Body.Bytes(), &resposta); err != nil {
+		t.Fatal(err)
+	}
+
+	got := make(map[string]models.Operacao, len(resposta.Operacoes))
+	for _, registro := range resposta.Operacoes {
+		got[registro.IdLoja] = registro.Operacao
+	}
+	want := map[string]models.Operacao{
+		"page-1": models.OperacaoAtivar,
+		"page-2": models.OperacaoDesativar,
+		"page-3": models.OperacaoAtivar,
+	}
+	for id, operacao := range want {
+		if got[id] != operacao {
+			t.Errorf("operação recente da loja %s = %q, esperado %q", id, got[id], operacao)
+		}
+	}
+}
+
+func TestStatusCacheHeaders(t *testing.T) {
+	e, _, _ := newTestServer(t, func(cfg *config.Config) { cfg.Cache.StatusTTL = time.Minute })
+
+	for _, rec := range []*httptest.ResponseRecorder{
+		request(e, http.MethodGet, "/plataformas/anotaai/lojas/status", "test-token", ""),
+		request(e, http.MethodPost, "/plataformas/anotaai/lojas/status", "test-token", `{"ids_lojas":["page-1"]}`),
+	} {
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status %d (corpo: %s)", rec.Code, rec.Body)
+		}
+		if got := rec.Header().Get("X-Cache-Age"); got != "0" {
+			t.Errorf("X-Cache-Age = %q, esperado \"0\" para o catálogo recém-buscado", got)
+		}
+		if got := rec.Header().Get("Warning"); got != "" {
+			t.Errorf("Warning = %q, esperado vazio para o catálogo recém-buscado", got)
+		}
+	}
+}
+
+// firstStore extrai a primeira loja de cada resposta, para comparar os formatos entre as rotas
+var firstStore = map[string]func(t *testing.T, body []byte) map[string]any{
+	"objeto": func(t *testing.T, body []byte) map[string]any {
+		return decodeObject(t, body)
+	},
+	"lojas": func(t *testing.T, body []byte) map[string]any {
+		return decodeObject(t, body)["lojas"].([]any)[0].(map[string]any)
+	},
+	"loja": func(t *testing.T, body []byte) map[string]any {
+		return decodeObject(t, body)["loja"].(map[string]any)
+	},
+	"ndjson": func(t *testing.T, body []byte) map[string]any {
+		linha, _, _ := strings.Cut(string(body), "\n")
+		return decodeObject(t, []byte(linha))
+	},
+	"duplicadas": func(t *testing.T, body []byte) map[string]any {
+		documento := decodeObject(t, body)["duplicadas"].([]any)[0].(map[string]any)
+		return documento["lojas"].([]any)[0].(map[string]any)
+	},
+	"resultado": func(t *testing.T, body []byte) map[string]any {
+		return decodeObject(t, body)["resultados"].([]any)[0].(map[string]any)
+	},
+}
+
+// decodeObject decodifica um objeto JSON da resposta
+func decodeObject(t *testing.T, body []byte) map[string]any {
+	t.Helper()
+	var objeto map[string]any
+	if err := json.Unmarshal(body, &objeto); err != nil {
+		t.Fatalf("resposta inválida: %v (corpo: %s)", err, body)
+	}
+	return objeto
+}
+
+func TestStoreResponsesUseLojaByDefault(t *testing.T) {
+	e, anotaAi, deliveryVip := newTestServer(t)
+	anotaAi.SetResponse(fakeplatform.RouteAnotaAiListPages, fakeplatform.JSON(http.StatusOK, fakeplatform.AnotaAiListPages(
+		fakeplatform.AnotaAiPage("page-1", "Loja 1", "12345678901", true),
+	)))
+	deliveryVip.SetResponse(fakeplatform.RouteDeliveryVipMerchants, fakeplatform.JSON(http.StatusOK, []any{
+		fakeplatform.DeliveryVipMerchant("merchant-1", "Loja 1", "12345678901", "ACTIVATED", false),
+	}))
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		loja   string
+	}{
+		{name: "listagem", method: http.MethodGet, path: "/plataformas/anotaai/lojas/status", loja: "lojas"},
+		{name: "loja", method: http.MethodGet, path: "/plataformas/anotaai/lojas/page-1/status", loja: "loja"},
+		{name: "sincronização", method: http.MethodPost, path: "/plataformas/anotaai/lojas/page-1/sincronizar", loja: "loja"},
+		{name: "ndjson", method: http.MethodPost, path: "/plataformas/anotaai/lojas/status", body: `{"ids_lojas":["page-1"]}`, loja: "ndjson"},
+		{name: "duplicadas", method: http.MethodGet, path: "/lojas/duplicadas", loja: "duplicadas"},
+		{name: "lote", method: http.MethodPost, path: "/plataformas/anotaai/lojas/ativar", body: `{"ids_lojas":["page-1"]}`, loja: "resultado"},
+		{name: "definição de status", method: http.MethodPut, path: "/plataformas/anotaai/lojas/page-1/status", body: `{"status":"ativo"}`, loja: "objeto"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := request(e, tt.method, tt.path, "test-token", tt.body)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status %d (corpo: %s)", rec.Code, rec.Body)
+			}
+			loja := firstStore[tt.loja](t, rec.Body.Bytes())
+			// Os resultados de operação trazem a loja em "loja"
+			if dto, ok := loja["loja"].(map[string]any); ok {
+				loja = dto
+			}
+			if loja["id"] != "page-1" || loja["is_active"] != true {
+				t.Errorf("loja = %v, esperado o DTO Loja com is_active sem ?verbose=true", loja)
+			}
+			if _, ok := loja["detalhes"]; ok {
+				t.Errorf("loja = %v, detalhes não deveriam ser retornados sem ?verbose=true", loja)
+			}
+
+			separador := "?"
+			if strings.Contains(tt.path, "?") {
+				separador = "&"
+			}
+			rec = request(e, tt.method, tt.path+separador+"formato=legado", "test-token", tt.body)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("formato=legado: status %d (corpo: %s)", rec.Code, rec.Body)
+			}
+			legado := firstStore[tt.loja](t, rec.Body.Bytes())
+			if _, ok := legado["loja"]; ok {
+				t.Errorf("formato=legado: %v, o resultado não deveria trazer a loja", legado)
+			}
+			if _, ok := legado["id"]; ok || legado["id_loja"] != "page-1" {
+				t.Errorf("formato=legado: loja = %v, esperado o formato anterior com id_loja", legado)
+			}
+		})
+	}
+}
+
+func TestStatusEventStreamUsesLojaByDefault(t *testing.T) {
+	e, anotaAi, _ := newTestServer(t)
+	anotaAi.SetResponse(fakeplatform.RouteAnotaAiListPages, fakeplatform.JSON(http.StatusOK, fakeplatform.AnotaAiListPages(
+		fakeplatform.AnotaAiPage("page-1", "Loja 1", "12345678901", true),
+	)))
+
+	for formato, campo := range map[string]string{"": "id", "legado": "id_loja"} {
+		// O stream só termina quando o cliente desconecta: o snapshot é enviado antes do prazo
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		req := httptest.NewRequest(http.MethodGet, "/plataformas/anotaai/lojas/status/stream?formato="+formato, nil).WithContext(ctx)
+		req.Header.Set(echo.HeaderAuthorization, "Bearer test-token")
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		cancel()
+
+		_, data, ok := strings.Cut(rec.Body.String(), "event: snapshot\ndata: ")
+		if !ok {
+			t.Fatalf("formato=%q: snapshot não enviado (corpo: %s)", formato, rec.Body)
+		}
+		data, _, _ = strings.Cut(data, "\n")
+		loja := firstStore["lojas"](t, []byte(data))
+		if loja[campo] != "page-1" {
+			t.Errorf("formato=%q: loja do snapshot = %v, esperado o campo %s", formato, loja, campo)
+		}
+	}
+}
+
+func TestFormattedDocumentKeepsVersion(t *testing.T) {
+	e, anotaAi, _ := newTestServer(t)
+	anotaAi.SetResponse(fakeplatform.RouteAnotaAiListPages, fakeplatform.JSON(http.StatusOK, fakeplatform.AnotaAiListPages(
+		fakeplatform.AnotaAiPage("page-1", "Loja 1", "12345678000190", true),
+	)))
+
+	tests := []struct {
+		name string
+		path string
+		loja string
+	}{
+		{name: "loja", path: "/plataformas/anotaai/lojas/page-1/status", loja: "loja"},
+		{name: "listagem", path: "/plataformas/anotaai/lojas/status", loja: "lojas"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limpo := request(e, http.MethodGet, tt.path, "test-token", "")
+			formatado := request(e, http.MethodGet, tt.path+"?formato_documento=formatado", "test-token", "")
+			if limpo.Code != http.StatusOK || formatado.Code != http.StatusOK {
+				t.Fatalf("status %d e %d (corpos: %s / %s)", limpo.Code, formatado.Code, limpo.Body, formatado.Body)
+			}
+
+			lojaLimpa := firstStore[tt.loja](t, limpo.Body.Bytes())
+			lojaFormatada := firstStore[tt.loja](t, formatado.Body.Bytes())
+			if lojaLimpa["documento"] != "12345678000190" || lojaFormatada["documento"] != "12.345.678/0001-90" {
+				t.Fatalf("documentos = %v / %v, esperado o CNPJ limpo e formatado", lojaLimpa["documento"], lojaFormatada["documento"])
+			}
+			if lojaLimpa["versao"] == nil || lojaLimpa["versao"] != lojaFormatada["versao"] {
+				t.Errorf("versao = %v / %v, esperada a mesma versão nos dois formatos", lojaLimpa["versao"], lojaFormatada["versao"])
+			}
+			if etag := limpo.Header().Get("ETag"); etag != formatado.Header().Get("ETag") {
+				t.Errorf("ETag = %q / %q, esperado o mesmo valor nos dois formatos", etag, formatado.Header().Get("ETag"))
+			}
+		})
+	}
+}
+
+func TestPlatformPathIsCanonicalized(t *testing.T) {
+	e, anotaAi, _ := newTestServer(t)
+	anotaAi.SetResponse(fakeplatform.RouteAnotaAiListPages, fakeplatform.JSON(http.StatusOK, fakeplatform.AnotaAiListPages(
+		fakeplatform.AnotaAiPage("page-1", "Loja 1", "12345678901", true),
+	)))
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		want   string
+	}{
+		{name: "consulta", method: http.MethodGet, path: "/plataformas/AnotaAi/lojas/status", want: "anotaai"},
+		{name: "loja", method: http.MethodGet, path: "/plataformas/ANOTAAI/lojas/page-1/status", want: "anotaai"},
+		{name: "lote", method: http.MethodPost, path: "/plataformas/DeliveryVip/lojas/ativar", body: `{"ids_lojas":["merchant-1"]}`, want: "deliveryvip"},
+		{name: "garantir", method: http.MethodPost, path: "/plataformas/AnotaAi/lojas/garantir-ativas", body: `{"ids_lojas":["page-1"]}`, want: "anotaai"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := request(e, tt.method, tt.path, "test-token", tt.body)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status %d (corpo: %s)", rec.Code, rec.Body)
+			}
+			if !strings.Contains(rec.Body.String(), `"plataforma":"`+tt.want+`"`) {
+				t.Errorf("corpo = %s, esperado \"plataforma\":%q", rec.Body, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateBulkAgreesWithBulkOperation(t *testing.T) {
+	e, _, _ := newTestServer(t)
+
+	tests := []struct {
+		name       string
+		query      string
+		body       string
+		wantStatus int
+		wantValido bool
+		wantIDs    []string
+	}{
+		{
+			name:       "ids_lojas",
+			body:       `{"ids_lojas":["page-1","page-2"]}`,
+			wantStatus: http.StatusOK,
+			wantValido: true,
+			wantIDs:    []string{"page-1", "page-2"},
+		},
+		{
+			name:       "grupos de prioridade",
+			body:       `{"prioridade_normal":["page-2"],"prioridade_alta":["page-1"]}`,
+			wantStatus: http.StatusOK,
+			wantValido: true,
+			wantIDs:    []string{"page-1", "page-2"},
+		},
+		{
+			name:       "ids_lojas e grupos de prioridade",
+			body:       `{"ids_lojas":["page-1"],"prioridade_alta":["page-2"]}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "ID duplicado",
+			body:       `{"ids_lojas":["page-1","page-1"]}`,
+			wantStatus: http.StatusOK,
+			wantIDs:    []string{"page-1"},
+		},
+		{
+			name:       "ID longo demais",
+			body:       `{"ids_lojas":["page-1","` + strings.Repeat("a", 129) + `"]}`,
+			wantStatus: http.StatusOK,
+			wantIDs:    []string{"page-1"},
+		},
+		{
+			name:       "query param ids",
+			query:      "?ids=page-1,%20page-2,",
+			wantStatus: http.StatusOK,
+			wantValido: true,
+			wantIDs:    []string{"page-1", "page-2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			validacao := request(e, http.MethodPost, "/plataformas/anotaai/lojas/validar"+tt.query, "test-token", tt.body)
+			if validacao.Code != tt.wantStatus {
+				t.Fatalf("validar: status %d, esperado %d (corpo: %s)", validacao.Code, tt.wantStatus, validacao.Body)
+			}
+			ativacao := request(e, http.MethodPost, "/plataformas/anotaai/lojas/ativar"+tt.query, "test-token", tt.body)
+			if wantAtivacao := map[bool]int{true: http.StatusOK, false: http.StatusBadRequest}[tt.wantValido]; ativacao.Code != wantAtivacao {
+				t.Errorf("ativar: status %d, esperado %d (corpo: %s)", ativacao.Code, wantAtivacao, ativacao.Body)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var resposta models.RespostaValidacaoLote
+			if err := json.Unmarshal(validacao.Body.Bytes(), &resposta); err != nil {
+				t.Fatal(err)
+			}
+			if resposta.Valido != tt.wantValido || strings.Join(resposta.IdsLojas, ",") != strings.Join(tt.wantIDs, ",") {
+				t.Errorf("valido=%v ids=%v avisos=%v, esperado valido=%v ids=%v", resposta.Valido, resposta.IdsLojas, resposta.Avisos, tt.wantValido, tt.wantIDs)
+			}
+		})
+	}
+}
@@ -1,7 +1,14 @@
 package config
 
 import (
+	"fmt"
+	"net/http"
+	"net/url"
 	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 )
 
 // Config contém toda a configuração da aplicação
@@ -9,60 +16,805 @@ type Config struct {
 	Server    ServerConfig
 	Auth      AuthConfig
 	Platforms PlatformConfig
+	Log       LogConfig
+	Cache     CacheConfig
+	Bulk      BulkConfig
+	Docs      DocsConfig
+	Debug     DebugConfig
+	Webhook   WebhookConfig
+	Storage   StorageConfig
+	IDMapPath string
+
+	// invalidDurations são as variáveis de duração que não puderam ser interpretadas, rejeitadas em Validate
+	invalidDurations []string
 }
 
 // ServerConfig contém a configuração do servidor
 type ServerConfig struct {
 	Port string
+	// MaxHeaderIDs é a quantidade máxima de IDs no header X-Lojas-IDs (0 sem limite)
+	MaxHeaderIDs int
+	// StrictInput rejeita as consultas que informam IDs no header X-Lojas-IDs e no body ao mesmo tempo
+	StrictInput bool
+	// FanoutConcurrency limita quantas plataformas são consultadas em paralelo nos endpoints que
+	// consultam todas as plataformas (0 sem limite)
+	FanoutConcurrency int
+	// Gzip comprime as respostas dos clientes que aceitam gzip
+	Gzip bool
+	// GzipMinLength é o tamanho mínimo, em bytes, das respostas comprimidas
+	GzipMinLength int
+	// MaxRequestTimeout limita o prazo que o cliente pode pedir no header X-Request-Timeout
+	MaxRequestTimeout time.Duration
+	// Maintenance inicia a API em modo de manutenção, com as ativações e desativações suspensas
+	Maintenance bool
 }
 
+// Escopos dos tokens de acesso. O escopo write inclui o read
+const (
+	ScopeRead  = "read"
+	ScopeWrite = "write"
+)
+
+// Comportamentos da autenticação quando a configuração dos tokens é inválida
+const (
+	// AuthFailClosed rejeita todas as requisições com 401
+	AuthFailClosed = "closed"
+	// AuthFailMaintenance responde 503, indicando manutenção, em vez de 401
+	AuthFailMaintenance = "maintenance"
+)
+
 // AuthConfig contém a configuração de autenticação
 type AuthConfig struct {
+	// BearerToken tem acesso completo (escopo write)
 	BearerToken string
+	// Tokens são os tokens adicionais com escopo, configurados em AUTH_TOKENS
+	Tokens []ScopedToken
+	// FailMode define a resposta das rotas protegidas quando os tokens configurados são inválidos
+	// (AuthFailClosed ou AuthFailMaintenance)
+	FailMode string
+	// MetricsToken é aceito apenas em /metrics, para que o coletor do Prometheus não precise de um
+	// token da API (vazio exige um token da API)
+	MetricsToken string
+}
+
+// Err verifica a configuração dos tokens, retornando o motivo de ela não poder ser usada para
+// autenticar as requisições
+func (c AuthConfig) Err() error {
+	if c.BearerToken == "" && len(c.Tokens) == 0 {
+		return fmt.Errorf("a variável de ambiente BEARER_TOKEN (ou AUTH_TOKENS) é obrigatória")
+	}
+	for i, scoped := range c.Tokens {
+		if scoped.Token == "" {
+			return fmt.Errorf("token vazio na posição %d de AUTH_TOKENS", i+1)
+		}
+		if scoped.Scope != ScopeRead && scoped.Scope != ScopeWrite {
+			return fmt.Errorf("escopo inválido na posição %d de AUTH_TOKENS: use \"token:%s\" ou \"token:%s\"", i+1, ScopeRead, ScopeWrite)
+		}
+	}
+	return nil
+}
+
+// ScopedToken associa um token de acesso ao seu escopo
+type ScopedToken struct {
+	Token string
+	Scope string
+}
+
+// ScopeFor retorna o escopo do token e se ele é um token válido
+func (c AuthConfig) ScopeFor(token string) (string, bool) {
+	if c.BearerToken != "" && token == c.BearerToken {
+		return ScopeWrite, true
+	}
+	for _, scoped := range c.Tokens {
+		if token == scoped.Token {
+			return scoped.Scope, true
+		}
+	}
+	return "", false
+}
+
+// LogConfig contém a configuração do destino e da rotação dos logs
+type LogConfig struct {
+	File       string
+	MaxSizeMB  int
+	MaxBackups int
+	MaxAgeDays int
+	Compress   bool
+	// SampleRate é a fração (entre 0 e 1) das consultas bem-sucedidas registradas no log de
+	// requisições; alterações e erros são sempre registrados
+	SampleRate float64
+}
+
+// CacheConfig contém a configuração do cache de status das lojas
+type CacheConfig struct {
+	// StatusTTL é o tempo que o catálogo de cada plataforma permanece em cache (0 desabilita)
+	StatusTTL time.Duration
+	// WarmupOnStart pré-carrega o catálogo de cada plataforma ao iniciar
+	WarmupOnStart bool
+	// StreamInterval é o intervalo entre as consultas de status enviadas por Server-Sent Events
+	StreamInterval time.Duration
+	// StreamMinInterval e StreamMaxInterval limitam o intervalo escolhido pelo cliente em ?intervalo=
+	StreamMinInterval time.Duration
+	StreamMaxInterval time.Duration
+}
+
+// BulkConfig contém a configuração das operações em lote
+type BulkConfig struct {
+	// Concurrency é a quantidade de lojas processadas em paralelo (o ponto de partida com Adaptive)
+	Concurrency int
+	// Adaptive ajusta a concorrência pela latência e pelas falhas observadas na plataforma
+	Adaptive bool
+	// MaxConcurrency é o limite superior da concorrência adaptativa
+	MaxConcurrency int
+	// LatencyTarget é a latência por loja acima da qual a concorrência adaptativa é reduzida
+	LatencyTarget time.Duration
+	// MaxIDs é a quantidade máxima de lojas por requisição em lote (0 sem limite)
+	MaxIDs int
+	// MaxRetries é a quantidade de novas tentativas por loja em falhas transitórias (0 desabilita)
+	MaxRetries int
+	// RetryBudget é a fração das lojas do lote que define o total de novas tentativas do lote
+	RetryBudget float64
+	// RetryDelay é o intervalo antes da primeira nova tentativa, dobrado a cada falha
+	RetryDelay time.Duration
+	// StoreTimeout é o tempo máximo de processamento de cada loja, incluindo as novas tentativas (0 desabilita)
+	StoreTimeout time.Duration
+	// Deadline é o prazo do lote inteiro; as lojas não despachadas até lá não são processadas (0 desabilita)
+	Deadline time.Duration
+	// ConfirmThreshold é a quantidade de lojas acima da qual o lote exige o header X-Confirm-Bulk (0 desabilita)
+	ConfirmThreshold int
+}
+
+// DocsConfig contém a configuração da documentação da API
+type DocsConfig struct {
+	// Enabled registra as rotas /docs e /docs/openapi.yml
+	Enabled bool
+}
+
+// DebugConfig contém a configuração dos recursos de diagnóstico
+type DebugConfig struct {
+	// Endpoints registra as rotas de diagnóstico, que expõem dados brutos das plataformas
+	Endpoints bool
+	// AllowURLOverride aceita o header X-Platform-Base-URL, que direciona a requisição para outra
+	// URL da plataforma (ex.: sandbox). Nunca deve ser habilitado em produção
+	AllowURLOverride bool
+	// URLOverrideHosts são os hosts aceitos em X-Platform-Base-URL ("host" em qualquer porta ou
+	// "host:porta"), obrigatórios com AllowURLOverride
+	URLOverrideHosts []string
+	// Sandbox são as credenciais usadas nas URLs de X-Platform-Base-URL. As credenciais de produção
+	// nunca são enviadas a essas URLs
+	Sandbox SandboxCredentials
+	// RecentOperations é a quantidade de operações mantidas em memória para GET /operacoes/recentes
+	// (0 não guarda nenhuma)
+	RecentOperations int
+}
+
+// SandboxCredentials contém as credenciais das plataformas usadas apenas com X-Platform-Base-URL.
+// Uma plataforma sem credenciais de sandbox não aceita a substituição da URL
+type SandboxCredentials struct {
+	AnotaAiEmail            string
+	AnotaAiPassword         string
+	DeliveryVipClientID     string
+	DeliveryVipClientSecret string
+}
+
+// HasAnotaAi indica se as credenciais de sandbox do AnotaAI estão configuradas
+func (s SandboxCredentials) HasAnotaAi() bool {
+	return s.AnotaAiEmail != "" && s.AnotaAiPassword != ""
+}
+
+// HasDeliveryVip indica se as credenciais de sandbox do DeliveryVip estão configuradas
+func (s SandboxCredentials) HasDeliveryVip() bool {
+	return s.DeliveryVipClientID != "" && s.DeliveryVipClientSecret != ""
+}
+
+// IsURLOverrideHostAllowed verifica se o host da URL está em URL_OVERRIDE_ALLOWED_HOSTS, sem
+// distinção de maiúsculas. Itens sem porta aceitam o host em qualquer porta
+func (c DebugConfig) IsURLOverrideHostAllowed(rawURL string) bool {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	for _, allowed := range c.URLOverrideHosts {
+		if strings.EqualFold(allowed, parsed.Host) || strings.EqualFold(allowed, parsed.Hostname()) {
+			return true
+		}
+	}
+	return false
+}
+
+// Modos de envio de webhooks
+const (
+	WebhookModoLoja  = "loja"
+	WebhookModoLote  = "lote"
+	WebhookModoAmbos = "ambos"
+)
+
+// WebhookConfig contém a configuração das notificações enviadas após as operações em lote
+type WebhookConfig struct {
+	// URL recebe as notificações (vazia desabilita os webhooks)
+	URL string
+	// Secret é a chave do HMAC enviado em X-Webhook-Assinatura (vazia não assina)
+	Secret string
+	// Mode define se são enviados eventos por loja, por lote ou ambos
+	Mode       string
+	MaxRetries int
+	Timeout    time.Duration
+	// QueueSize é a quantidade de eventos aguardando entrega; com a fila cheia, novos eventos são descartados
+	QueueSize int
+	// Workers é a quantidade de entregas simultâneas
+	Workers int
+}
+
+// Backends de armazenamento suportados
+const (
+	StorageMemoria = "memoria"
+)
+
+// StorageConfig contém a configuração dos repositórios de auditoria, lotes e histórico
+type StorageConfig struct {
+	// Backend escolhe a implementação dos repositórios (por enquanto apenas "memoria")
+	Backend string
+}
+
+// Nomes das plataformas integradas, usados em PLATFORMS_ENABLED
+const (
+	PlataformaAnotaAi     = "anotaai"
+	PlataformaDeliveryVip = "deliveryvip"
+)
+
+// KnownPlatforms são as plataformas integradas, habilitadas por padrão
+var KnownPlatforms = []string{PlataformaAnotaAi, PlataformaDeliveryVip}
+
+// PlatformInfo registra os padrões de uma plataforma integrada que podem ser substituídos por
+// variáveis de ambiente
+type PlatformInfo struct {
+	// TokenHeaderEnv é a variável de ambiente que substitui TokenHeader, no formato "Nome" (token
+	// puro) ou "Nome: Prefixo" (ex.: "Authorization: Bearer")
+	TokenHeaderEnv string
+	// TokenHeader é o header em que a plataforma espera o token quando TokenHeaderEnv não está definida
+	TokenHeader TokenHeader
+}
+
+// PlatformRegistry contém o registro de cada plataforma de KnownPlatforms
+var PlatformRegistry = map[string]PlatformInfo{
+	PlataformaAnotaAi: {
+		TokenHeaderEnv: "ANOTAAI_TOKEN_HEADER",
+		TokenHeader:    TokenHeader{Name: "authorization"},
+	},
+	PlataformaDeliveryVip: {
+		TokenHeaderEnv: "DELIVERYVIP_TOKEN_HEADER",
+		TokenHeader:    TokenHeader{Name: "Authorization", Prefix: "Bearer"},
+	},
 }
 
 // PlatformConfig contém as URLs das plataformas para implementação futura
 type PlatformConfig struct {
+	// Enabled são as plataformas habilitadas (PLATFORMS_ENABLED). As demais não são validadas na
+	// inicialização, não fazem login e respondem como plataformas não suportadas
+	Enabled        []string
 	AnotaAiURL     string
 	DeliveryVipURL string
-	AnotaAi        AnotaAiConfig
-	DeliveryVip    DeliveryVipConfig
+	// TokenWaitTimeout é quanto uma requisição aguarda o primeiro token da plataforma antes de
+	// falhar com 503 (0 faz o login na hora, dentro da requisição)
+	TokenWaitTimeout time.Duration
+	AnotaAi          AnotaAiConfig
+	DeliveryVip      DeliveryVipConfig
+}
+
+// IsEnabled verifica se a plataforma está habilitada em PLATFORMS_ENABLED
+func (c PlatformConfig) IsEnabled(plataforma string) bool {
+	return slices.Contains(c.Enabled, plataforma)
 }
 
 // AnotaAiConfig contém as configurações específicas do AnotaAI
 type AnotaAiConfig struct {
+	Email        string
+	Password     string
+	TokenRenewal time.Duration
+	// LoginTimeout limita a requisição de login, menor que o timeout geral para falhar rápido
+	LoginTimeout time.Duration
+	// StatusTimeout limita cada requisição de listagem de páginas (status e ping)
+	StatusTimeout time.Duration
+	// MutationTimeout limita cada requisição de ativação/desativação, para falhar rápido
+	MutationTimeout time.Duration
+	// ExtraHeaders são enviados em todas as requisições ao AnotaAI
+	ExtraHeaders map[string]string
+	// Accounts são as contas de parceiro adicionais, além da conta padrão (Email/Password)
+	Accounts []AnotaAiAccount
+	// ProtectedStoreIDs são as lojas (IDs do AnotaAI) que nunca são desativadas por esta API
+	ProtectedStoreIDs []string
+	// Responses define como as respostas de ativação/desativação são interpretadas
+	Responses ResponseSemantics
+	// TokenHeader define como o token de acesso é enviado nas requisições
+	TokenHeader TokenHeader
+	// IncompleteStatus é o status reportado para as páginas ativas sem os dados do estabelecimento
+	// (AnotaAiIncompletoStatus ou AnotaAiIncompletoBloqueado)
+	IncompleteStatus string
+}
+
+// Status reportados para as páginas do AnotaAI sem os dados do estabelecimento (cadastro incompleto)
+const (
+	// AnotaAiIncompletoStatus reporta essas páginas com o status próprio "incompleto"
+	AnotaAiIncompletoStatus = "incompleto"
+	// AnotaAiIncompletoBloqueado mantém o comportamento anterior, reportando-as como "bloqueado"
+	AnotaAiIncompletoBloqueado = "bloqueado"
+)
+
+// AnotaAiAccount contém as credenciais de uma conta de parceiro do AnotaAI
+type AnotaAiAccount struct {
+	Name     string
 	Email    string
 	Password string
 }
 
+// AnotaAiDefaultAccount é o nome da conta configurada por ANOTAAI_EMAIL e ANOTAAI_PASSWORD
+const AnotaAiDefaultAccount = "padrao"
+
+// AllAccounts retorna a conta padrão seguida das contas adicionais
+func (c AnotaAiConfig) AllAccounts() []AnotaAiAccount {
+	accounts := []AnotaAiAccount{{Name: AnotaAiDefaultAccount, Email: c.Email, Password: c.Password}}
+	return append(accounts, c.Accounts...)
+}
+
 // DeliveryVipConfig contém as configurações específicas do DeliveryVip
 type DeliveryVipConfig struct {
 	ClientID     string
 	ClientSecret string
+	TokenRenewal time.Duration
+	// LoginTimeout limita a requisição de token OAuth
+	LoginTimeout time.Duration
+	// StatusTimeout limita cada requisição de listagem de merchants (status e ping)
+	StatusTimeout time.Duration
+	// MutationTimeout limita cada requisição de block/unblock, para falhar rápido
+	MutationTimeout time.Duration
+	// ExtraHeaders são enviados em todas as requisições ao DeliveryVip
+	ExtraHeaders map[string]string
+	// ProtectedStoreIDs são as lojas (IDs do DeliveryVip) que nunca são desativadas por esta API
+	ProtectedStoreIDs []string
+	// Responses define como as respostas de block/unblock são interpretadas
+	Responses ResponseSemantics
+	// TokenHeader define como o token de acesso é enviado nas requisições
+	TokenHeader TokenHeader
+}
+
+// ResponseSemantics declara como uma plataforma indica sucesso e erro nas respostas de
+// ativação/desativação. Os caminhos JSON usam "." para campos aninhados (ex.: "data.success")
+type ResponseSemantics struct {
+	// SuccessStatuses são os status HTTP aceitos como sucesso (vazio aceita qualquer 2xx)
+	SuccessStatuses []int
+	// SuccessField é o campo booleano do corpo que precisa ser true para confirmar o sucesso
+	// (vazio não verifica o corpo). Respostas sem corpo não são verificadas
+	SuccessField string
+	// ErrorFields são os campos do corpo com o código ou a mensagem de erro, em ordem de preferência
+	ErrorFields []string
+}
+
+// IsSuccessStatus indica se o status HTTP é uma resposta de sucesso da plataforma
+func (r ResponseSemantics) IsSuccessStatus(statusCode int) bool {
+	if len(r.SuccessStatuses) == 0 {
+		return statusCode >= 200 && statusCode <= 299
+	}
+	return slices.Contains(r.SuccessStatuses, statusCode)
+}
+
+// AnotaAiResponses é a semântica das respostas do AnotaAI: qualquer 2xx com success:true no corpo
+// e a mensagem de erro em "mensagem"
+func AnotaAiResponses() ResponseSemantics {
+	return ResponseSemantics{
+		SuccessField: "success",
+		ErrorFields:  []string{"mensagem"},
+	}
+}
+
+// DeliveryVipResponses é a semântica das respostas do DeliveryVip: o block/unblock responde 202
+// (processamento assíncrono), mas 200 e 204 também são aceitos como conclusão síncrona. O corpo não
+// tem flag de sucesso e o código de erro vem em "code" ou "errorCode"
+func DeliveryVipResponses() ResponseSemantics {
+	return ResponseSemantics{
+		SuccessStatuses: []int{http.StatusOK, http.StatusAccepted, http.StatusNoContent},
+		ErrorFields:     []string{"code", "errorCode"},
+	}
+}
+
+// TokenHeader declara o header em que uma plataforma espera o token de acesso e o prefixo do valor
+// (ex.: "Bearer"). Sem prefixo, o token é enviado puro
+type TokenHeader struct {
+	Name   string
+	Prefix string
+}
+
+// Value retorna o valor do header para o token informado
+func (t TokenHeader) Value(token string) string {
+	if t.Prefix == "" {
+		return token
+	}
+	return t.Prefix + " " + token
+}
+
+// AnotaAiTokenHeader é o header de token padrão do AnotaAI: "authorization" com o token puro
+func AnotaAiTokenHeader() TokenHeader {
+	return PlatformRegistry[PlataformaAnotaAi].TokenHeader
+}
+
+// DeliveryVipTokenHeader é o header de token padrão do DeliveryVip: "Authorization: Bearer <token>"
+func DeliveryVipTokenHeader() TokenHeader {
+	return PlatformRegistry[PlataformaDeliveryVip].TokenHeader
+}
+
+// loadTokenHeader carrega o header de token da plataforma da variável TokenHeaderEnv do registro,
+// no formato "Nome" ou "Nome: Prefixo", usando o padrão do registro quando ela não está definida
+func loadTokenHeader(plataforma string) TokenHeader {
+	info := PlatformRegistry[plataforma]
+	value := strings.TrimSpace(getEnv(info.TokenHeaderEnv, ""))
+	if value == "" {
+		return info.TokenHeader
+	}
+	name, prefix, _ := strings.Cut(value, ":")
+	return TokenHeader{Name: strings.TrimSpace(name), Prefix: strings.TrimSpace(prefix)}
 }
 
 // Load carrega a configuração das variáveis de ambiente
 func Load() *Config {
-	return &Config{
+	durations := &durationEnv{}
+	cfg := &Config{
 		Server: ServerConfig{
-			Port: getEnv("PORT", "8080"),
+			Port:              getEnv("PORT", "8080"),
+			MaxHeaderIDs:      getEnvInt("HEADER_MAX_IDS", 200),
+			StrictInput:       getEnvBool("STRICT_INPUT", false),
+			FanoutConcurrency: getEnvInt("PLATFORM_FANOUT_CONCURRENCY", 4),
+			Gzip:              getEnvBool("GZIP_ENABLED", false),
+			GzipMinLength:     getEnvInt("GZIP_MIN_LENGTH", 1024),
+			MaxRequestTimeout: durations.get("REQUEST_TIMEOUT_MAX", time.Minute),
+			Maintenance:       getEnvBool("MAINTENANCE_MODE", false),
 		},
 		Auth: AuthConfig{
-			BearerToken: getEnv("BEARER_TOKEN", ""),
+			BearerToken:  getEnv("BEARER_TOKEN", ""),
+			Tokens:       loadScopedTokens(),
+			FailMode:     getEnv("AUTH_FAIL_MODE", AuthFailClosed),
+			MetricsToken: getEnv("METRICS_TOKEN", ""),
 		},
 		Platforms: PlatformConfig{
-			AnotaAiURL:     getEnv("ANOTAAI_API_URL", "https://integration-admin.api.anota.ai"),
-			DeliveryVipURL: getEnv("DELIVERYVIP_API_URL", "https://api.deliveryvip.com.br"),
+			Enabled:          loadEnabledPlatforms(),
+			AnotaAiURL:       getEnv("ANOTAAI_API_URL", "https://integration-admin.api.anota.ai"),
+			DeliveryVipURL:   getEnv("DELIVERYVIP_API_URL", "https://api.deliveryvip.com.br"),
+			TokenWaitTimeout: durations.get("TOKEN_WAIT_TIMEOUT", 0),
 			AnotaAi: AnotaAiConfig{
-				Email:    getEnv("ANOTAAI_EMAIL", ""),
-				Password: getEnv("ANOTAAI_PASSWORD", ""),
+				Email:             getEnv("ANOTAAI_EMAIL", ""),
+				Password:          getEnv("ANOTAAI_PASSWORD", ""),
+				TokenRenewal:      durations.get("ANOTAAI_TOKEN_RENEWAL", 3*time.Hour),
+				LoginTimeout:      durations.get("ANOTAAI_LOGIN_TIMEOUT", 10*time.Second),
+				StatusTimeout:     durations.get("ANOTAAI_STATUS_TIMEOUT", 30*time.Second),
+				MutationTimeout:   durations.get("ANOTAAI_MUTATION_TIMEOUT", 30*time.Second),
+				ExtraHeaders:      getEnvHeaders("ANOTAAI_EXTRA_HEADERS"),
+				Accounts:          loadAnotaAiAccounts(),
+				ProtectedStoreIDs: getEnvList("ANOTAAI_PROTECTED_STORE_IDS"),
+				IncompleteStatus:  getEnv("ANOTAAI_INCOMPLETE_STATUS", AnotaAiIncompletoStatus),
+				Responses:         AnotaAiResponses(),
+				TokenHeader:       loadTokenHeader(PlataformaAnotaAi),
 			},
 			DeliveryVip: DeliveryVipConfig{
-				ClientID:     getEnv("DELIVERYVIP_CLIENT_ID", ""),
-				ClientSecret: getEnv("DELIVERYVIP_CLIENT_SECRET", ""),
+				ClientID:          getEnv("DELIVERYVIP_CLIENT_ID", ""),
+				ClientSecret:      getEnv("DELIVERYVIP_CLIENT_SECRET", ""),
+				TokenRenewal:      durations.get("DELIVERYVIP_TOKEN_RENEWAL", 6*time.Hour),
+				LoginTimeout:      durations.get("DELIVERYVIP_LOGIN_TIMEOUT", 10*time.Second),
+				StatusTimeout:     durations.get("DELIVERYVIP_STATUS_TIMEOUT", 30*time.Second),
+				MutationTimeout:   durations.get("DELIVERYVIP_MUTATION_TIMEOUT", 30*time.Second),
+				ExtraHeaders:      getEnvHeaders("DELIVERYVIP_EXTRA_HEADERS"),
+				ProtectedStoreIDs: getEnvList("DELIVERYVIP_PROTECTED_STORE_IDS"),
+				Responses:         DeliveryVipResponses(),
+				TokenHeader:       loadTokenHeader(PlataformaDeliveryVip),
 			},
 		},
+		Log: LogConfig{
+			File:       getEnv("LOG_FILE", ""),
+			MaxSizeMB:  getEnvInt("LOG_MAX_SIZE_MB", 100),
+			MaxBackups: getEnvInt("LOG_MAX_BACKUPS", 5),
+			MaxAgeDays: getEnvInt("LOG_MAX_AGE_DAYS", 30),
+			Compress:   getEnvBool("LOG_COMPRESS", true),
+			SampleRate: getEnvFloat("LOG_SAMPLE_RATE", 1),
+		},
+		Cache: CacheConfig{
+			StatusTTL:         durations.get("STATUS_CACHE_TTL", 30*time.Second),
+			WarmupOnStart:     getEnvBool("WARMUP_ON_START", false),
+			StreamInterval:    durations.get("STATUS_STREAM_INTERVAL", 15*time.Second),
+			StreamMinInterval: durations.get("STATUS_STREAM_MIN_INTERVAL", 5*time.Second),
+			StreamMaxInterval: durations.get("STATUS_STREAM_MAX_INTERVAL", 5*time.Minute),
+		},
+		Bulk: BulkConfig{
+			Concurrency:      getEnvInt("BULK_CONCURRENCY", 5),
+			Adaptive:         getEnvBool("BULK_ADAPTIVE", false),
+			MaxConcurrency:   getEnvInt("BULK_MAX_CONCURRENCY", 20),
+			LatencyTarget:    durations.get("BULK_LATENCY_TARGET", 2*time.Second),
+			MaxIDs:           getEnvInt("BULK_MAX_IDS", 0),
+			MaxRetries:       getEnvInt("BULK_MAX_RETRIES", 2),
+			RetryBudget:      getEnvFloat("BULK_RETRY_BUDGET", 0.1),
+			RetryDelay:       durations.get("BULK_RETRY_DELAY", 500*time.Millisecond),
+			StoreTimeout:     durations.get("PER_STORE_TIMEOUT", 15*time.Second),
+			Deadline:         durations.get("BULK_DEADLINE", 5*time.Minute),
+			ConfirmThreshold: getEnvInt("BULK_CONFIRM_THRESHOLD", 0),
+		},
+		Docs: DocsConfig{
+			Enabled: getEnvBool("DOCS_ENABLED", true),
+		},
+		Debug: DebugConfig{
+			Endpoints:        getEnvBool("DEBUG_ENDPOINTS", false),
+			AllowURLOverride: getEnvBool("ALLOW_URL_OVERRIDE", false),
+			URLOverrideHosts: getEnvList("URL_OVERRIDE_ALLOWED_HOSTS"),
+			Sandbox: SandboxCredentials{
+				AnotaAiEmail:            getEnv("SANDBOX_ANOTAAI_EMAIL", ""),
+				AnotaAiPassword:         getEnv("SANDBOX_ANOTAAI_PASSWORD", ""),
+				DeliveryVipClientID:     getEnv("SANDBOX_DELIVERYVIP_CLIENT_ID", ""),
+				DeliveryVipClientSecret: getEnv("SANDBOX_DELIVERYVIP_CLIENT_SECRET", ""),
+			},
+			RecentOperations: getEnvInt("RECENT_OPERATIONS_SIZE", 200),
+		},
+		Webhook: WebhookConfig{
+			URL:        getEnv("WEBHOOK_URL", ""),
+			Secret:     getEnv("WEBHOOK_SECRET", ""),
+			Mode:       getEnv("WEBHOOK_MODE", WebhookModoLote),
+			MaxRetries: getEnvInt("WEBHOOK_MAX_RETRIES", 3),
+			Timeout:    durations.get("WEBHOOK_TIMEOUT", 10*time.Second),
+			QueueSize:  getEnvInt("WEBHOOK_QUEUE_SIZE", 1000),
+			Workers:    getEnvInt("WEBHOOK_WORKERS", 4),
+		},
+		Storage: StorageConfig{
+			Backend: getEnv("STORAGE_BACKEND", StorageMemoria),
+		},
+		IDMapPath: getEnv("ID_MAP_PATH", ""),
+	}
+	cfg.invalidDurations = durations.invalidas
+	return cfg
+}
+
+// loadScopedTokens carrega os tokens de AUTH_TOKENS, no formato "token:escopo" separados por
+// vírgula (ex.: "abc123:read,def456:write"). Itens sem escopo ficam com escopo vazio e são
+// rejeitados por Validate
+func loadScopedTokens() []ScopedToken {
+	var tokens []ScopedToken
+	for _, item := range strings.Split(getEnv("AUTH_TOKENS", ""), ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		token, scope, _ := strings.Cut(item, ":")
+		tokens = append(tokens, ScopedToken{Token: strings.TrimSpace(token), Scope: strings.TrimSpace(scope)})
 	}
+	return tokens
+}
+
+// loadEnabledPlatforms carrega as plataformas de PLATFORMS_ENABLED (nomes separados por vírgula,
+// sem distinção de maiúsculas). Sem a variável, todas as plataformas integradas ficam habilitadas
+func loadEnabledPlatforms() []string {
+	plataformas := getEnvList("PLATFORMS_ENABLED")
+	if len(plataformas) == 0 {
+		return slices.Clone(KnownPlatforms)
+	}
+	for i, plataforma := range plataformas {
+		plataformas[i] = strings.ToLower(plataforma)
+	}
+	return plataformas
+}
+
+// loadAnotaAiAccounts carrega as contas adicionais listadas em ANOTAAI_ACCOUNTS (nomes separados
+// por vírgula), com as credenciais em ANOTAAI_<NOME>_EMAIL e ANOTAAI_<NOME>_PASSWORD
+func loadAnotaAiAccounts() []AnotaAiAccount {
+	var accounts []AnotaAiAccount
+	for _, name := range strings.Split(getEnv("ANOTAAI_ACCOUNTS", ""), ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		prefix := "ANOTAAI_" + strings.ToUpper(name) + "_"
+		accounts = append(accounts, AnotaAiAccount{
+			Name:     name,
+			Email:    getEnv(prefix+"EMAIL", ""),
+			Password: getEnv(prefix+"PASSWORD", ""),
+		})
+	}
+	return accounts
+}
+
+// Validate verifica se a configuração obrigatória está presente e consistente
+func (c *Config) Validate() error {
+	switch c.Auth.FailMode {
+	case AuthFailClosed, AuthFailMaintenance:
+	default:
+		return fmt.Errorf("a variável de ambiente AUTH_FAIL_MODE deve ser %s ou %s (recebido: %q)", AuthFailClosed, AuthFailMaintenance, c.Auth.FailMode)
+	}
+	// Com AUTH_FAIL_MODE=maintenance, tokens inválidos não impedem a inicialização: as rotas
+	// protegidas respondem 503 até a configuração ser corrigida
+	if err := c.Auth.Err(); err != nil && c.Auth.FailMode != AuthFailMaintenance {
+		return err
+	}
+
+	if len(c.Platforms.Enabled) == 0 {
+		return fmt.Errorf("a variável de ambiente PLATFORMS_ENABLED deve listar ao menos uma plataforma")
+	}
+	for _, plataforma := range c.Platforms.Enabled {
+		if !slices.Contains(KnownPlatforms, plataforma) {
+			return fmt.Errorf("plataforma desconhecida em PLATFORMS_ENABLED: %q (válidas: %s)", plataforma, strings.Join(KnownPlatforms, ", "))
+		}
+	}
+
+	// Apenas as plataformas habilitadas precisam de uma URL válida
+	platformURLs := []struct {
+		plataforma string
+		envVar     string
+		value      string
+	}{
+		{PlataformaAnotaAi, "ANOTAAI_API_URL", c.Platforms.AnotaAiURL},
+		{PlataformaDeliveryVip, "DELIVERYVIP_API_URL", c.Platforms.DeliveryVipURL},
+	}
+	for _, platformURL := range platformURLs {
+		if c.Platforms.IsEnabled(platformURL.plataforma) && !IsValidPlatformURL(platformURL.value) {
+			return fmt.Errorf("a variável de ambiente %s deve conter uma URL absoluta válida (recebido: %q)", platformURL.envVar, platformURL.value)
+		}
+	}
+
+	tokenHeaders := map[string]TokenHeader{
+		PlataformaAnotaAi:     c.Platforms.AnotaAi.TokenHeader,
+		PlataformaDeliveryVip: c.Platforms.DeliveryVip.TokenHeader,
+	}
+	for _, plataforma := range KnownPlatforms {
+		name := tokenHeaders[plataforma].Name
+		if name == "" || strings.ContainsAny(name, " \t:") {
+			return fmt.Errorf("a variável de ambiente %s deve ter o formato \"Nome\" ou \"Nome: Prefixo\" (recebido: %q)", PlatformRegistry[plataforma].TokenHeaderEnv, name)
+		}
+	}
+
+	seen := make(map[string]bool)
+	for _, account := range c.Platforms.AnotaAi.AllAccounts() {
+		if seen[account.Name] {
+			return fmt.Errorf("conta do AnotaAI duplicada em ANOTAAI_ACCOUNTS: %s", account.Name)
+		}
+		seen[account.Name] = true
+
+		if account.Name != AnotaAiDefaultAccount && (account.Email == "" || account.Password == "") {
+			prefix := "ANOTAAI_" + strings.ToUpper(account.Name) + "_"
+			return fmt.Errorf("as variáveis de ambiente %sEMAIL e %sPASSWORD são obrigatórias para a conta %s do AnotaAI", prefix, prefix, account.Name)
+		}
+	}
+
+	switch c.Platforms.AnotaAi.IncompleteStatus {
+	case AnotaAiIncompletoStatus, AnotaAiIncompletoBloqueado:
+	default:
+		return fmt.Errorf("a variável de ambiente ANOTAAI_INCOMPLETE_STATUS deve ser %s ou %s (recebido: %q)",
+			AnotaAiIncompletoStatus, AnotaAiIncompletoBloqueado, c.Platforms.AnotaAi.IncompleteStatus)
+	}
+
+	if len(c.invalidDurations) > 0 {
+		key := c.invalidDurations[0]
+		return fmt.Errorf("a variável de ambiente %s deve ser uma duração como '30s' ou '3h' (recebido: %q)", key, os.Getenv(key))
+	}
+	if c.Platforms.AnotaAi.TokenRenewal <= 0 {
+		return fmt.Errorf("a variável de ambiente ANOTAAI_TOKEN_RENEWAL deve ser uma duração positiva")
+	}
+	if c.Platforms.DeliveryVip.TokenRenewal <= 0 {
+		return fmt.Errorf("a variável de ambiente DELIVERYVIP_TOKEN_RENEWAL deve ser uma duração positiva")
+	}
+	if c.Platforms.TokenWaitTimeout < 0 {
+		return fmt.Errorf("a variável de ambiente TOKEN_WAIT_TIMEOUT não pode ser negativa")
+	}
+	if c.Platforms.AnotaAi.LoginTimeout <= 0 {
+		return fmt.Errorf("a variável de ambiente ANOTAAI_LOGIN_TIMEOUT deve ser uma duração positiva")
+	}
+	if c.Platforms.DeliveryVip.LoginTimeout <= 0 {
+		return fmt.Errorf("a variável de ambiente DELIVERYVIP_LOGIN_TIMEOUT deve ser uma duração positiva")
+	}
+	platformTimeouts := []struct {
+		envVar string
+		value  time.Duration
+	}{
+		{"ANOTAAI_STATUS_TIMEOUT", c.Platforms.AnotaAi.StatusTimeout},
+		{"ANOTAAI_MUTATION_TIMEOUT", c.Platforms.AnotaAi.MutationTimeout},
+		{"DELIVERYVIP_STATUS_TIMEOUT", c.Platforms.DeliveryVip.StatusTimeout},
+		{"DELIVERYVIP_MUTATION_TIMEOUT", c.Platforms.DeliveryVip.MutationTimeout},
+	}
+	for _, timeout := range platformTimeouts {
+		if timeout.value <= 0 {
+			return fmt.Errorf("a variável de ambiente %s deve ser uma duração positiva", timeout.envVar)
+		}
+	}
+
+	if c.Cache.StreamInterval <= 0 {
+		return fmt.Errorf("a variável de ambiente STATUS_STREAM_INTERVAL deve ser uma duração positiva")
+	}
+	if c.Cache.StreamMinInterval <= 0 {
+		return fmt.Errorf("a variável de ambiente STATUS_STREAM_MIN_INTERVAL deve ser uma duração positiva")
+	}
+	if c.Cache.StreamInterval < c.Cache.StreamMinInterval || c.Cache.StreamInterval > c.Cache.StreamMaxInterval {
+		return fmt.Errorf("a variável de ambiente STATUS_STREAM_INTERVAL (%s) deve estar entre STATUS_STREAM_MIN_INTERVAL (%s) e STATUS_STREAM_MAX_INTERVAL (%s)",
+			c.Cache.StreamInterval, c.Cache.StreamMinInterval, c.Cache.StreamMaxInterval)
+	}
+
+	if c.Bulk.Adaptive {
+		if c.Bulk.MaxConcurrency < c.Bulk.Concurrency {
+			return fmt.Errorf("a variável de ambiente BULK_MAX_CONCURRENCY (%d) não pode ser menor que BULK_CONCURRENCY (%d)", c.Bulk.MaxConcurrency, c.Bulk.Concurrency)
+		}
+		if c.Bulk.LatencyTarget <= 0 {
+			return fmt.Errorf("a variável de ambiente BULK_LATENCY_TARGET deve ser uma duração positiva")
+		}
+	}
+
+	if c.Server.MaxHeaderIDs < 0 {
+		return fmt.Errorf("a variável de ambiente HEADER_MAX_IDS não pode ser negativa")
+	}
+
+	if c.Server.FanoutConcurrency < 0 {
+		return fmt.Errorf("a variável de ambiente PLATFORM_FANOUT_CONCURRENCY não pode ser negativa")
+	}
+
+	if c.Server.GzipMinLength < 0 {
+		return fmt.Errorf("a variável de ambiente GZIP_MIN_LENGTH não pode ser negativa")
+	}
+
+	if c.Server.MaxRequestTimeout <= 0 {
+		return fmt.Errorf("a variável de ambiente REQUEST_TIMEOUT_MAX deve ser uma duração positiva")
+	}
+
+	if c.Debug.AllowURLOverride {
+		if len(c.Debug.URLOverrideHosts) == 0 {
+			return fmt.Errorf("a variável de ambiente URL_OVERRIDE_ALLOWED_HOSTS é obrigatória com ALLOW_URL_OVERRIDE=true")
+		}
+		if !c.Debug.Sandbox.HasAnotaAi() && !c.Debug.Sandbox.HasDeliveryVip() {
+			return fmt.Errorf("ALLOW_URL_OVERRIDE=true exige as credenciais de sandbox de ao menos uma plataforma (SANDBOX_ANOTAAI_EMAIL/SANDBOX_ANOTAAI_PASSWORD ou SANDBOX_DELIVERYVIP_CLIENT_ID/SANDBOX_DELIVERYVIP_CLIENT_SECRET)")
+		}
+	}
+
+	if c.Debug.RecentOperations < 0 {
+		return fmt.Errorf("a variável de ambiente RECENT_OPERATIONS_SIZE não pode ser negativa")
+	}
+
+	if c.Bulk.MaxRetries < 0 {
+		return fmt.Errorf("a variável de ambiente BULK_MAX_RETRIES não pode ser negativa")
+	}
+	if c.Log.SampleRate < 0 || c.Log.SampleRate > 1 {
+		return fmt.Errorf("a variável de ambiente LOG_SAMPLE_RATE deve estar entre 0 e 1 (recebido: %v)", c.Log.SampleRate)
+	}
+	if c.Bulk.RetryBudget < 0 || c.Bulk.RetryBudget > 1 {
+		return fmt.Errorf("a variável de ambiente BULK_RETRY_BUDGET deve estar entre 0 e 1 (recebido: %v)", c.Bulk.RetryBudget)
+	}
+	if c.Bulk.StoreTimeout < 0 {
+		return fmt.Errorf("a variável de ambiente PER_STORE_TIMEOUT não pode ser negativa")
+	}
+	if c.Bulk.Deadline < 0 {
+		return fmt.Errorf("a variável de ambiente BULK_DEADLINE não pode ser negativa")
+	}
+	if c.Bulk.ConfirmThreshold < 0 {
+		return fmt.Errorf("a variável de ambiente BULK_CONFIRM_THRESHOLD não pode ser negativa")
+	}
+
+	if c.Storage.Backend != StorageMemoria {
+		return fmt.Errorf("a variável de ambiente STORAGE_BACKEND deve ser %s (recebido: %q)", StorageMemoria, c.Storage.Backend)
+	}
+
+	if c.Webhook.URL != "" {
+		if !IsValidPlatformURL(c.Webhook.URL) {
+			return fmt.Errorf("a variável de ambiente WEBHOOK_URL deve conter uma URL absoluta válida (recebido: %q)", c.Webhook.URL)
+		}
+		switch c.Webhook.Mode {
+		case WebhookModoLoja, WebhookModoLote, WebhookModoAmbos:
+		default:
+			return fmt.Errorf("a variável de ambiente WEBHOOK_MODE deve ser %s, %s ou %s (recebido: %q)", WebhookModoLoja, WebhookModoLote, WebhookModoAmbos, c.Webhook.Mode)
+		}
+		if c.Webhook.MaxRetries < 0 {
+			return fmt.Errorf("a variável de ambiente WEBHOOK_MAX_RETRIES não pode ser negativa")
+		}
+		if c.Webhook.QueueSize <= 0 {
+			return fmt.Errorf("a variável de ambiente WEBHOOK_QUEUE_SIZE deve ser positiva")
+		}
+		if c.Webhook.Workers <= 0 {
+			return fmt.Errorf("a variável de ambiente WEBHOOK_WORKERS deve ser positiva")
+		}
+	}
+
+	return nil
+}
+
+// IsValidPlatformURL verifica se a URL base de uma plataforma é absoluta e possui host
+func IsValidPlatformURL(value string) bool {
+	parsed, err := url.Parse(value)
+	return err == nil && (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != ""
 }
 
 // getEnv obtém uma variável de ambiente com um valor padrão
@@ -72,3 +824,74 @@ func getEnv(key, fallback string) string {
 	}
 	return fallback
 }
+
+// getEnvInt obtém uma variável de ambiente inteira com um valor padrão
+func getEnvInt(key string, fallback int) int {
+	if value, err := strconv.Atoi(os.Getenv(key)); err == nil {
+		return value
+	}
+	return fallback
+}
+
+// getEnvFloat obtém uma variável de ambiente decimal com um valor padrão
+func getEnvFloat(key string, fallback float64) float64 {
+	if value, err := strconv.ParseFloat(os.Getenv(key), 64); err == nil {
+		return value
+	}
+	return fallback
+}
+
+// getEnvHeaders obtém uma lista de headers no formato "Nome=valor;Outro=valor". Itens sem "="
+// ou com nome vazio são ignorados
+func getEnvHeaders(key string) map[string]string {
+	headers := make(map[string]string)
+	for _, item := range strings.Split(os.Getenv(key), ";") {
+		name, value, ok := strings.Cut(item, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			continue
+		}
+		headers[name] = strings.TrimSpace(value)
+	}
+	return headers
+}
+
+// getEnvList obtém uma lista de valores separados por vírgula, ignorando os itens vazios
+func getEnvList(key string) []string {
+	var values []string
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			values = append(values, item)
+		}
+	}
+	return values
+}
+
+// getEnvBool obtém uma variável de ambiente booleana com um valor padrão
+func getEnvBool(key string, fallback bool) bool {
+	if value, err := strconv.ParseBool(os.Getenv(key)); err == nil {
+		return value
+	}
+	return fallback
+}
+
+// durationEnv lê as variáveis de ambiente de duração de Load, guardando as que estão preenchidas
+// com um valor que não pôde ser interpretado para que Validate as rejeite
+type durationEnv struct {
+	invalidas []string
+}
+
+// get obtém uma variável de ambiente de duração (ex.: "30s", "3h") com um valor padrão. Um valor
+// inválido (ex.: "3 hours") usa o padrão e é registrado em invalidas
+func (d *durationEnv) get(key string, fallback time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		d.invalidas = append(d.invalidas, key)
+		return fallback
+	}
+	return parsed
+}
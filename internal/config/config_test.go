@@ -0,0 +1,134 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidatePlatformURLsOnlyForEnabledPlatforms(t *testing.T) {
+	tests := []struct {
+		name      string
+		enabled   string
+		anotaAi   string
+		wantErrIn string
+	}{
+		{name: "todas habilitadas com URLs válidas", anotaAi: "https://integration-admin.api.anota.ai"},
+		{name: "plataforma habilitada sem URL", anotaAi: "nao-e-url", wantErrIn: "ANOTAAI_API_URL"},
+		{name: "plataforma desabilitada sem URL", enabled: "deliveryvip", anotaAi: "nao-e-url"},
+		{name: "nome sem distinção de maiúsculas", enabled: "DeliveryVip", anotaAi: "nao-e-url"},
+		{name: "plataforma desconhecida", enabled: "deliveryvip,ifood", wantErrIn: "PLATFORMS_ENABLED"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("BEARER_TOKEN", "token")
+			t.Setenv("PLATFORMS_ENABLED", tt.enabled)
+			t.Setenv("ANOTAAI_API_URL", tt.anotaAi)
+
+			err := Load().Validate()
+			if tt.wantErrIn == "" {
+				if err != nil {
+					t.Fatalf("Validate() = %v, esperado nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErrIn) {
+				t.Fatalf("Validate() = %v, esperado erro mencionando %s", err, tt.wantErrIn)
+			}
+		})
+	}
+}
+
+func TestIsURLOverrideHostAllowed(t *testing.T) {
+	debug := DebugConfig{URLOverrideHosts: []string{"sandbox.deliveryvip.com.br", "localhost:9090"}}
+
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{url: "https://sandbox.deliveryvip.com.br", want: true},
+		{url: "https://SANDBOX.deliveryvip.com.br:8443/api", want: true},
+		{url: "http://localhost:9090", want: true},
+		{url: "http://localhost:9091"},
+		{url: "https://api.deliveryvip.com.br"},
+		{url: "https://sandbox.deliveryvip.com.br.atacante.com"},
+	}
+
+	for _, tt := range tests {
+		if got := debug.IsURLOverrideHostAllowed(tt.url); got != tt.want {
+			t.Errorf("IsURLOverrideHostAllowed(%q) = %v, esperado %v", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestLoadTokenHeader(t *testing.T) {
+	tests := []struct {
+		name      string
+		anotaAi   string
+		want      TokenHeader
+		wantErrIn string
+	}{
+		{name: "padrão do registro", want: TokenHeader{Name: "authorization"}},
+		{name: "header sem prefixo", anotaAi: "X-Api-Key", want: TokenHeader{Name: "X-Api-Key"}},
+		{name: "header com prefixo", anotaAi: "Authorization: Bearer", want: TokenHeader{Name: "Authorization", Prefix: "Bearer"}},
+		{name: "nome vazio", anotaAi: ": Bearer", wantErrIn: "ANOTAAI_TOKEN_HEADER"},
+		{name: "nome com espaço", anotaAi: "Api Key", wantErrIn: "ANOTAAI_TOKEN_HEADER"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("BEARER_TOKEN", "token")
+			t.Setenv("ANOTAAI_TOKEN_HEADER", tt.anotaAi)
+
+			cfg := Load()
+			err := cfg.Validate()
+			if tt.wantErrIn != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErrIn) {
+					t.Fatalf("Validate() = %v, esperado erro mencionando %s", err, tt.wantErrIn)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Validate() = %v, esperado nil", err)
+			}
+			if cfg.Platforms.AnotaAi.TokenHeader != tt.want {
+				t.Errorf("TokenHeader = %+v, esperado %+v", cfg.Platforms.AnotaAi.TokenHeader, tt.want)
+			}
+			if cfg.Platforms.DeliveryVip.TokenHeader != DeliveryVipTokenHeader() {
+				t.Errorf("TokenHeader do DeliveryVip = %+v, esperado o padrão do registro", cfg.Platforms.DeliveryVip.TokenHeader)
+			}
+		})
+	}
+}
+
+func TestValidateRejectsMalformedDurations(t *testing.T) {
+	tests := []struct {
+		name      string
+		key       string
+		value     string
+		wantErrIn string
+	}{
+		{name: "duração válida", key: "ANOTAAI_TOKEN_RENEWAL", value: "3h"},
+		{name: "duração por extenso", key: "ANOTAAI_TOKEN_RENEWAL", value: "3 hours", wantErrIn: "ANOTAAI_TOKEN_RENEWAL"},
+		{name: "número sem unidade", key: "DELIVERYVIP_TOKEN_RENEWAL", value: "6", wantErrIn: "DELIVERYVIP_TOKEN_RENEWAL"},
+		{name: "timeout do webhook", key: "WEBHOOK_TIMEOUT", value: "dez segundos", wantErrIn: "WEBHOOK_TIMEOUT"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("BEARER_TOKEN", "token")
+			t.Setenv(tt.key, tt.value)
+
+			err := Load().Validate()
+			if tt.wantErrIn == "" {
+				if err != nil {
+					t.Fatalf("Validate() = %v, esperado nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErrIn) {
+				t.Fatalf("Validate() = %v, esperado erro mencionando %s", err, tt.wantErrIn)
+			}
+		})
+	}
+}
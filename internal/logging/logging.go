@@ -0,0 +1,31 @@
+package logging
+
+import (
+	"io"
+	"log"
+	"os"
+
+	"delivery-control/internal/config"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// Setup configura o destino dos logs da aplicação
+// Se LOG_FILE estiver definido, os logs são gravados no arquivo com rotação por tamanho;
+// caso contrário, continuam sendo escritos no stderr. Retorna o writer configurado
+func Setup(cfg config.LogConfig) io.Writer {
+	if cfg.File == "" {
+		return os.Stderr
+	}
+
+	writer := &lumberjack.Logger{
+		Filename:   cfg.File,
+		MaxSize:    cfg.MaxSizeMB,
+		MaxBackups: cfg.MaxBackups,
+		MaxAge:     cfg.MaxAgeDays,
+		Compress:   cfg.Compress,
+	}
+
+	log.SetOutput(writer)
+	return writer
+}
@@ -0,0 +1,282 @@
+// Package metrics concentra as métricas Prometheus expostas em /metrics
+package metrics
+
+import (
+	"cmp"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Resultados registrados nas métricas
+const (
+	ResultadoSucesso = "sucesso"
+	ResultadoFalha   = "falha"
+)
+
+// Resultados das consultas ao cache de status
+const (
+	CacheAcerto = "acerto"
+	CacheFalta  = "falta"
+)
+
+// tokenRenewals conta as renovações de token por plataforma, conta e resultado
+var tokenRenewals = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "control_api_token_renewals_total",
+	Help: "Renovações de token de acesso das plataformas, por resultado",
+}, []string{"plataforma", "conta", "resultado"})
+
+// bulkConcurrency informa o limite atual de lojas processadas em paralelo nas operações em lote
+var bulkConcurrency = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "control_api_bulk_concurrency",
+	Help: "Limite atual de lojas processadas em paralelo nas operações em lote, por plataforma",
+}, []string{"plataforma"})
+
+// fanoutConcurrency informa o limite de plataformas consultadas em paralelo (0 sem limite)
+var fanoutConcurrency = prometheus.NewGauge(prometheus.GaugeOpts{
+	Name: "control_api_platform_fanout_concurrency",
+	Help: "Limite de plataformas consultadas em paralelo nos endpoints de todas as plataformas (0 sem limite)",
+})
+
+// storeOperations conta as operações executadas nas lojas por plataforma, operação e resultado
+var storeOperations = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "control_api_store_operations_total",
+	Help: "Operações executadas nas lojas, por plataforma, operação e resultado",
+}, []string{"plataforma", "operacao", "resultado"})
+
+// cacheLookups conta as consultas ao cache de status por plataforma e resultado (acerto ou falta)
+var cacheLookups = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "control_api_status_cache_lookups_total",
+	Help: "Consultas ao cache de status do catálogo, por plataforma e resultado",
+}, []string{"plataforma", "resultado"})
+
+// webhooksDropped conta os eventos de webhook descartados porque a fila de entrega estava cheia
+var webhooksDropped = prometheus.NewCounter(prometheus.CounterOpts{
+	Name: "control_api_webhook_events_dropped_total",
+	Help: "Eventos de webhook descartados com a fila de entrega cheia (WEBHOOK_QUEUE_SIZE)",
+})
+
+// tokenAgeDesc descreve o tempo desde a última renovação de token bem-sucedida
+var tokenAgeDesc = prometheus.NewDesc(
+	"control_api_token_seconds_since_last_renewal",
+	"Segundos desde a última renovação de token bem-sucedida (desde a inicialização, se nunca renovou)",
+	[]string{"plataforma", "conta"}, nil,
+)
+
+// tokenKey identifica o token de uma conta em uma plataforma
+type tokenKey struct {
+	plataforma string
+	conta      string
+}
+
+// tokenAgeCollector calcula a idade dos tokens no momento da coleta
+type tokenAgeCollector struct {
+	mu          sync.RWMutex
+	lastSuccess map[tokenKey]time.Time
+}
+
+var tokenAge = &tokenAgeCollector{lastSuccess: make(map[tokenKey]time.Time)}
+
+// operationKey identifica uma operação em uma plataforma
+type operationKey struct {
+	plataforma string
+	operacao   string
+}
+
+// counters guarda em memória os mesmos contadores enviados ao Prometheus, lidos por Snapshot
+// em ambientes sem Prometheus
+var counters = struct {
+	mu         sync.Mutex
+	operations map[operationKey]map[string]int64
+	cache      map[string]map[string]int64
+	fanout     int
+	webhooks   int64
+}{
+	operations: make(map[operationKey]map[string]int64),
+	cache:      make(map[string]map[string]int64),
+}
+
+func init() {
+	prometheus.MustRegister(tokenRenewals, tokenAge, bulkConcurrency, fanoutConcurrency, storeOperations, cacheLookups, webhooksDropped)
+}
+
+// RegisterToken passa a reportar a idade do token da conta, contando a partir de agora
+// até a primeira renovação bem-sucedida
+func RegisterToken(plataforma, conta string) {
+	tokenAge.mu.Lock()
+	defer tokenAge.mu.Unlock()
+	key := tokenKey{plataforma, conta}
+	if _, ok := tokenAge.lastSuccess[key]; !ok {
+		tokenAge.lastSuccess[key] = time.Now()
+	}
+}
+
+// RecordTokenRenewal registra o resultado de uma renovação de token
+func RecordTokenRenewal(plataforma, conta string, err error) {
+	if err != nil {
+		tokenRenewals.WithLabelValues(plataforma, conta, ResultadoFalha).Inc()
+		return
+	}
+
+	tokenRenewals.WithLabelValues(plataforma, conta, ResultadoSucesso).Inc()
+	tokenAge.mu.Lock()
+	tokenAge.lastSuccess[tokenKey{plataforma, conta}] = time.Now()
+	tokenAge.mu.Unlock()
+}
+
+// RecordOperation registra o resultado de uma operação executada em uma loja
+func RecordOperation(plataforma, operacao string, err error) {
+	resultado := ResultadoSucesso
+	if err != nil {
+		resultado = ResultadoFalha
+	}
+	storeOperations.WithLabelValues(plataforma, operacao, resultado).Inc()
+
+	counters.mu.Lock()
+	defer counters.mu.Unlock()
+	key := operationKey{plataforma, operacao}
+	if counters.operations[key] == nil {
+		counters.operations[key] = make(map[string]int64)
+	}
+	counters.operations[key][resultado]++
+}
+
+// RecordCacheLookup registra uma consulta ao cache de status da plataforma
+func RecordCacheLookup(plataforma string, acerto bool) {
+	resultado := CacheFalta
+	if acerto {
+		resultado = CacheAcerto
+	}
+	cacheLookups.WithLabelValues(plataforma, resultado).Inc()
+
+	counters.mu.Lock()
+	defer counters.mu.Unlock()
+	if counters.cache[plataforma] == nil {
+		counters.cache[plataforma] = make(map[string]int64)
+	}
+	counters.cache[plataforma][resultado]++
+}
+
+// RecordWebhookDropped registra um evento de webhook descartado com a fila de entrega cheia
+func RecordWebhookDropped() {
+	webhooksDropped.Inc()
+	counters.mu.Lock()
+	defer counters.mu.Unlock()
+	counters.webhooks++
+}
+
+// SetFanoutConcurrency registra o limite de plataformas consultadas em paralelo
+func SetFanoutConcurrency(limite int) {
+	fanoutConcurrency.Set(float64(limite))
+	counters.mu.Lock()
+	defer counters.mu.Unlock()
+	counters.fanout = limite
+}
+
+// SetBulkConcurrency registra o limite atual de concorrência das operações em lote da plataforma
+func SetBulkConcurrency(plataforma string, limite int) {
+	bulkConcurrency.WithLabelValues(plataforma).Set(float64(limite))
+}
+
+// Describe implementa prometheus.Collector
+func (c *tokenAgeCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- tokenAgeDesc
+}
+
+// Collect implementa prometheus.Collector
+func (c *tokenAgeCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for key, lastSuccess := range c.lastSuccess {
+		ch <- prometheus.MustNewConstMetric(tokenAgeDesc, prometheus.GaugeValue,
+			time.Since(lastSuccess).Seconds(), key.plataforma, key.conta)
+	}
+}
+
+// Snapshot é a fotografia dos contadores servida em GET /stats
+type Snapshot struct {
+	Operacoes []OperationStats `json:"operacoes"`
+	Tokens    []TokenStats     `json:"tokens"`
+	Cache     []CacheStats     `json:"cache"`
+	// ConcorrenciaPlataformas é o limite de plataformas consultadas em paralelo (0 sem limite)
+	ConcorrenciaPlataformas int `json:"concorrencia_plataformas"`
+	// WebhooksDescartados conta os eventos descartados com a fila de entrega cheia
+	WebhooksDescartados int64 `json:"webhooks_descartados"`
+}
+
+// OperationStats traz as contagens de uma operação em uma plataforma
+type OperationStats struct {
+	Plataforma string `json:"plataforma"`
+	Operacao   string `json:"operacao"`
+	Sucesso    int64  `json:"sucesso"`
+	Falha      int64  `json:"falha"`
+}
+
+// TokenStats traz a idade do token de uma conta
+type TokenStats struct {
+	Plataforma string `json:"plataforma"`
+	Conta      string `json:"conta"`
+	// IdadeSegundos conta desde a última renovação bem-sucedida (desde a inicialização, se nunca renovou)
+	IdadeSegundos float64 `json:"idade_segundos"`
+}
+
+// CacheStats traz as consultas ao cache de status de uma plataforma
+type CacheStats struct {
+	Plataforma string `json:"plataforma"`
+	Acertos    int64  `json:"acertos"`
+	Faltas     int64  `json:"faltas"`
+	// TaxaAcerto é a fração das consultas atendidas pelo cache, entre 0 e 1 (0 sem consultas)
+	TaxaAcerto float64 `json:"taxa_acerto"`
+}
+
+// TakeSnapshot retorna os contadores atuais, ordenados por plataforma
+func TakeSnapshot() Snapshot {
+	snapshot := Snapshot{
+		Operacoes: []OperationStats{},
+		Tokens:    []TokenStats{},
+		Cache:     []CacheStats{},
+	}
+
+	counters.mu.Lock()
+	snapshot.ConcorrenciaPlataformas = counters.fanout
+	snapshot.WebhooksDescartados = counters.webhooks
+	for key, resultados := range counters.operations {
+		snapshot.Operacoes = append(snapshot.Operacoes, OperationStats{
+			Plataforma: key.plataforma,
+			Operacao:   key.operacao,
+			Sucesso:    resultados[ResultadoSucesso],
+			Falha:      resultados[ResultadoFalha],
+		})
+	}
+	for plataforma, resultados := range counters.cache {
+		stats := CacheStats{Plataforma: plataforma, Acertos: resultados[CacheAcerto], Faltas: resultados[CacheFalta]}
+		if total := stats.Acertos + stats.Faltas; total > 0 {
+			stats.TaxaAcerto = float64(stats.Acertos) / float64(total)
+		}
+		snapshot.Cache = append(snapshot.Cache, stats)
+	}
+	counters.mu.Unlock()
+
+	tokenAge.mu.RLock()
+	for key, lastSuccess := range tokenAge.lastSuccess {
+		snapshot.Tokens = append(snapshot.Tokens, TokenStats{
+			Plataforma:    key.plataforma,
+			Conta:         key.conta,
+			IdadeSegundos: time.Since(lastSuccess).Seconds(),
+		})
+	}
+	tokenAge.mu.RUnlock()
+
+	slices.SortFunc(snapshot.Operacoes, func(a, b OperationStats) int {
+		return cmp.Or(cmp.Compare(a.Plataforma, b.Plataforma), cmp.Compare(a.Operacao, b.Operacao))
+	})
+	slices.SortFunc(snapshot.Tokens, func(a, b TokenStats) int {
+		return cmp.Or(cmp.Compare(a.Plataforma, b.Plataforma), cmp.Compare(a.Conta, b.Conta))
+	})
+	slices.SortFunc(snapshot.Cache, func(a, b CacheStats) int {
+		return cmp.Compare(a.Plataforma, b.Plataforma)
+	})
+	return snapshot
+}
@@ -0,0 +1,41 @@
+package models
+
+import "time"
+
+// RegistroAuditoria representa uma operação executada em uma loja, mantida no repositório de auditoria
+type RegistroAuditoria struct {
+	Timestamp  time.Time  `json:"timestamp"`
+	Operacao   Operacao   `json:"operacao"`
+	Plataforma Plataforma `json:"plataforma"`
+	IdLoja     string     `json:"id_loja"`
+	Motivo     string     `json:"motivo,omitempty"`
+	Sucesso    bool       `json:"sucesso"`
+	Erro       string     `json:"erro,omitempty"`
+}
+
+// RegistroStatus representa o status de uma loja observado em um instante, mantido no histórico
+type RegistroStatus struct {
+	Timestamp time.Time `json:"timestamp"`
+	Status    Status    `json:"status"`
+}
+
+// EstadoLote representa a etapa de execução de uma operação em lote
+type EstadoLote string
+
+const (
+	EstadoLotePendente   EstadoLote = "pendente"
+	EstadoLoteExecutando EstadoLote = "executando"
+	EstadoLoteConcluido  EstadoLote = "concluido"
+)
+
+// Lote representa uma operação em lote mantida no repositório de lotes
+type Lote struct {
+	IdLote      string                  `json:"id_lote"`
+	Plataforma  Plataforma              `json:"plataforma"`
+	Operacao    Operacao                `json:"operacao"`
+	Estado      EstadoLote              `json:"estado"`
+	IdsLojas    []string                `json:"ids_lojas"`
+	Resultados  []ResultadoOperacaoLoja `json:"resultados,omitempty"`
+	CriadoEm    time.Time               `json:"criado_em"`
+	ConcluidoEm *time.Time              `json:"concluido_em,omitempty"`
+}
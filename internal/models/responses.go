@@ -1,5 +1,10 @@
 package models
 
+import (
+	"strings"
+	"time"
+)
+
 // Plataforma representa as plataformas suportadas
 type Plataforma string
 
@@ -8,6 +13,12 @@ const (
 	PlataformaDeliveryVip Plataforma = "deliveryvip"
 )
 
+// ParsePlataforma retorna o identificador canônico (minúsculo, sem espaços) da plataforma
+// informada pelo cliente, para que "AnotaAi" e "anotaai" sejam a mesma plataforma
+func ParsePlataforma(value string) Plataforma {
+	return Plataforma(strings.ToLower(strings.TrimSpace(value)))
+}
+
 // Status representa o status de uma loja
 type Status string
 
@@ -19,77 +30,567 @@ const (
 	StatusBloqueado     Status = "bloqueado"
 	StatusDemonstracao  Status = "demonstracao"
 	StatusNaoEncontrado Status = "nao_encontrado"
+	// StatusIncompleto indica uma página do AnotaAI sem os dados do estabelecimento (cadastro em andamento)
+	StatusIncompleto Status = "incompleto"
+
+	// StatusErro indica que a operação em lote falhou por um motivo diferente de loja não
+	// encontrada. Não é um status de loja, por isso não é aceito por IsValid
+	StatusErro Status = "erro"
+	// StatusTempoEsgotado indica que a loja excedeu o tempo limite por loja de uma operação em
+	// lote (PER_STORE_TIMEOUT). Assim como StatusErro, não é aceito por IsValid
+	StatusTempoEsgotado Status = "tempo_esgotado"
+	// StatusNaoProcessado indica que a loja não chegou a ser processada porque o lote atingiu o prazo
+	// de BULK_DEADLINE. Também não é aceito por IsValid
+	StatusNaoProcessado Status = "nao_processado"
+	// StatusProtegida indica que a desativação foi recusada porque a loja está na lista de lojas
+	// protegidas. Também não é aceito por IsValid
+	StatusProtegida Status = "protegida"
+	// StatusInvalido indica um ID de loja malformado, que não chegou a ser consultado nem enviado à
+	// plataforma. Também não é aceito por IsValid
+	StatusInvalido Status = "invalido"
+)
+
+// IsValid verifica se o status é um dos valores conhecidos
+func (s Status) IsValid() bool {
+	switch s {
+	case StatusAtivo, StatusEmTeste, StatusTesteExpirado, StatusCancelado, StatusBloqueado, StatusDemonstracao, StatusNaoEncontrado, StatusIncompleto:
+		return true
+	}
+	return false
+}
+
+// OrdenacaoLojas representa o critério de ordenação da listagem completa de lojas
+type OrdenacaoLojas string
+
+const (
+	OrdenarPorID   OrdenacaoLojas = "id"
+	OrdenarPorNome OrdenacaoLojas = "nome"
+)
+
+// IsValid verifica se a ordenação é um dos valores conhecidos
+func (o OrdenacaoLojas) IsValid() bool {
+	return o == OrdenarPorID || o == OrdenarPorNome
+}
+
+// FormatoLojas representa o formato das lojas nas consultas de status
+type FormatoLojas string
+
+const (
+	// FormatoNormalizado é o formato padrão, com o DTO Loja, igual em todas as plataformas
+	FormatoNormalizado FormatoLojas = "loja"
+	// FormatoLegado mantém o formato anterior ao DTO Loja (StatusLojaDetalhes, LojaDuplicada e os
+	// resultados de operação sem "loja"), para clientes que ainda não migraram
+	FormatoLegado FormatoLojas = "legado"
+)
+
+// IsValid verifica se o formato é um dos valores conhecidos
+func (f FormatoLojas) IsValid() bool {
+	return f == FormatoNormalizado || f == FormatoLegado
+}
+
+// FormatoDocumento representa como o documento (CPF/CNPJ) é apresentado nas consultas de status
+type FormatoDocumento string
+
+const (
+	// FormatoDocumentoLimpo é o formato padrão, apenas com os dígitos
+	FormatoDocumentoLimpo FormatoDocumento = "limpo"
+	// FormatoDocumentoFormatado aplica a máscara de CPF ou CNPJ (pontos, barra e traço)
+	FormatoDocumentoFormatado FormatoDocumento = "formatado"
+)
+
+// IsValid verifica se o formato do documento é um dos valores conhecidos
+func (f FormatoDocumento) IsValid() bool {
+	return f == FormatoDocumentoLimpo || f == FormatoDocumentoFormatado
+}
+
+// Operacao representa as operações que uma plataforma pode suportar
+type Operacao string
+
+const (
+	OperacaoAtivar    Operacao = "ativar"
+	OperacaoDesativar Operacao = "desativar"
+	OperacaoStatus    Operacao = "status"
+	OperacaoPing      Operacao = "ping"
 )
 
 // TipoErro representa os tipos de erro da API
 type TipoErro string
 
 const (
-	ErroRequisicaoInvalida TipoErro = "invalid_request"
-	ErroNaoAutorizado      TipoErro = "unauthorized"
-	ErroNaoEncontrado      TipoErro = "not_found"
-	ErroBadGateway         TipoErro = "bad_gateway"
-	ErroInternoServidor    TipoErro = "internal_server_error"
+	ErroRequisicaoInvalida    TipoErro = "invalid_request"
+	ErroNaoAutorizado         TipoErro = "unauthorized"
+	ErroProibido              TipoErro = "forbidden"
+	ErroNaoEncontrado         TipoErro = "not_found"
+	ErroBadGateway            TipoErro = "bad_gateway"
+	ErroServicoIndisponivel   TipoErro = "service_unavailable"
+	ErroConflito              TipoErro = "conflict"
+	ErroConfirmacaoNecessaria TipoErro = "precondition_required"
+	// ErroEntidadeNaoProcessavel indica uma requisição bem formada, mas semanticamente impossível
+	// (ex.: a mesma loja pedida como ativa e bloqueada)
+	ErroEntidadeNaoProcessavel TipoErro = "unprocessable_entity"
+	ErroTempoEsgotado          TipoErro = "gateway_timeout"
+	ErroInternoServidor        TipoErro = "internal_server_error"
+	ErroOperacaoNaoSuportada   TipoErro = "unsupported_operation"
 )
 
 // RespostaOperacaoLoja representa a resposta para operações de ativação/desativação
 type RespostaOperacaoLoja struct {
 	Plataforma Plataforma `json:"plataforma"`
 	IdLoja     string     `json:"id_loja"`
-	Status     Status     `json:"status"`
-	Mensagem   string     `json:"mensagem"`
+	// IdPlataforma só é retornado quando difere do ID enviado pelo cliente
+	IdPlataforma string `json:"id_plataforma,omitempty"`
+	Status       Status `json:"status"`
+	Mensagem     string `json:"mensagem"`
+}
+
+// RequisicaoDefinicaoStatus representa o body de PUT /plataformas/{plataforma}/lojas/{idLoja}/status
+type RequisicaoDefinicaoStatus struct {
+	// Status é o status alvo da loja: ativo ou bloqueado
+	Status Status `json:"status"`
+	Motivo string `json:"motivo,omitempty"`
+}
+
+// RespostaDefinicaoStatus representa o resultado da definição declarativa do status de uma loja
+type RespostaDefinicaoStatus struct {
+	Plataforma Plataforma `json:"plataforma"`
+	IdLoja     string     `json:"id_loja"`
+	// IdPlataforma só é retornado quando difere do ID enviado pelo cliente
+	IdPlataforma   string `json:"id_plataforma,omitempty"`
+	StatusAnterior Status `json:"status_anterior"`
+	Status         Status `json:"status"`
+	// Alterado indica se a plataforma foi chamada para mudar o status; falso quando a loja já estava no alvo
+	Alterado bool   `json:"alterado"`
+	Mensagem string `json:"mensagem"`
+	// Loja é a loja no DTO normalizado, já no status alvo; omitida com ?formato=legado
+	Loja *Loja `json:"loja,omitempty"`
+}
+
+// RespostaOperacoesRecentes representa as últimas operações executadas nas lojas, mantidas em memória
+type RespostaOperacoesRecentes struct {
+	Total int `json:"total"`
+	// Capacidade é a quantidade máxima de operações mantidas (RECENT_OPERATIONS_SIZE)
+	Capacidade int                 `json:"capacidade"`
+	Operacoes  []RegistroAuditoria `json:"operacoes"`
+}
+
+// RequisicaoReconciliacao representa o body de POST /plataformas/{plataforma}/lojas/reconciliar/diff,
+// com as lojas que deveriam estar ativas e as que deveriam estar bloqueadas
+type RequisicaoReconciliacao struct {
+	Ativas     []string `json:"ativas"`
+	Bloqueadas []string `json:"bloqueadas"`
+}
+
+// RespostaReconciliacaoDiff representa o plano para levar as lojas ao status desejado, sem alterações aplicadas
+type RespostaReconciliacaoDiff struct {
+	Plataforma Plataforma `json:"plataforma"`
+	// Bloquear são as lojas que deveriam estar bloqueadas e não estão
+	Bloquear []ItemReconciliacao `json:"bloquear"`
+	// Desbloquear são as lojas que deveriam estar ativas e não estão
+	Desbloquear    []ItemReconciliacao `json:"desbloquear"`
+	Corretas       []ItemReconciliacao `json:"corretas"`
+	NaoEncontradas []ItemReconciliacao `json:"nao_encontradas"`
+}
+
+// ItemReconciliacao representa uma loja do plano de reconciliação e o seu status atual
+type ItemReconciliacao struct {
+	IdLoja string `json:"id_loja"`
+	// IdPlataforma só é retornado quando difere do ID enviado pelo cliente
+	IdPlataforma string `json:"id_plataforma,omitempty"`
+	StatusAtual  Status `json:"status_atual"`
 }
 
 // RequisicaoMultiplasLojas representa a requisição para operações com múltiplas lojas
 type RequisicaoMultiplasLojas struct {
 	IdsLojas []string `json:"ids_lojas" validate:"required,min=1"`
+	// PrioridadeAlta e PrioridadeNormal são uma alternativa a IdsLojas: as lojas de prioridade
+	// alta são processadas antes das demais
+	PrioridadeAlta   []string `json:"prioridade_alta,omitempty"`
+	PrioridadeNormal []string `json:"prioridade_normal,omitempty"`
+	// Motivo é opcional e usado apenas na desativação
+	Motivo string `json:"motivo,omitempty"`
+}
+
+// Prioridade identifica o grupo de prioridade de uma loja em uma operação em lote
+type Prioridade string
+
+const (
+	PrioridadeAlta   Prioridade = "alta"
+	PrioridadeNormal Prioridade = "normal"
+)
+
+// RespostaValidacaoLote representa a validação de uma lista de IDs antes de uma operação em lote
+type RespostaValidacaoLote struct {
+	Plataforma Plataforma `json:"plataforma"`
+	Valido     bool       `json:"valido"`
+	Total      int        `json:"total"`
+	IdsLojas   []string   `json:"ids_lojas"`
+	Avisos     []string   `json:"avisos"`
 }
 
 // RespostaOperacaoMultiplasLojas representa a resposta para operações de ativação/desativação de múltiplas lojas
 type RespostaOperacaoMultiplasLojas struct {
-	Plataforma Plataforma              `json:"plataforma"`
+	Plataforma Plataforma `json:"plataforma"`
+	// IdLote identifica o lote nos webhooks e só é retornado quando WEBHOOK_URL está configurada
+	IdLote     string                  `json:"id_lote,omitempty"`
 	Resultados []ResultadoOperacaoLoja `json:"resultados"`
 }
 
+// ResumoLote representa o evento de webhook lote.concluido, enviado ao final de uma operação em lote
+type ResumoLote struct {
+	IdLote        string     `json:"id_lote"`
+	Plataforma    Plataforma `json:"plataforma"`
+	Operacao      Operacao   `json:"operacao"`
+	Total         int        `json:"total"`
+	Sucesso       int        `json:"sucesso"`
+	NaoEncontrado int        `json:"nao_encontrado"`
+	Falha         int        `json:"falha"`
+	DuracaoMs     int64      `json:"duracao_ms"`
+}
+
+// EventoOperacaoLoja representa o evento de webhook loja.operacao, enviado para cada loja de um lote
+type EventoOperacaoLoja struct {
+	IdLote     string                `json:"id_lote"`
+	Plataforma Plataforma            `json:"plataforma"`
+	Operacao   Operacao              `json:"operacao"`
+	Resultado  ResultadoOperacaoLoja `json:"resultado"`
+}
+
 // ResultadoOperacaoLoja representa o resultado individual de uma operação
 type ResultadoOperacaoLoja struct {
-	IdLoja   string    `json:"id_loja"`
-	Status   Status    `json:"status"`
-	Sucesso  bool      `json:"sucesso"`
-	Mensagem string    `json:"mensagem"`
-	Erro     *TipoErro `json:"erro,omitempty"`
+	IdLoja string `json:"id_loja"`
+	// IdPlataforma só é retornado quando difere do ID enviado pelo cliente
+	IdPlataforma string    `json:"id_plataforma,omitempty"`
+	Status       Status    `json:"status"`
+	Sucesso      bool      `json:"sucesso"`
+	Mensagem     string    `json:"mensagem"`
+	Erro         *TipoErro `json:"erro,omitempty"`
+	// Prioridade só é retornada quando a requisição informou os IDs por grupo de prioridade
+	Prioridade Prioridade `json:"prioridade,omitempty"`
+	// Acao só é retornada pelas rotas garantir-ativas e garantir-bloqueadas e indica se a plataforma foi chamada
+	Acao AcaoGarantia `json:"acao,omitempty"`
+	// Chamada identifica a chamada à plataforma que falhou; só é retornada com ?verbose=true
+	Chamada *ChamadaPlataforma `json:"chamada,omitempty"`
+	// Loja é a loja no DTO normalizado após a operação; omitida com ?formato=legado
+	Loja *Loja `json:"loja,omitempty"`
+}
+
+// EtapaChamada identifica a etapa em que uma chamada à plataforma falhou
+type EtapaChamada string
+
+const (
+	// EtapaToken indica que não havia token de acesso (o login da plataforma está pendente ou falhando)
+	EtapaToken EtapaChamada = "token"
+	// EtapaStatus indica a listagem do catálogo usada para consultar o status das lojas
+	EtapaStatus EtapaChamada = "status"
+	// EtapaOperacao indica a chamada de ativação/desativação da loja
+	EtapaOperacao EtapaChamada = "operacao"
+)
+
+// ChamadaPlataforma descreve a chamada à plataforma que falhou, sem host, query string ou tokens
+type ChamadaPlataforma struct {
+	Etapa   EtapaChamada `json:"etapa"`
+	Metodo  string       `json:"metodo,omitempty"`
+	Caminho string       `json:"caminho,omitempty"`
+	// StatusHTTP é o status recebido da plataforma, ausente quando ela não chegou a responder
+	StatusHTTP int `json:"status_http,omitempty"`
 }
 
+// AcaoGarantia indica se uma loja das rotas garantir-ativas/garantir-bloqueadas precisou de uma chamada à plataforma
+type AcaoGarantia string
+
+const (
+	// AcaoExecutada indica que a loja não estava no status pretendido e a operação foi enviada à plataforma
+	AcaoExecutada AcaoGarantia = "executada"
+	// AcaoIgnorada indica que a loja já estava no status pretendido (ou não existe) e a plataforma não foi chamada
+	AcaoIgnorada AcaoGarantia = "ignorada"
+)
+
 // RespostaStatusMultiplasLojas representa a resposta para consulta de status de múltiplas lojas
 type RespostaStatusMultiplasLojas struct {
 	Plataforma Plataforma           `json:"plataforma"`
 	Lojas      []StatusLojaDetalhes `json:"lojas"`
+	// CatalogoVazio indica que a plataforma não retornou nenhuma loja, e não que as lojas solicitadas não foram encontradas
+	CatalogoVazio bool `json:"catalogo_vazio,omitempty"`
+	// Cache é a idade do catálogo usado na resposta, informada nos headers X-Cache-Age e Warning
+	Cache *IdadeCache `json:"-"`
+}
+
+// IdadeCache descreve o catálogo em cache usado em uma consulta de status. Nil com o cache de
+// status desabilitado
+type IdadeCache struct {
+	Idade time.Duration
+	// Desatualizado indica que o catálogo já passou de 80% do TTL
+	Desatualizado bool
+}
+
+// RespostaStatusAgrupado representa a consulta de status com ?agrupar=true: os IDs das lojas
+// agrupados pelo status, na mesma ordem da listagem
+type RespostaStatusAgrupado struct {
+	Plataforma Plataforma          `json:"plataforma"`
+	Total      int                 `json:"total"`
+	Grupos     map[Status][]string `json:"grupos"`
+	// CatalogoVazio indica que a plataforma não retornou nenhuma loja, e não que as lojas solicitadas não foram encontradas
+	CatalogoVazio bool `json:"catalogo_vazio,omitempty"`
+}
+
+// RespostaResumoStatus representa a contagem das lojas por status em uma plataforma
+type RespostaResumoStatus struct {
+	Plataforma Plataforma `json:"plataforma"`
+	// Total é a quantidade de lojas consideradas (as informadas em X-Lojas-IDs ou todo o catálogo)
+	Total     int            `json:"total"`
+	PorStatus map[Status]int `json:"por_status"`
+	// TotalPlataforma é a quantidade de lojas que a plataforma conhece, gerenciadas ou não
+	TotalPlataforma int `json:"total_plataforma"`
+}
+
+// RespostaStatusPlataformas representa a consulta de status em todas as plataformas, indexada pela plataforma
+type RespostaStatusPlataformas map[Plataforma]ResultadoStatusPlataforma
+
+// ResultadoStatusPlataforma representa o resultado da consulta em uma plataforma
+// Erro só é preenchido quando a plataforma falhou e, nesse caso, a lista de lojas fica vazia
+type ResultadoStatusPlataforma struct {
+	*RespostaStatusMultiplasLojas
+	Erro *RespostaErro `json:"erro,omitempty"`
+}
+
+// RespostaLojasDuplicadas representa os documentos (CPF/CNPJ) com lojas em mais de uma plataforma.
+// Erros só é preenchido para as plataformas que falharam, cujas lojas ficam fora da comparação
+type RespostaLojasDuplicadas struct {
+	Total      int                         `json:"total"`
+	Duplicadas []DocumentoDuplicado        `json:"duplicadas"`
+	Erros      map[Plataforma]RespostaErro `json:"erros,omitempty"`
+}
+
+// DocumentoDuplicado representa um documento e as lojas que o usam em cada plataforma
+type DocumentoDuplicado struct {
+	Documento string `json:"documento"`
+	Lojas     []Loja `json:"lojas"`
+}
+
+// RespostaLojasDuplicadasLegado representa a consulta de documentos duplicados com ?formato=legado
+type RespostaLojasDuplicadasLegado struct {
+	Total      int                         `json:"total"`
+	Duplicadas []DocumentoDuplicadoLegado  `json:"duplicadas"`
+	Erros      map[Plataforma]RespostaErro `json:"erros,omitempty"`
+}
+
+// DocumentoDuplicadoLegado representa um documento duplicado com as lojas no formato LojaDuplicada
+type DocumentoDuplicadoLegado struct {
+	Documento string          `json:"documento"`
+	Lojas     []LojaDuplicada `json:"lojas"`
+}
+
+// LojaDuplicada representa uma das lojas de um documento presente em mais de uma plataforma, no
+// formato anterior ao DTO Loja
+type LojaDuplicada struct {
+	Plataforma Plataforma `json:"plataforma"`
+	IdLoja     string     `json:"id_loja"`
+	// IdPlataforma só é retornado quando difere do ID enviado pelo cliente
+	IdPlataforma string `json:"id_plataforma,omitempty"`
+	Status       Status `json:"status"`
+	NomeFantasia string `json:"nome_fantasia"`
+}
+
+// Loja é a representação normalizada de uma loja, com os mesmos campos em todas as plataformas. É o
+// formato padrão de todas as respostas com lojas; ?formato=legado mantém o formato anterior
+type Loja struct {
+	Id string `json:"id"`
+	// IdPlataforma só é retornado quando difere do ID enviado pelo cliente
+	IdPlataforma string     `json:"id_plataforma,omitempty"`
+	Plataforma   Plataforma `json:"plataforma"`
+	// NomeFantasia, Documento e DocumentoTipo são omitidos quando a resposta não consulta o
+	// catálogo (resultados de operações em lote) ou a loja não foi encontrada
+	NomeFantasia string `json:"nome_fantasia,omitempty"`
+	Documento    string `json:"documento,omitempty"`
+	// DocumentoTipo é "cpf" ou "cnpj" conforme o tamanho do documento, e vazio quando ele não é nenhum dos dois
+	DocumentoTipo string `json:"documento_tipo,omitempty"`
+	Status        Status `json:"status"`
+	IsActive      bool   `json:"is_active"`
+	// Versao identifica o estado atual da loja (status e documento), para uso no header If-Match
+	Versao string `json:"versao,omitempty"`
+	// Detalhes só é retornado quando a consulta é feita com ?verbose=true
+	Detalhes *DetalhesStatusLoja `json:"detalhes,omitempty"`
+	// Observacao explica status que não vêm da plataforma, como o de um ID invalido
+	Observacao string `json:"observacao,omitempty"`
+}
+
+// RespostaLojas representa a consulta de status de múltiplas lojas no formato padrão
+type RespostaLojas struct {
+	Plataforma Plataforma `json:"plataforma"`
+	Lojas      []Loja     `json:"lojas"`
+	// CatalogoVazio indica que a plataforma não retornou nenhuma loja, e não que as lojas solicitadas não foram encontradas
+	CatalogoVazio bool `json:"catalogo_vazio,omitempty"`
+}
+
+// RespostaLojasPlataformas representa a consulta de status em todas as plataformas no formato padrão
+type RespostaLojasPlataformas map[Plataforma]ResultadoLojasPlataforma
+
+// ResultadoLojasPlataforma representa o resultado de uma plataforma na consulta de todas as
+// plataformas no formato padrão. Erro só é preenchido quando a plataforma falhou
+type ResultadoLojasPlataforma struct {
+	*RespostaLojas
+	Erro *RespostaErro `json:"erro,omitempty"`
+}
+
+// RespostaLoja representa a consulta de status de uma loja no formato padrão
+type RespostaLoja struct {
+	Plataforma Plataforma `json:"plataforma"`
+	Loja       Loja       `json:"loja"`
+	// Aguardado e StatusAlcancado só são retornados quando a consulta usa ?aguardar=
+	Aguardado       Status `json:"aguardado,omitempty"`
+	StatusAlcancado *bool  `json:"status_alcancado,omitempty"`
+	Tentativas      int    `json:"tentativas"`
 }
 
 // StatusLojaDetalhes representa os detalhes de status de uma loja específica
 type StatusLojaDetalhes struct {
-	IdLoja       string `json:"id_loja"`
+	IdLoja string `json:"id_loja"`
+	// IdPlataforma só é retornado quando difere do ID enviado pelo cliente
+	IdPlataforma string `json:"id_plataforma,omitempty"`
 	Status       Status `json:"status"`
 	Documento    string `json:"documento"`
 	NomeFantasia string `json:"nome_fantasia"`
+	// Versao identifica o estado atual da loja (status e documento), para uso no header If-Match
+	Versao string `json:"versao,omitempty"`
+	// Detalhes só é retornado quando a consulta é feita com ?verbose=true
+	Detalhes *DetalhesStatusLoja `json:"detalhes,omitempty"`
+	// Observacao explica status que não vêm da plataforma, como o de um ID invalido
+	Observacao string `json:"observacao,omitempty"`
+	// IsActive vem do catálogo e é usado no DTO Loja, sem depender de Detalhes
+	IsActive bool `json:"-"`
+}
+
+// DetalhesStatusLoja representa os dados brutos da plataforma usados para derivar o status
+type DetalhesStatusLoja struct {
+	IsActive         bool   `json:"is_active"`
+	StatusAssinatura string `json:"status_assinatura,omitempty"`
+	Bloqueado        *bool  `json:"bloqueado,omitempty"`
+	DocumentoValido  *bool  `json:"documento_valido,omitempty"`
+	// DadosIncompletos indica uma página do AnotaAI sem os dados do estabelecimento
+	DadosIncompletos bool `json:"dados_incompletos,omitempty"`
 }
 
-// StoreInfo representa informações completas de uma loja
+// StoreInfo representa informações completas de uma loja, normalizadas entre as plataformas.
+// É o formato retornado pelos serviços de cada plataforma e mantido no cache de status
 type StoreInfo struct {
 	Found        bool
 	IsActive     bool
 	Status       Status // Novo campo para armazenar o status específico
 	Documento    string
 	NomeFantasia string
+	// Campos brutos do DeliveryVip, preservados para diagnóstico
+	SubscriptionStatus string
+	Blocked            bool
+	// DocumentoValido indica se o documento tem o tamanho esperado para o tipo informado pela plataforma
+	DocumentoValido bool
+	// Flag "active" da página do AnotaAI. Falso indica página arquivada/removida,
+	// independente do status do estabelecimento (sign.active)
+	PageActive bool
+	// DadosIncompletos indica uma página do AnotaAI listada sem o bloco establishment.sign
+	DadosIncompletos bool
+}
+
+// RespostaStatusLoja representa a consulta de status de uma única loja, opcionalmente aguardando um status alvo
+type RespostaStatusLoja struct {
+	Plataforma Plataforma         `json:"plataforma"`
+	Loja       StatusLojaDetalhes `json:"loja"`
+	// Aguardado e StatusAlcancado só são retornados quando a consulta usa ?aguardar=
+	Aguardado       Status `json:"aguardado,omitempty"`
+	StatusAlcancado *bool  `json:"status_alcancado,omitempty"`
+	Tentativas      int    `json:"tentativas"`
+}
+
+// RespostaPing representa o resultado da verificação ativa de credenciais e conectividade de uma plataforma
+type RespostaPing struct {
+	Plataforma  Plataforma `json:"plataforma"`
+	Alcancavel  bool       `json:"alcancavel"`
+	Autenticado bool       `json:"autenticado"`
+	LatenciaMs  int64      `json:"latencia_ms"`
+	Mensagem    string     `json:"mensagem,omitempty"`
+}
+
+// RespostaPlataforma representa uma plataforma e as operações que ela suporta
+type RespostaPlataforma struct {
+	Plataforma Plataforma `json:"plataforma"`
+	Operacoes  []Operacao `json:"operacoes"`
+}
+
+// RespostaMapeamentoStatus representa a tabela usada para classificar o status bruto de uma
+// plataforma nos status da API
+type RespostaMapeamentoStatus struct {
+	Plataforma Plataforma `json:"plataforma"`
+	// Campo é o campo do payload da plataforma usado na classificação
+	Campo        string            `json:"campo"`
+	Mapeamento   map[string]Status `json:"mapeamento"`
+	StatusPadrao Status            `json:"status_padrao"`
+	Observacoes  []string          `json:"observacoes,omitempty"`
+}
+
+// RespostaLimpezaCache representa o resultado da limpeza manual do cache de status
+type RespostaLimpezaCache struct {
+	Plataformas    []Plataforma `json:"plataformas"`
+	LojasRemovidas int          `json:"lojas_removidas"`
 }
 
 // RespostaErro representa uma resposta de erro
 type RespostaErro struct {
-	Error    TipoErro `json:"error"`
-	Mensagem string   `json:"mensagem"`
+	Error     TipoErro `json:"error"`
+	Mensagem  string   `json:"mensagem"`
+	Timestamp string   `json:"timestamp,omitempty"`
+	RequestID string   `json:"request_id,omitempty"`
+	// Detalhes lista cada item rejeitado na validação, quando houver
+	Detalhes []DetalheValidacao `json:"detalhes,omitempty"`
+	// CodigoPlataforma é o código de erro original da plataforma (o código do corpo da resposta do
+	// DeliveryVip ou a mensagem do AnotaAI), presente apenas quando a plataforma o informou
+	CodigoPlataforma string `json:"codigo_plataforma,omitempty"`
+	// Chamada identifica a chamada à plataforma que falhou; só é retornada com ?verbose=true
+	Chamada *ChamadaPlataforma `json:"chamada,omitempty"`
+}
+
+// Motivos de rejeição de um ID de loja
+const (
+	MotivoIDVazio      = "vazio"
+	MotivoIDDuplicado  = "duplicado"
+	MotivoIDMuitoLongo = "muito_longo"
+)
+
+// DetalheValidacao identifica um ID de loja rejeitado e o motivo
+type DetalheValidacao struct {
+	// Indice é a posição do ID na lista enviada, começando em 0
+	Indice int    `json:"indice"`
+	IdLoja string `json:"id_loja"`
+	Motivo string `json:"motivo"`
 }
 
 // RespostaSaude representa a resposta do health check
 type RespostaSaude struct {
 	Status string `json:"status"`
+	// Manutencao indica se as operações de ativação e desativação estão suspensas
+	Manutencao bool `json:"manutencao"`
+}
+
+// RequisicaoManutencao ativa ou desativa o modo de manutenção
+type RequisicaoManutencao struct {
+	// Ativo é obrigatório; ponteiro para distinguir false de ausente
+	Ativo  *bool  `json:"ativo"`
+	Motivo string `json:"motivo,omitempty"`
+}
+
+// EstadoManutencao representa o estado do modo de manutenção
+type EstadoManutencao struct {
+	Ativo  bool   `json:"ativo"`
+	Motivo string `json:"motivo,omitempty"`
+	// Desde é o horário (UTC, RFC 3339) em que o modo foi ativado
+	Desde string `json:"desde,omitempty"`
+}
+
+// RespostaTesteWebhook representa o resultado do envio de um evento de teste ao webhook configurado
+type RespostaTesteWebhook struct {
+	URL        string `json:"url"`
+	Evento     string `json:"evento"`
+	Sucesso    bool   `json:"sucesso"`
+	StatusHTTP int    `json:"status_http,omitempty"`
+	Tentativas int    `json:"tentativas"`
+	LatenciaMs int64  `json:"latencia_ms"`
+	Erro       string `json:"erro,omitempty"`
 }
@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"slices"
+	"sync"
+
+	"delivery-control/internal/models"
+)
+
+// Limites dos repositórios em memória, que descartam os registros mais antigos ao atingi-los
+const (
+	memoryAuditLimit   = 10000
+	memoryJobLimit     = 1000
+	memoryHistoryLimit = 100 // por loja
+)
+
+// NewMemory cria repositórios em memória, perdidos ao reiniciar o processo
+func NewMemory() *Repositories {
+	return &Repositories{
+		Audit:   &memoryAuditStore{},
+		Jobs:    &memoryJobStore{lotes: make(map[string]models.Lote)},
+		History: &memoryHistoryStore{historico: make(map[historyKey][]models.RegistroStatus)},
+	}
+}
+
+// memoryAuditStore guarda os registros de auditoria em ordem de inserção
+type memoryAuditStore struct {
+	mu        sync.RWMutex
+	registros []models.RegistroAuditoria
+}
+
+func (s *memoryAuditStore) Record(registro models.RegistroAuditoria) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.registros = appendBounded(s.registros, registro, memoryAuditLimit)
+	return nil
+}
+
+func (s *memoryAuditStore) List(limite int) ([]models.RegistroAuditoria, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return newestFirst(s.registros, limite), nil
+}
+
+// memoryJobStore guarda os lotes por id, lembrando a ordem de criação para descartar os mais antigos
+type memoryJobStore struct {
+	mu    sync.RWMutex
+	lotes map[string]models.Lote
+	ordem []string
+}
+
+func (s *memoryJobStore) Save(lote models.Lote) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.lotes[lote.IdLote]; !exists {
+		s.ordem = append(s.ordem, lote.IdLote)
+		if len(s.ordem) > memoryJobLimit {
+			delete(s.lotes, s.ordem[0])
+			s.ordem = s.ordem[1:]
+		}
+	}
+	s.lotes[lote.IdLote] = lote
+	return nil
+}
+
+func (s *memoryJobStore) Get(idLote string) (models.Lote, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	lote, ok := s.lotes[idLote]
+	return lote, ok, nil
+}
+
+// historyKey identifica uma loja em uma plataforma
+type historyKey struct {
+	plataforma models.Plataforma
+	idLoja     string
+}
+
+// memoryHistoryStore guarda os últimos status de cada loja
+type memoryHistoryStore struct {
+	mu        sync.RWMutex
+	historico map[historyKey][]models.RegistroStatus
+}
+
+func (s *memoryHistoryStore) Append(plataforma models.Plataforma, idLoja string, registro models.RegistroStatus) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := historyKey{plataforma, idLoja}
+	s.historico[key] = appendBounded(s.historico[key], registro, memoryHistoryLimit)
+	return nil
+}
+
+func (s *memoryHistoryStore) List(plataforma models.Plataforma, idLoja string, limite int) ([]models.RegistroStatus, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return newestFirst(s.historico[historyKey{plataforma, idLoja}], limite), nil
+}
+
+// appendBounded adiciona o item descartando os mais antigos além do limite
+func appendBounded[T any](itens []T, item T, limite int) []T {
+	itens = append(itens, item)
+	if len(itens) > limite {
+		itens = slices.Clone(itens[len(itens)-limite:])
+	}
+	return itens
+}
+
+// newestFirst retorna uma cópia dos itens do mais recente para o mais antigo, até limite (0 retorna todos)
+func newestFirst[T any](itens []T, limite int) []T {
+	resultado := slices.Clone(itens)
+	slices.Reverse(resultado)
+	if limite > 0 && len(resultado) > limite {
+		resultado = resultado[:limite]
+	}
+	return resultado
+}
@@ -0,0 +1,52 @@
+// Package repository define os repositórios usados pelos recursos que precisam guardar estado
+// (auditoria, lotes e histórico de status). A implementação é escolhida por STORAGE_BACKEND,
+// sem que services e handlers dependam dela
+package repository
+
+import (
+	"fmt"
+
+	"delivery-control/internal/config"
+	"delivery-control/internal/models"
+)
+
+// AuditStore guarda as operações executadas nas lojas
+type AuditStore interface {
+	// Record adiciona um registro de auditoria
+	Record(registro models.RegistroAuditoria) error
+	// List retorna os registros mais recentes primeiro, até limite (0 retorna todos)
+	List(limite int) ([]models.RegistroAuditoria, error)
+}
+
+// JobStore guarda as operações em lote pelo id do lote
+type JobStore interface {
+	// Save cria ou substitui o lote
+	Save(lote models.Lote) error
+	// Get retorna o lote e se ele foi encontrado
+	Get(idLote string) (models.Lote, bool, error)
+}
+
+// HistoryStore guarda os status observados de cada loja ao longo do tempo
+type HistoryStore interface {
+	// Append adiciona um status ao histórico da loja
+	Append(plataforma models.Plataforma, idLoja string, registro models.RegistroStatus) error
+	// List retorna o histórico da loja, os registros mais recentes primeiro, até limite (0 retorna todos)
+	List(plataforma models.Plataforma, idLoja string, limite int) ([]models.RegistroStatus, error)
+}
+
+// Repositories agrupa os repositórios da aplicação
+type Repositories struct {
+	Audit   AuditStore
+	Jobs    JobStore
+	History HistoryStore
+}
+
+// New cria os repositórios do backend configurado em STORAGE_BACKEND
+func New(cfg config.StorageConfig) (*Repositories, error) {
+	switch cfg.Backend {
+	case config.StorageMemoria:
+		return NewMemory(), nil
+	default:
+		return nil, fmt.Errorf("backend de armazenamento não suportado: %s", cfg.Backend)
+	}
+}
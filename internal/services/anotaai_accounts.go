@@ -0,0 +1,177 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"delivery-control/internal/config"
+	"delivery-control/internal/models"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// AnotaAiAccounts distribui as operações do AnotaAI entre as contas de parceiro configuradas.
+// Cada conta mantém o próprio token; a conta responsável por cada loja é descoberta pela
+// listagem de páginas e mantida em um índice em memória
+type AnotaAiAccounts struct {
+	accounts []*AnotaAiService
+
+	mu         sync.RWMutex
+	storeIndex map[string]*AnotaAiService
+	indexedAt  time.Time
+}
+
+// storeIndexMinAge evita recarregar o índice a cada loja desconhecida em operações em lote
+const storeIndexMinAge = 30 * time.Second
+
+// NewAnotaAiAccounts cria um serviço AnotaAI para cada conta configurada
+func NewAnotaAiAccounts(cfg *config.Config) *AnotaAiAccounts {
+	accounts := make([]*AnotaAiService, 0, len(cfg.Platforms.AnotaAi.Accounts)+1)
+	for _, account := range cfg.Platforms.AnotaAi.AllAccounts() {
+		accounts = append(accounts, NewAnotaAiService(cfg, account))
+	}
+
+	return &AnotaAiAccounts{
+		accounts:   accounts,
+		storeIndex: make(map[string]*AnotaAiService),
+	}
+}
+
+// ActivateStore ativa a loja usando a conta responsável por ela
+func (a *AnotaAiAccounts) ActivateStore(ctx context.Context, idLoja string) error {
+	account, err := a.accountFor(idLoja)
+	if err != nil {
+		return err
+	}
+	return account.ActivateStore(ctx, idLoja)
+}
+
+// DeactivateStore desativa a loja usando a conta responsável por ela
+func (a *AnotaAiAccounts) DeactivateStore(ctx context.Context, idLoja string) error {
+	account, err := a.accountFor(idLoja)
+	if err != nil {
+		return err
+	}
+	return account.DeactivateStore(ctx, idLoja)
+}
+
+// GetRawPage retorna o JSON bruto da página na conta responsável pela loja
+func (a *AnotaAiAccounts) GetRawPage(idLoja string) (json.RawMessage, error) {
+	account, err := a.accountFor(idLoja)
+	if err != nil {
+		if len(a.accounts) > 1 {
+			return nil, fmt.Errorf("%w: %v", ErrLojaNaoEncontrada, err)
+		}
+		return nil, err
+	}
+	return account.GetRawPage(idLoja)
+}
+
+// Ping valida todas as contas, retornando o primeiro status diferente de 200 recebido
+func (a *AnotaAiAccounts) Ping() (int, error) {
+	for _, account := range a.accounts {
+		statusCode, err := account.Ping()
+		if err != nil {
+			return 0, fmt.Errorf("conta %s: %w", account.account.Name, err)
+		}
+		if statusCode != http.StatusOK {
+			return statusCode, nil
+		}
+	}
+	return http.StatusOK, nil
+}
+
+// GetMultipleStoreStatus consulta o catálogo de todas as contas concorrentemente e os combina.
+// Falha se qualquer conta falhar, já que um catálogo parcial reportaria lojas existentes como
+// não encontradas. Se idsLojas for nil ou vazio, retorna o status de todas as lojas
+func (a *AnotaAiAccounts) GetMultipleStoreStatus(idsLojas []string) (map[string]models.StoreInfo, error) {
+	catalogs := make([]map[string]models.StoreInfo, len(a.accounts))
+
+	var g errgroup.Group
+	for i, account := range a.accounts {
+		g.Go(func() error {
+			lojas, err := account.GetMultipleStoreStatus(nil)
+			if err != nil {
+				if len(a.accounts) > 1 {
+					return fmt.Errorf("conta %s: %w", account.account.Name, err)
+				}
+				return err
+			}
+			catalogs[i] = lojas
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	storeMap := make(map[string]models.StoreInfo)
+	index := make(map[string]*AnotaAiService)
+	for i, lojas := range catalogs {
+		for idLoja, storeInfo := range lojas {
+			if _, exists := index[idLoja]; exists {
+				log.Printf("[AnotaAI] AVISO: loja %s encontrada em mais de uma conta, usando a conta %s", idLoja, index[idLoja].account.Name)
+				continue
+			}
+			index[idLoja] = a.accounts[i]
+			storeMap[idLoja] = storeInfo
+		}
+	}
+
+	a.mu.Lock()
+	a.storeIndex = index
+	a.indexedAt = time.Now()
+	a.mu.Unlock()
+
+	if len(idsLojas) == 0 {
+		return storeMap, nil
+	}
+
+	filtered := make(map[string]models.StoreInfo, len(idsLojas))
+	for _, idLoja := range idsLojas {
+		storeInfo, exists := storeMap[idLoja]
+		if !exists {
+			storeInfo = models.StoreInfo{Status: models.StatusNaoEncontrado}
+		}
+		filtered[idLoja] = storeInfo
+	}
+	return filtered, nil
+}
+
+// accountFor retorna a conta responsável pela loja. Com uma única conta não há o que
+// descobrir; com várias, atualiza o índice pela listagem quando a loja ainda não é conhecida
+func (a *AnotaAiAccounts) accountFor(idLoja string) (*AnotaAiService, error) {
+	if len(a.accounts) == 1 {
+		return a.accounts[0], nil
+	}
+
+	account, ok, fresh := a.lookup(idLoja)
+	if ok {
+		return account, nil
+	}
+
+	if fresh {
+		return nil, fmt.Errorf("loja não encontrada em nenhuma conta do AnotaAI: %s", idLoja)
+	}
+	if _, err := a.GetMultipleStoreStatus(nil); err != nil {
+		return nil, fmt.Errorf("erro ao identificar a conta da loja %s: %w", idLoja, err)
+	}
+
+	if account, ok, _ := a.lookup(idLoja); ok {
+		return account, nil
+	}
+	return nil, fmt.Errorf("loja não encontrada em nenhuma conta do AnotaAI: %s", idLoja)
+}
+
+// lookup consulta o índice de lojas por conta, informando também se o índice é recente
+func (a *AnotaAiAccounts) lookup(idLoja string) (*AnotaAiService, bool, bool) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	account, ok := a.storeIndex[idLoja]
+	return account, ok, time.Since(a.indexedAt) < storeIndexMinAge
+}
@@ -2,24 +2,89 @@ package services
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
+	"strings"
 	"sync"
 	"time"
 
 	"delivery-control/internal/config"
+	"delivery-control/internal/metrics"
 	"delivery-control/internal/models"
 	"delivery-control/internal/utils"
 )
 
-// AnotaAiService gerencia a integração com AnotaAI
+// AnotaAiError representa um erro reportado pela API AnotaAI
+type AnotaAiError struct {
+	HTTPStatus int
+	TipoErro   models.TipoErro
+	Mensagem   string
+	// MensagemPlataforma é a mensagem original da resposta do AnotaAI, que não informa códigos de erro
+	MensagemPlataforma string
+}
+
+func (e *AnotaAiError) Error() string {
+	return e.Mensagem
+}
+
+// NewAnotaAiError cria um erro do AnotaAI classificando a mensagem retornada pela plataforma
+func NewAnotaAiError(httpStatus int, operacao, mensagemPlataforma string) error {
+	mensagem := fmt.Sprintf("%s falhou", operacao)
+	if mensagemPlataforma != "" {
+		mensagem = fmt.Sprintf("%s falhou: %s", operacao, mensagemPlataforma)
+	}
+
+	return &AnotaAiError{
+		HTTPStatus:         httpStatus,
+		TipoErro:           classifyAnotaAiMessage(httpStatus, mensagemPlataforma),
+		Mensagem:           mensagem,
+		MensagemPlataforma: mensagemPlataforma,
+	}
+}
+
+// classifyAnotaAiMessage mapeia o status HTTP e as mensagens de falha comuns do AnotaAI para o TipoErro
+func classifyAnotaAiMessage(httpStatus int, mensagem string) models.TipoErro {
+	switch httpStatus {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return models.ErroNaoAutorizado
+	case http.StatusNotFound:
+		return models.ErroNaoEncontrado
+	}
+
+	lower := strings.ToLower(mensagem)
+	switch {
+	case containsAny(lower, "token", "unauthorized", "não autorizado", "nao autorizado", "expired", "expirado", "forbidden", "permiss"):
+		return models.ErroNaoAutorizado
+	case containsAny(lower, "not found", "não encontrad", "nao encontrad"):
+		return models.ErroNaoEncontrado
+	default:
+		return models.ErroBadGateway
+	}
+}
+
+// containsAny verifica se o texto contém algum dos trechos informados
+func containsAny(text string, substrs ...string) bool {
+	for _, substr := range substrs {
+		if strings.Contains(text, substr) {
+			return true
+		}
+	}
+	return false
+}
+
+// AnotaAiService gerencia a integração com AnotaAI usando uma conta de parceiro
 type AnotaAiService struct {
 	config      *config.Config
+	account     config.AnotaAiAccount
+	logPrefix   string
 	accessToken string
 	tokenMutex  sync.RWMutex
+	tokenReady  *tokenReady
 	httpClient  *http.Client
 }
 
@@ -35,19 +100,20 @@ type LoginResponse struct {
 	AccessToken string `json:"access_token"`
 }
 
-// AnotaAiResponse representa uma resposta padrão do AnotaAI
-type AnotaAiResponse struct {
+// AnotaAiListPagesResponse representa a resposta da API de listagem de páginas
+type AnotaAiListPagesResponse = anotaAiListPages[AnotaAiPage]
+
+// anotaAiListPages representa a listagem de páginas com os documentos decodificados em T,
+// permitindo obter tanto as páginas tipadas quanto o JSON bruto de cada uma
+type anotaAiListPages[T any] struct {
 	Success  bool   `json:"success"`
+	Message  string `json:"message"`
 	Mensagem string `json:"mensagem"`
-}
-
-// AnotaAiListPagesResponse representa a resposta da API de listagem de páginas
-type AnotaAiListPagesResponse struct {
-	Success bool `json:"success"`
-	Info    struct {
-		Docs  []AnotaAiPage `json:"docs"`
-		Limit int           `json:"limit"`
-		Page  int           `json:"page"`
+	Info     struct {
+		// Páginas malformadas são descartadas individualmente, sem invalidar o catálogo
+		Docs  tolerantList[T] `json:"docs"`
+		Limit int             `json:"limit"`
+		Page  int             `json:"page"`
 	} `json:"info"`
 }
 
@@ -91,77 +157,109 @@ func (c *CpfCnpjField) GetValue() string {
 	return c.Raw
 }
 
-// AnotaAiPage representa uma página/loja na resposta da API
+// AnotaAiPage representa uma página/loja na resposta da API.
+// Active indica se a página existe para o AnotaAI (falso em páginas arquivadas/removidas que
+// continuam sendo listadas), enquanto Page.Establishment.Sign.Active é o status da assinatura
+// do estabelecimento, alterado pelos endpoints de ativação e bloqueio. Páginas com o cadastro
+// incompleto são listadas sem establishment ou sem sign
 type AnotaAiPage struct {
 	ID       string `json:"_id"`
 	PageID   string `json:"page_id"`
 	PageName string `json:"page_name"`
 	Active   bool   `json:"active"`
 	Page     struct {
-		Establishment struct {
-			Sign struct {
-				Active  bool         `json:"active"`
-				CpfCnpj CpfCnpjField `json:"cpf_cnpj"`
-			} `json:"sign"`
+		Establishment *struct {
+			Sign *AnotaAiSign `json:"sign"`
 		} `json:"establishment"`
 	} `json:"page"`
 }
 
-// NewAnotaAiService cria um novo serviço AnotaAI
-func NewAnotaAiService(cfg *config.Config) *AnotaAiService {
+// AnotaAiSign representa a assinatura do estabelecimento de uma página
+type AnotaAiSign struct {
+	Active  bool         `json:"active"`
+	CpfCnpj CpfCnpjField `json:"cpf_cnpj"`
+}
+
+// sign retorna a assinatura do estabelecimento, ou nil quando a página não traz esses dados
+func (p AnotaAiPage) sign() *AnotaAiSign {
+	if p.Page.Establishment == nil {
+		return nil
+	}
+	return p.Page.Establishment.Sign
+}
+
+// NewAnotaAiService cria um novo serviço AnotaAI autenticado com a conta informada
+func NewAnotaAiService(cfg *config.Config, account config.AnotaAiAccount) *AnotaAiService {
+	logPrefix := "[AnotaAI]"
+	if account.Name != config.AnotaAiDefaultAccount {
+		logPrefix = fmt.Sprintf("[AnotaAI:%s]", account.Name)
+	}
+
 	service := &AnotaAiService{
-		config: cfg,
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+		config:     cfg,
+		account:    account,
+		logPrefix:  logPrefix,
+		tokenReady: newTokenReady(),
+		httpClient: newPlatformClient(cfg.Platforms.AnotaAi.ExtraHeaders),
 	}
 
 	// Inicia a rotina de renovação de token
+	metrics.RegisterToken(string(models.PlataformaAnotaAi), account.Name)
 	go service.startTokenRenewal()
 
 	return service
 }
 
-// startTokenRenewal inicia a rotina que renova o token a cada 3 horas
+// startTokenRenewal inicia a rotina que renova o token no intervalo configurado (padrão 3 horas)
 func (s *AnotaAiService) startTokenRenewal() {
-	log.Printf("[AnotaAI] Iniciando serviço de renovação de token...")
-	log.Printf("[AnotaAI] URL configurada: %s", s.config.Platforms.AnotaAiURL)
+	log.Printf("%s Iniciando serviço de renovação de token...", s.logPrefix)
+	log.Printf("%s URL configurada: %s", s.logPrefix, s.config.Platforms.AnotaAiURL)
 
 	// Faz o primeiro login imediatamente
-	log.Printf("[AnotaAI] Tentando login inicial...")
-	if err := s.renewToken(); err != nil {
-		log.Printf("[AnotaAI] ERRO no login inicial: %v", err)
+	log.Printf("%s Tentando login inicial...", s.logPrefix)
+	if err := s.renewTokenWithRetry(); err != nil {
+		log.Printf("%s ERRO no login inicial: %v", s.logPrefix, err)
 	} else {
-		log.Printf("[AnotaAI] Login inicial realizado com sucesso!")
+		log.Printf("%s Login inicial realizado com sucesso!", s.logPrefix)
 	}
 
-	// Configura renovação a cada 3 horas
-	ticker := time.NewTicker(3 * time.Hour)
+	// Configura renovação periódica (padrão 3 horas), com variação aleatória por instância
+	// para espalhar as renovações entre réplicas
+	renewalInterval := jitteredInterval(s.config.Platforms.AnotaAi.TokenRenewal)
+	log.Printf("%s Intervalo de renovação de token: %s", s.logPrefix, renewalInterval.Round(time.Second))
+	ticker := time.NewTicker(renewalInterval)
 	defer ticker.Stop()
 
 	for range ticker.C {
-		log.Printf("[AnotaAI] Renovando token automaticamente...")
-		if err := s.renewToken(); err != nil {
-			log.Printf("[AnotaAI] ERRO ao renovar token: %v", err)
+		log.Printf("%s Renovando token automaticamente...", s.logPrefix)
+		if err := s.renewTokenWithRetry(); err != nil {
+			log.Printf("%s ERRO ao renovar token: %v", s.logPrefix, err)
 		}
 	}
 }
 
-// renewToken renova o token de acesso
-func (s *AnotaAiService) renewToken() error {
-	log.Printf("[AnotaAI] Iniciando processo de renovação de token...")
+// renewTokenWithRetry renova o token, tentando novamente enquanto o login estiver indisponível
+func (s *AnotaAiService) renewTokenWithRetry() error {
+	return renewWithRetry(s.logPrefix, loginRetryDelay, s.renewToken)
+}
+
+// renewToken renova o token de acesso, registrando o resultado nas métricas
+func (s *AnotaAiService) renewToken() (err error) {
+	defer func() { metrics.RecordTokenRenewal(string(models.PlataformaAnotaAi), s.account.Name, err) }()
+
+	log.Printf("%s Iniciando processo de renovação de token...", s.logPrefix)
 
 	// Verifica se as credenciais estão configuradas
-	if s.config.Platforms.AnotaAi.Email == "" {
-		return fmt.Errorf("email do AnotaAI não configurado")
+	if s.account.Email == "" {
+		return fmt.Errorf("email da conta %s do AnotaAI não configurado", s.account.Name)
 	}
-	if s.config.Platforms.AnotaAi.Password == "" {
-		return fmt.Errorf("senha do AnotaAI não configurada")
+	if s.account.Password == "" {
+		return fmt.Errorf("senha da conta %s do AnotaAI não configurada", s.account.Name)
 	}
 
 	loginReq := LoginRequest{
-		Email:    s.config.Platforms.AnotaAi.Email,
-		Password: s.config.Platforms.AnotaAi.Password,
+		Email:    s.account.Email,
+		Password: s.account.Password,
 	}
 
 	payload, err := json.Marshal(loginReq)
@@ -171,38 +269,36 @@ func (s *AnotaAiService) renewToken() error {
 
 	url := fmt.Sprintf("%s/noauth/partner/login", s.config.Platforms.AnotaAiURL)
 
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(payload))
+	// O login tem um timeout próprio, menor que o do cliente, para que uma conexão travada
+	// não atrase a disponibilidade do token
+	ctx, cancel := context.WithTimeout(context.Background(), s.config.Platforms.AnotaAi.LoginTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(payload))
 	if err != nil {
 		return fmt.Errorf("erro ao criar requisição de login: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
 
-	log.Printf("[AnotaAI] Enviando requisição de login...")
+	log.Printf("%s Enviando requisição de login...", s.logPrefix)
 	resp, err := s.httpClient.Do(req)
 	if err != nil {
 		return fmt.Errorf("erro na requisição de login: %w", err)
 	}
 	defer resp.Body.Close()
 
-	// Lê o corpo da resposta para debug
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return fmt.Errorf("erro ao ler corpo da resposta: %w", err)
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		log.Printf("[AnotaAI] Corpo da resposta (erro): %s", string(body))
-		return fmt.Errorf("erro no login - status: %d, resposta: %s", resp.StatusCode, string(body))
+	if err := checkLoginResponse(resp); err != nil {
+		return fmt.Errorf("erro no login: %w", err)
 	}
 
 	var loginResp LoginResponse
-	if err := json.Unmarshal(body, &loginResp); err != nil {
+	if err := decodeLoginResponse(resp, &loginResp); err != nil {
 		return fmt.Errorf("erro ao decodificar resposta de login: %w", err)
 	}
 
 	if loginResp.AccessToken != "" {
-		log.Printf("[AnotaAI] Token recebido com sucesso")
+		log.Printf("%s Token recebido com sucesso", s.logPrefix)
 	}
 
 	if !loginResp.Success {
@@ -213,8 +309,9 @@ func (s *AnotaAiService) renewToken() error {
 	s.tokenMutex.Lock()
 	s.accessToken = loginResp.AccessToken
 	s.tokenMutex.Unlock()
+	s.tokenReady.notify()
 
-	log.Printf("[AnotaAI] Token AnotaAI renovado com sucesso às %s", time.Now().Format("2006-01-02 15:04:05"))
+	log.Printf("%s Token AnotaAI renovado com sucesso às %s", s.logPrefix, time.Now().Format("2006-01-02 15:04:05"))
 	return nil
 }
 
@@ -225,113 +322,278 @@ func (s *AnotaAiService) getAccessToken() string {
 	return s.accessToken
 }
 
+// waitAccessToken retorna o token de acesso, aguardando até TOKEN_WAIT_TIMEOUT pelo primeiro
+// login quando ele ainda não está disponível. Com TOKEN_WAIT_TIMEOUT=0 a renovação é feita
+// na hora, dentro da requisição
+func (s *AnotaAiService) waitAccessToken() string {
+	if token := s.getAccessToken(); token != "" {
+		return token
+	}
+	if s.config.Platforms.TokenWaitTimeout == 0 {
+		if err := s.tokenReady.renewNow(s.renewToken); err != nil {
+			log.Printf("%s ERRO na renovação síncrona do token: %v", s.logPrefix, err)
+			return ""
+		}
+		return s.getAccessToken()
+	}
+	if s.tokenReady.wait(s.config.Platforms.TokenWaitTimeout) {
+		return s.getAccessToken()
+	}
+	return ""
+}
+
+// setTokenHeader envia o token no header e formato declarados em TokenHeader
+func (s *AnotaAiService) setTokenHeader(req *http.Request, token string) {
+	tokenHeader := s.config.Platforms.AnotaAi.TokenHeader
+	req.Header.Set(tokenHeader.Name, tokenHeader.Value(token))
+}
+
 // ActivateStore ativa uma loja no AnotaAI
-func (s *AnotaAiService) ActivateStore(idLoja string) error {
-	token := s.getAccessToken()
+func (s *AnotaAiService) ActivateStore(ctx context.Context, idLoja string) error {
+	if err := checkStoreID(idLoja); err != nil {
+		return err
+	}
+
+	token := s.waitAccessToken()
 	if token == "" {
-		return fmt.Errorf("token de acesso não disponível")
+		return errTokenIndisponivel()
 	}
 
+	ctx, cancel := context.WithTimeout(ctx, s.config.Platforms.AnotaAi.MutationTimeout)
+	defer cancel()
+
 	url := fmt.Sprintf("%s/partnerauth/partner/active/%s", s.config.Platforms.AnotaAiURL, idLoja)
-	req, err := http.NewRequest("PUT", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "PUT", url, nil)
 	if err != nil {
 		return fmt.Errorf("erro ao criar requisição de ativação: %w", err)
 	}
 
-	req.Header.Set("authorization", token)
+	s.setTokenHeader(req, token)
 
 	resp, err := s.httpClient.Do(req)
 	if err != nil {
-		return fmt.Errorf("erro na requisição de ativação: %w", err)
+		return wrapCall(models.EtapaOperacao, req, 0, fmt.Errorf("erro na requisição de ativação: %w", err))
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("erro na ativação - status: %d", resp.StatusCode)
-	}
-
-	var anotaResp AnotaAiResponse
-	if err := json.NewDecoder(resp.Body).Decode(&anotaResp); err != nil {
-		return fmt.Errorf("erro ao decodificar resposta de ativação: %w", err)
-	}
-
-	if !anotaResp.Success {
-		return fmt.Errorf("ativação falhou: %s", anotaResp.Mensagem)
-	}
-
-	return nil
+	return wrapCall(models.EtapaOperacao, req, resp.StatusCode, checkUpdateResponse(resp, "ativação", s.config.Platforms.AnotaAi.Responses))
 }
 
 // DeactivateStore desativa uma loja no AnotaAI
-func (s *AnotaAiService) DeactivateStore(idLoja string) error {
-	token := s.getAccessToken()
+func (s *AnotaAiService) DeactivateStore(ctx context.Context, idLoja string) error {
+	if err := checkStoreID(idLoja); err != nil {
+		return err
+	}
+
+	token := s.waitAccessToken()
 	if token == "" {
-		return fmt.Errorf("token de acesso não disponível")
+		return errTokenIndisponivel()
 	}
 
+	ctx, cancel := context.WithTimeout(ctx, s.config.Platforms.AnotaAi.MutationTimeout)
+	defer cancel()
+
 	url := fmt.Sprintf("%s/partnerauth/partner/block/%s", s.config.Platforms.AnotaAiURL, idLoja)
-	req, err := http.NewRequest("PUT", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "PUT", url, nil)
 	if err != nil {
 		return fmt.Errorf("erro ao criar requisição de desativação: %w", err)
 	}
 
-	req.Header.Set("authorization", token)
+	s.setTokenHeader(req, token)
 
 	resp, err := s.httpClient.Do(req)
 	if err != nil {
-		return fmt.Errorf("erro na requisição de desativação: %w", err)
+		return wrapCall(models.EtapaOperacao, req, 0, fmt.Errorf("erro na requisição de desativação: %w", err))
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("erro na desativação - status: %d", resp.StatusCode)
+	return wrapCall(models.EtapaOperacao, req, resp.StatusCode, checkUpdateResponse(resp, "desativação", s.config.Platforms.AnotaAi.Responses))
+}
+
+// checkUpdateResponse valida a resposta de ativação/desativação conforme a semântica de respostas
+// do AnotaAI (por padrão, qualquer 2xx). O corpo só é decodificado quando existe, e nesse caso o campo de
+// sucesso precisa ser true. Respostas de sucesso sem corpo (ex.: 204 No Content) são tratadas como sucesso
+func checkUpdateResponse(resp *http.Response, operacao string, semantics config.ResponseSemantics) error {
+	if !semantics.IsSuccessStatus(resp.StatusCode) {
+		// A mensagem do corpo é lida apenas como detalhe; uma página HTML de erro fica sem mensagem
+		var body map[string]any
+		_ = decodeJSON(resp, &body)
+		return NewAnotaAiError(resp.StatusCode, fmt.Sprintf("%s (status %d)", operacao, resp.StatusCode), firstJSONField(body, semantics.ErrorFields))
+	}
+	if semantics.SuccessField == "" || !hasBody(resp) {
+		return nil
 	}
 
-	var anotaResp AnotaAiResponse
-	if err := json.NewDecoder(resp.Body).Decode(&anotaResp); err != nil {
-		return fmt.Errorf("erro ao decodificar resposta de desativação: %w", err)
+	var body map[string]any
+	if err := decodeJSON(resp, &body); err != nil {
+		if errors.Is(err, errRespostaSemConteudo) {
+			return nil
+		}
+		return fmt.Errorf("erro ao decodificar resposta de %s: %w", operacao, err)
 	}
 
-	if !anotaResp.Success {
-		return fmt.Errorf("desativação falhou: %s", anotaResp.Mensagem)
+	if sucesso, _ := jsonField(body, semantics.SuccessField); sucesso != true {
+		return NewAnotaAiError(resp.StatusCode, operacao, firstJSONField(body, semantics.ErrorFields))
 	}
 
 	return nil
 }
 
-// GetMultipleStoreStatus obtém o status de múltiplas lojas no AnotaAI
-// Se idsLojas for nil ou vazio, retorna o status de todas as lojas
-func (s *AnotaAiService) GetMultipleStoreStatus(idsLojas []string) (map[string]models.StoreInfo, error) {
-	token := s.getAccessToken()
+// Ping faz uma listagem mínima de páginas para validar o token e a conectividade,
+// retornando o status HTTP recebido da plataforma
+func (s *AnotaAiService) Ping() (int, error) {
+	token := s.waitAccessToken()
 	if token == "" {
-		return nil, fmt.Errorf("token de acesso não disponível")
+		return 0, errTokenIndisponivel()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.config.Platforms.AnotaAi.StatusTimeout)
+	defer cancel()
+
+	url := fmt.Sprintf("%s/partnerauth/partner/listpages/v2?limit=1&page=1", s.config.Platforms.AnotaAiURL)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return 0, fmt.Errorf("erro ao criar requisição de ping: %w", err)
+	}
+
+	s.setTokenHeader(req, token)
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("erro na requisição de ping: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	return resp.StatusCode, nil
+}
+
+// anotaAiPageStatus combina os flags da página e do estabelecimento. No AnotaAI só existe
+// ativo e bloqueado, e a loja só é considerada ativa quando a página (active) e a assinatura
+// do estabelecimento (sign.active) estão ativas - uma página arquivada com sign.active=true
+// não está de fato funcionando. Uma página ativa sem os dados do estabelecimento ainda está
+// com o cadastro em andamento e é reportada com statusIncompleto (ANOTAAI_INCOMPLETE_STATUS)
+func anotaAiPageStatus(page AnotaAiPage, statusIncompleto models.Status) (models.Status, bool) {
+	sign := page.sign()
+	if page.Active && sign == nil {
+		return statusIncompleto, false
+	}
+	if page.Active && sign.Active {
+		return models.StatusAtivo, true
+	}
+	return models.StatusBloqueado, false
+}
+
+// storeInfoFromPage monta as informações da loja a partir da página listada pelo AnotaAI
+func (s *AnotaAiService) storeInfoFromPage(page AnotaAiPage) models.StoreInfo {
+	status, isActive := anotaAiPageStatus(page, models.Status(s.config.Platforms.AnotaAi.IncompleteStatus))
+	documento, documentoValido := anotaAiPageDocument(page)
+	return models.StoreInfo{
+		Found:            true,
+		IsActive:         isActive,
+		Status:           status,
+		Documento:        documento,
+		DocumentoValido:  documentoValido,
+		NomeFantasia:     page.PageName,
+		PageActive:       page.Active,
+		DadosIncompletos: page.sign() == nil,
+	}
+}
+
+// anotaAiPageDocument limpa o documento da página e o valida usando o tipo (cpf/cnpj) informado
+// pelo AnotaAI, registrando em log documentos incompatíveis com o tipo declarado
+func anotaAiPageDocument(page AnotaAiPage) (string, bool) {
+	sign := page.sign()
+	if sign == nil {
+		return "", false
+	}
+	cpfCnpj := sign.CpfCnpj
+	documento := utils.CleanDocument(cpfCnpj.GetValue())
+	valido := utils.IsValidDocument(documento, cpfCnpj.Type)
+	if !valido && documento != "" {
+		log.Printf("[AnotaAI] AVISO: documento da página %s com %d dígitos não corresponde ao tipo %q", page.PageID, len(documento), cpfCnpj.Type)
 	}
+	return documento, valido
+}
+
+// listAnotaAiPages busca a listagem completa de páginas da conta, decodificando cada página em T
+func listAnotaAiPages[T any](s *AnotaAiService, token string) ([]T, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.config.Platforms.AnotaAi.StatusTimeout)
+	defer cancel()
 
 	url := fmt.Sprintf("%s/partnerauth/partner/listpages/v2?limit=2000&page=1", s.config.Platforms.AnotaAiURL)
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("erro ao criar requisição de status: %w", err)
 	}
 
-	req.Header.Set("authorization", token)
+	s.setTokenHeader(req, token)
 
 	resp, err := s.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("erro na requisição de status: %w", err)
+		return nil, wrapCall(models.EtapaStatus, req, 0, fmt.Errorf("erro na requisição de status: %w", err))
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("erro na consulta de status - status: %d", resp.StatusCode)
+		return nil, wrapCall(models.EtapaStatus, req, resp.StatusCode, fmt.Errorf("erro na consulta de status - status: %d", resp.StatusCode))
 	}
 
-	var listResp AnotaAiListPagesResponse
-	if err := json.NewDecoder(resp.Body).Decode(&listResp); err != nil {
-		return nil, fmt.Errorf("erro ao decodificar resposta de status: %w", err)
+	var listResp anotaAiListPages[T]
+	if err := decodeJSON(resp, &listResp); err != nil {
+		return nil, wrapCall(models.EtapaStatus, req, resp.StatusCode, fmt.Errorf("erro ao decodificar resposta de status: %w", err))
 	}
 
 	if !listResp.Success {
-		return nil, fmt.Errorf("consulta de status falhou - success: false")
+		mensagem := listResp.Mensagem
+		if mensagem == "" {
+			mensagem = listResp.Message
+		}
+		return nil, wrapCall(models.EtapaStatus, req, resp.StatusCode, NewAnotaAiError(resp.StatusCode, "consulta de status", mensagem))
+	}
+
+	for _, skipped := range listResp.Info.Docs.Skipped {
+		log.Printf("[AnotaAI] AVISO: item %d da listagem de páginas da conta %s descartado por estar malformado: %v", skipped.Indice, s.account.Name, skipped.Err)
+	}
+
+	return listResp.Info.Docs.Items, nil
+}
+
+// GetRawPage retorna o JSON da página exatamente como listado pelo AnotaAI, para diagnóstico.
+// Retorna ErrLojaNaoEncontrada se a página não estiver na listagem da conta
+func (s *AnotaAiService) GetRawPage(idLoja string) (json.RawMessage, error) {
+	token := s.waitAccessToken()
+	if token == "" {
+		return nil, errTokenIndisponivel()
+	}
+
+	pages, err := listAnotaAiPages[json.RawMessage](s, token)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, raw := range pages {
+		var page struct {
+			PageID string `json:"page_id"`
+		}
+		if err := json.Unmarshal(raw, &page); err == nil && page.PageID == idLoja {
+			return raw, nil
+		}
+	}
+	return nil, ErrLojaNaoEncontrada
+}
+
+// GetMultipleStoreStatus obtém o status de múltiplas lojas no AnotaAI
+// Se idsLojas for nil ou vazio, retorna o status de todas as lojas
+func (s *AnotaAiService) GetMultipleStoreStatus(idsLojas []string) (map[string]models.StoreInfo, error) {
+	token := s.waitAccessToken()
+	if token == "" {
+		return nil, errTokenIndisponivel()
+	}
+
+	pages, err := listAnotaAiPages[AnotaAiPage](s, token)
+	if err != nil {
+		return nil, err
 	}
 
 	// Mapa para armazenar as informações das lojas
@@ -339,22 +601,8 @@ func (s *AnotaAiService) GetMultipleStoreStatus(idsLojas []string) (map[string]m
 
 	// Se nenhum ID específico foi solicitado, retorna todas as lojas
 	if len(idsLojas) == 0 {
-		for _, page := range listResp.Info.Docs {
-			// No AnotaAI só existe ativo e bloqueado
-			var status models.Status
-			if page.Page.Establishment.Sign.Active {
-				status = models.StatusAtivo
-			} else {
-				status = models.StatusBloqueado
-			}
-
-			storeMap[page.PageID] = models.StoreInfo{
-				Found:        true,
-				IsActive:     page.Page.Establishment.Sign.Active,
-				Status:       status,
-				Documento:    utils.CleanDocument(page.Page.Establishment.Sign.CpfCnpj.GetValue()),
-				NomeFantasia: page.PageName,
-			}
+		for _, page := range pages {
+			storeMap[page.PageID] = s.storeInfoFromPage(page)
 		}
 		return storeMap, nil
 	}
@@ -372,23 +620,9 @@ func (s *AnotaAiService) GetMultipleStoreStatus(idsLojas []string) (map[string]m
 
 	// Procura cada loja solicitada na resposta da API
 	for _, idLoja := range idsLojas {
-		for _, page := range listResp.Info.Docs {
+		for _, page := range pages {
 			if page.PageID == idLoja {
-				// No AnotaAI só existe ativo e bloqueado
-				var status models.Status
-				if page.Page.Establishment.Sign.Active {
-					status = models.StatusAtivo
-				} else {
-					status = models.StatusBloqueado
-				}
-
-				storeMap[idLoja] = models.StoreInfo{
-					Found:        true,
-					IsActive:     page.Page.Establishment.Sign.Active,
-					Status:       status,
-					Documento:    utils.CleanDocument(page.Page.Establishment.Sign.CpfCnpj.GetValue()),
-					NomeFantasia: page.PageName,
-				}
+				storeMap[idLoja] = s.storeInfoFromPage(page)
 				break
 			}
 		}
@@ -0,0 +1,175 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"delivery-control/internal/config"
+	"delivery-control/internal/models"
+	"delivery-control/internal/testutil/fakeplatform"
+)
+
+// decodeAnotaAiPage decodifica uma página montada pelo fakeplatform como a listagem faria
+func decodeAnotaAiPage(t *testing.T, raw map[string]any) AnotaAiPage {
+	t.Helper()
+	data, err := json.Marshal(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var page AnotaAiPage
+	if err := json.Unmarshal(data, &page); err != nil {
+		t.Fatalf("erro ao decodificar página: %v", err)
+	}
+	return page
+}
+
+func TestAnotaAiPageStatusCombinesPageAndSignFlags(t *testing.T) {
+	tests := []struct {
+		name         string
+		pageActive   bool
+		signActive   bool
+		wantStatus   models.Status
+		wantIsActive bool
+	}{
+		{name: "página ativa e assinatura ativa", pageActive: true, signActive: true, wantStatus: models.StatusAtivo, wantIsActive: true},
+		{name: "página ativa e assinatura bloqueada", pageActive: true, signActive: false, wantStatus: models.StatusBloqueado},
+		{name: "página arquivada e assinatura ativa", pageActive: false, signActive: true, wantStatus: models.StatusBloqueado},
+		{name: "página arquivada e assinatura bloqueada", pageActive: false, signActive: false, wantStatus: models.StatusBloqueado},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw := fakeplatform.AnotaAiPage("page-1", "Loja", "12345678901", tt.signActive)
+			raw["active"] = tt.pageActive
+
+			status, isActive := anotaAiPageStatus(decodeAnotaAiPage(t, raw), models.StatusIncompleto)
+			if status != tt.wantStatus || isActive != tt.wantIsActive {
+				t.Errorf("anotaAiPageStatus() = (%q, %v), esperado (%q, %v)", status, isActive, tt.wantStatus, tt.wantIsActive)
+			}
+		})
+	}
+}
+
+func TestAnotaAiUpdateResponses(t *testing.T) {
+	tests := []struct {
+		name    string
+		resp    fakeplatform.Response
+		wantErr bool
+	}{
+		{name: "200 com corpo de sucesso", resp: fakeplatform.JSON(http.StatusOK, map[string]any{"success": true})},
+		{name: "204 sem corpo", resp: fakeplatform.Response{Status: http.StatusNoContent}},
+		{name: "200 com success false", resp: fakeplatform.JSON(http.StatusOK, map[string]any{"success": false, "message": "Página não encontrada"}), wantErr: true},
+		{name: "404", resp: fakeplatform.JSON(http.StatusNotFound, map[string]any{"success": false, "message": "Página não encontrada"}), wantErr: true},
+		{name: "500 com página HTML", resp: fakeplatform.Response{Status: http.StatusInternalServerError, Body: "<html>erro</html>", ContentType: "text/html"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ps, anotaAi, _ := newTestService(t)
+			anotaAi.SetResponse(fakeplatform.RouteAnotaAiActivate, tt.resp)
+			anotaAi.SetResponse(fakeplatform.RouteAnotaAiBlock, tt.resp)
+
+			operacoes := map[string]func() error{
+				"ativação":    func() error { return ps.anotaAiService.ActivateStore(context.Background(), "page-1") },
+				"desativação": func() error { return ps.anotaAiService.DeactivateStore(context.Background(), "page-1") },
+			}
+			for nome, operacao := range operacoes {
+				err := operacao()
+				if tt.wantErr && err == nil {
+					t.Errorf("%s deveria falhar", nome)
+				}
+				if !tt.wantErr && err != nil {
+					t.Errorf("%s deveria ter sucesso, erro: %v", nome, err)
+				}
+			}
+		})
+	}
+}
+
+func TestAnotaAiListPagesSkipsCorruptPage(t *testing.T) {
+	ps, anotaAi, _ := newTestService(t)
+	corrompida := fakeplatform.AnotaAiPage("page-corrompida", "Loja Corrompida", "12345678901", true)
+	corrompida["active"] = "sim"
+	anotaAi.SetResponse(fakeplatform.RouteAnotaAiListPages, fakeplatform.JSON(http.StatusOK, fakeplatform.AnotaAiListPages(
+		fakeplatform.AnotaAiPage("page-1", "Loja 1", "12345678901", true),
+		corrompida,
+		fakeplatform.AnotaAiPage("page-2", "Loja 2", "12345678000190", false),
+	)))
+
+	resposta, err := ps.GetMultipleStoreStatus(context.Background(), models.PlataformaAnotaAi, []string{"page-1", "page-corrompida", "page-2"}, false, models.OrdenarPorID)
+	if err != nil {
+		t.Fatalf("uma página corrompida não deveria invalidar o catálogo, erro: %v", err)
+	}
+
+	got := statusByID(resposta.Lojas)
+	want := map[string]models.Status{
+		"page-1":          models.StatusAtivo,
+		"page-corrompida": models.StatusNaoEncontrado,
+		"page-2":          models.StatusBloqueado,
+	}
+	for id, status := range want {
+		if got[id] != status {
+			t.Errorf("status da página %s = %q, esperado %q", id, got[id], status)
+		}
+	}
+}
+
+func TestAnotaAiSendsConfiguredTokenHeader(t *testing.T) {
+	ps, anotaAi, _ := newTestService(t, func(cfg *config.Config) {
+		cfg.Platforms.AnotaAi.TokenHeader = config.TokenHeader{Name: "X-Api-Key", Prefix: "Token"}
+	})
+
+	if _, err := ps.ActivateStore(context.Background(), models.PlataformaAnotaAi, "page-1"); err != nil {
+		t.Fatalf("ActivateStore() erro: %v", err)
+	}
+	request := anotaAi.Requests(fakeplatform.RouteAnotaAiActivate)[0]
+	if got := request.Header.Get("X-Api-Key"); got != "Token "+fakeplatform.AnotaAiToken {
+		t.Errorf("header X-Api-Key = %q, esperado o token com o prefixo configurado", got)
+	}
+	if got := request.Header.Get("authorization"); got != "" {
+		t.Errorf("header authorization = %q, esperado vazio com outro header configurado", got)
+	}
+}
+
+func TestAnotaAiPageWithoutEstablishmentIsIncomplete(t *testing.T) {
+	tests := []struct {
+		name             string
+		incompleteStatus string
+		wantStatus       models.Status
+	}{
+		{name: "padrão", incompleteStatus: config.AnotaAiIncompletoStatus, wantStatus: models.StatusIncompleto},
+		{name: "ANOTAAI_INCOMPLETE_STATUS=bloqueado", incompleteStatus: config.AnotaAiIncompletoBloqueado, wantStatus: models.StatusBloqueado},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ps, anotaAi, _ := newTestService(t, func(cfg *config.Config) {
+				cfg.Platforms.AnotaAi.IncompleteStatus = tt.incompleteStatus
+			})
+			anotaAi.SetResponse(fakeplatform.RouteAnotaAiListPages, fakeplatform.JSON(http.StatusOK, fakeplatform.AnotaAiListPages(
+				fakeplatform.AnotaAiIncompletePage("page-incompleta", "Loja em Cadastro"),
+				fakeplatform.AnotaAiPage("page-1", "Loja 1", "12345678901", true),
+			)))
+
+			resposta, err := ps.GetMultipleStoreStatus(context.Background(), models.PlataformaAnotaAi, []string{"page-incompleta", "page-1"}, false, models.OrdenarPorID)
+			if err != nil {
+				t.Fatalf("uma página sem estabelecimento não deveria invalidar o catálogo, erro: %v", err)
+			}
+
+			got := statusByID(resposta.Lojas)
+			if got["page-incompleta"] != tt.wantStatus {
+				t.Errorf("status da página sem estabelecimento = %q, esperado %q", got["page-incompleta"], tt.wantStatus)
+			}
+			if got["page-1"] != models.StatusAtivo {
+				t.Errorf("status da página completa = %q, esperado %q", got["page-1"], models.StatusAtivo)
+			}
+			for _, loja := range resposta.Lojas {
+				if loja.IdLoja == "page-incompleta" && (loja.Detalhes == nil || !loja.Detalhes.DadosIncompletos || loja.IsActive) {
+					t.Errorf("detalhes = %+v, is_active = %v, esperado dados_incompletos sem is_active", loja.Detalhes, loja.IsActive)
+				}
+			}
+		})
+	}
+}
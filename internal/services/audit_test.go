@@ -0,0 +1,55 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"delivery-control/internal/config"
+	"delivery-control/internal/metrics"
+	"delivery-control/internal/models"
+	"delivery-control/internal/testutil/fakeplatform"
+)
+
+// activationSuccesses retorna o contador de ativações bem-sucedidas da plataforma nas métricas
+func activationSuccesses(plataforma models.Plataforma) int64 {
+	for _, stats := range metrics.TakeSnapshot().Operacoes {
+		if stats.Plataforma == string(plataforma) && stats.Operacao == string(models.OperacaoAtivar) {
+			return stats.Sucesso
+		}
+	}
+	return 0
+}
+
+func TestActivationsAreAudited(t *testing.T) {
+	ps, anotaAi, _ := newTestService(t, func(cfg *config.Config) { cfg.Debug.RecentOperations = 10 })
+	anotaAi.SetResponse(fakeplatform.RouteAnotaAiListPages, fakeplatform.JSON(http.StatusOK, fakeplatform.AnotaAiListPages(
+		fakeplatform.AnotaAiPage("page-4", "Loja Bloqueada", "12345678901", false),
+	)))
+	antes := activationSuccesses(models.PlataformaAnotaAi)
+
+	if _, err := ps.ActivateStore(context.Background(), models.PlataformaAnotaAi, "page-1"); err != nil {
+		t.Fatalf("ActivateStore() erro: %v", err)
+	}
+	if _, err := ps.ActivateMultipleStores(context.Background(), string(models.PlataformaAnotaAi), []string{"page-2", "page-3"}); err != nil {
+		t.Fatalf("ActivateMultipleStores() erro: %v", err)
+	}
+	if _, err := ps.SetStoreStatus(context.Background(), models.PlataformaAnotaAi, "page-4", models.StatusAtivo, "", nil); err != nil {
+		t.Fatalf("SetStoreStatus() erro: %v", err)
+	}
+
+	if got := activationSuccesses(models.PlataformaAnotaAi) - antes; got != 4 {
+		t.Errorf("ativações contadas nas métricas = %d, esperadas 4", got)
+	}
+	ativadas := map[string]bool{}
+	for _, registro := range ps.RecentOperations(0).Operacoes {
+		if registro.Operacao == models.OperacaoAtivar && registro.Sucesso {
+			ativadas[registro.IdLoja] = true
+		}
+	}
+	for _, id := range []string{"page-1", "page-2", "page-3", "page-4"} {
+		if !ativadas[id] {
+			t.Errorf("ativação da loja %s não foi registrada na auditoria", id)
+		}
+	}
+}
@@ -0,0 +1,241 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"net/url"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"delivery-control/internal/config"
+	"delivery-control/internal/models"
+)
+
+// runBulk processa as lojas de uma operação em lote respeitando o limite de concorrência da
+// plataforma (fixo em BULK_CONCURRENCY ou adaptativo, ver concurrencyLimiter). As lojas são
+// despachadas na ordem dos IDs recebidos, o que permite priorizar lojas colocando-as no início
+// da lista, e os resultados mantêm essa mesma ordem.
+// O lote tem o prazo de BULK_DEADLINE e acompanha o contexto da requisição: esgotado o prazo (ou
+// com o cliente desconectado), nenhuma loja nova é iniciada e as que faltam são reportadas como
+// nao_processado, enquanto as que estão em andamento são canceladas e reportadas como tempo_esgotado
+func (ps *PlatformService) runBulk(ctx context.Context, plataforma models.Plataforma, idsLojas []string, process func(ctx context.Context, idLoja string) models.ResultadoOperacaoLoja) []models.ResultadoOperacaoLoja {
+	resultados := make([]models.ResultadoOperacaoLoja, len(idsLojas))
+	limiter, ok := ps.limiters[plataforma]
+	if !ok {
+		limiter = newConcurrencyLimiter(plataforma, ps.config.Bulk, false)
+	}
+
+	ctx, cancel := ps.bulkContext(ctx)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for i, idLoja := range idsLojas {
+		if !limiter.acquire(ctx) {
+			restantes := len(idsLojas) - i
+			log.Printf("[Bulk] plataforma=%s lote interrompido (%v), %d lojas não processadas", plataforma, context.Cause(ctx), restantes)
+			for j := i; j < len(idsLojas); j++ {
+				resultados[j] = newResultadoNaoProcessado(idsLojas[j], ctx.Err())
+			}
+			break
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// O lote pode ter sido interrompido entre a vaga liberada e o início da goroutine
+			if ctx.Err() != nil {
+				resultados[i] = newResultadoNaoProcessado(idLoja, ctx.Err())
+				limiter.releaseUnused()
+				return
+			}
+
+			inicio := time.Now()
+			storeCtx, cancel := ps.storeContext(ctx)
+			defer cancel()
+			resultados[i] = safeProcess(storeCtx, idLoja, process)
+			if ctx.Err() != nil {
+				resultados[i] = interruptedResult(resultados[i], ctx.Err())
+			}
+			// Loja não encontrada não indica problema na plataforma
+			falhou := resultados[i].Status == models.StatusErro || resultados[i].Status == models.StatusTempoEsgotado
+			limiter.release(time.Since(inicio), falhou)
+		}()
+	}
+	wg.Wait()
+
+	return resultados
+}
+
+// safeProcess executa o processamento de uma loja recuperando panics, para que uma loja
+// com problema seja marcada como falha sem derrubar o servidor
+func safeProcess(ctx context.Context, idLoja string, process func(ctx context.Context, idLoja string) models.ResultadoOperacaoLoja) (resultado models.ResultadoOperacaoLoja) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("[Bulk] PANIC ao processar loja %s: %v\n%s", idLoja, r, debug.Stack())
+
+			errType := models.ErroInternoServidor
+			resultado = models.ResultadoOperacaoLoja{
+				IdLoja:   idLoja,
+				Status:   models.StatusErro,
+				Sucesso:  false,
+				Mensagem: fmt.Sprintf("Erro interno ao processar loja: %v", r),
+				Erro:     &errType,
+			}
+		}
+	}()
+
+	return process(ctx, idLoja)
+}
+
+// storeContext cria o contexto do processamento de uma loja, com o prazo de PER_STORE_TIMEOUT
+// para que uma loja travada na plataforma não consuma o tempo do lote inteiro
+func (ps *PlatformService) storeContext(parent context.Context) (context.Context, context.CancelFunc) {
+	if ps.config.Bulk.StoreTimeout <= 0 {
+		return context.WithCancel(parent)
+	}
+	return context.WithTimeout(parent, ps.config.Bulk.StoreTimeout)
+}
+
+// bulkContext cria o contexto do lote, com o prazo de BULK_DEADLINE
+func (ps *PlatformService) bulkContext(parent context.Context) (context.Context, context.CancelFunc) {
+	if ps.config.Bulk.Deadline <= 0 {
+		return context.WithCancel(parent)
+	}
+	return context.WithTimeout(parent, ps.config.Bulk.Deadline)
+}
+
+// newResultadoNaoProcessado monta o resultado de uma loja que não chegou a ser despachada porque
+// o lote foi interrompido. A plataforma não foi chamada para essa loja
+func newResultadoNaoProcessado(idLoja string, err error) models.ResultadoOperacaoLoja {
+	mensagem := "Loja não processada: prazo do lote (BULK_DEADLINE ou X-Request-Timeout) esgotado"
+	if errors.Is(err, context.Canceled) {
+		mensagem = "Loja não processada: requisição cancelada pelo cliente"
+	}
+	errType := models.ErroTempoEsgotado
+	return models.ResultadoOperacaoLoja{
+		IdLoja:   idLoja,
+		Status:   models.StatusNaoProcessado,
+		Sucesso:  false,
+		Mensagem: mensagem,
+		Erro:     &errType,
+	}
+}
+
+// interruptedResult ajusta o resultado de uma loja em andamento quando o lote foi interrompido:
+// a falha causada pelo cancelamento vira tempo_esgotado, já que a plataforma pode ou não ter aplicado
+// a operação. Resultados concluídos antes da interrupção são mantidos
+func interruptedResult(resultado models.ResultadoOperacaoLoja, err error) models.ResultadoOperacaoLoja {
+	if resultado.Status != models.StatusErro && resultado.Status != models.StatusTempoEsgotado {
+		return resultado
+	}
+
+	resultado.Status = models.StatusTempoEsgotado
+	resultado.Mensagem = "Loja interrompida: prazo do lote (BULK_DEADLINE ou X-Request-Timeout) esgotado durante a operação"
+	if errors.Is(err, context.Canceled) {
+		resultado.Mensagem = "Loja interrompida: requisição cancelada pelo cliente durante a operação"
+	}
+	errType := models.ErroTempoEsgotado
+	resultado.Erro = &errType
+	return resultado
+}
+
+// HasUnprocessed indica se alguma loja do lote deixou de ser processada por causa do prazo do lote
+func HasUnprocessed(resultados []models.ResultadoOperacaoLoja) bool {
+	for _, resultado := range resultados {
+		if resultado.Status == models.StatusNaoProcessado {
+			return true
+		}
+	}
+	return false
+}
+
+// retryBudget é o orçamento de novas tentativas compartilhado pelas lojas de um lote. Quando a
+// plataforma está claramente fora do ar, o orçamento se esgota e as lojas restantes falham
+// sem novas tentativas, evitando multiplicar as chamadas durante a indisponibilidade
+type retryBudget struct {
+	// initial é o orçamento do lote; zero desabilita as novas tentativas sem que haja o que esgotar
+	initial   int64
+	remaining atomic.Int64
+	exhausted atomic.Bool
+}
+
+// newRetryBudget cria o orçamento do lote: BULK_RETRY_BUDGET (fração das lojas, arredondada
+// para cima) novas tentativas no total
+func newRetryBudget(cfg config.BulkConfig, total int) *retryBudget {
+	budget := &retryBudget{}
+	if cfg.MaxRetries > 0 && cfg.RetryBudget > 0 {
+		budget.initial = int64(math.Ceil(cfg.RetryBudget * float64(total)))
+	}
+	budget.remaining.Store(budget.initial)
+	return budget
+}
+
+// take consome uma nova tentativa do orçamento, retornando false se ele já se esgotou. O
+// esgotamento só é registrado em log quando havia orçamento (BULK_RETRY_BUDGET=0 não o configura)
+func (b *retryBudget) take() bool {
+	if b.remaining.Add(-1) >= 0 {
+		return true
+	}
+	if b.initial > 0 && !b.exhausted.Swap(true) {
+		log.Printf("[Bulk] Orçamento de novas tentativas esgotado, as lojas restantes falham sem novas tentativas")
+	}
+	return false
+}
+
+// withRetry executa a operação da loja, repetindo falhas transitórias até BULK_MAX_RETRIES vezes
+// enquanto houver orçamento no lote e dentro do prazo da loja. O intervalo entre tentativas começa em BULK_RETRY_DELAY e dobra.
+// Uma falha transitória (timeout, 5xx) não garante que a plataforma deixou de aplicar a operação, então
+// a operação deve ser idempotente, sem efeito adicional numa segunda execução:
+//   - ativar/desativar no AnotaAI: PUT que define o estado da página
+//   - ativar/desativar no DeliveryVip: POST unblock/block, idempotente pelo estado desejado porque a
+//     resposta "já está no status" é tratada como sucesso (ErrLojaJaNoStatus)
+//
+// Operações que criam ou acumulam algo na plataforma não devem passar por withRetry
+func (ps *PlatformService) withRetry(ctx context.Context, budget *retryBudget, idLoja string, operation func() error) error {
+	err := operation()
+
+	delay := ps.config.Bulk.RetryDelay
+	for tentativa := 1; err != nil && tentativa <= ps.config.Bulk.MaxRetries && isTransient(err); tentativa++ {
+		// Com o prazo da loja esgotado, não há tempo para uma nova tentativa
+		if ctx.Err() != nil {
+			break
+		}
+		if !budget.take() {
+			break
+		}
+		log.Printf("[Bulk] Falha transitória na loja %s, nova tentativa %d/%d em %s: %v", idLoja, tentativa, ps.config.Bulk.MaxRetries, delay, err)
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return fmt.Errorf("%w após falha transitória: %v", ctx.Err(), err)
+		}
+		delay *= 2
+		err = operation()
+	}
+	return err
+}
+
+// isTransient indica se vale tentar novamente: falhas de rede e respostas 5xx ou 429 da plataforma.
+// Loja não encontrada, credenciais inválidas e dados rejeitados não mudam com uma nova tentativa
+func isTransient(err error) bool {
+	var deliveryVipErr *DeliveryVipError
+	if errors.As(err, &deliveryVipErr) {
+		return isTransientStatus(deliveryVipErr.HTTPStatus)
+	}
+	var anotaAiErr *AnotaAiError
+	if errors.As(err, &anotaAiErr) {
+		return isTransientStatus(anotaAiErr.HTTPStatus)
+	}
+	var urlErr *url.Error
+	return errors.As(err, &urlErr)
+}
+
+// isTransientStatus indica se o status HTTP da plataforma representa uma falha transitória
+func isTransientStatus(statusCode int) bool {
+	return statusCode == http.StatusTooManyRequests || statusCode >= http.StatusInternalServerError
+}
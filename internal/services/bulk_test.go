@@ -0,0 +1,182 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"delivery-control/internal/config"
+	"delivery-control/internal/models"
+	"delivery-control/internal/testutil/fakeplatform"
+)
+
+// newBulkTestService cria um PlatformService apenas com a configuração de lotes, suficiente para runBulk
+func newBulkTestService(bulk config.BulkConfig) *PlatformService {
+	return &PlatformService{config: &config.Config{Bulk: bulk}}
+}
+
+func TestRunBulkRecoversPanickingStore(t *testing.T) {
+	ps := newBulkTestService(config.BulkConfig{Concurrency: 2})
+	ids := []string{"loja-1", "loja-panic", "loja-3"}
+
+	resultados := ps.runBulk(context.Background(), models.PlataformaAnotaAi, ids, func(ctx context.Context, idLoja string) models.ResultadoOperacaoLoja {
+		if idLoja == "loja-panic" {
+			panic("falha inesperada")
+		}
+		return newResultadoOperacao(idLoja, nil, "ativar", models.StatusAtivo, "Loja ativada com sucesso")
+	})
+
+	if len(resultados) != len(ids) {
+		t.Fatalf("runBulk() retornou %d resultados, esperado %d", len(resultados), len(ids))
+	}
+	for i, resultado := range resultados {
+		if resultado.IdLoja != ids[i] {
+			t.Errorf("resultado %d é da loja %s, esperado %s", i, resultado.IdLoja, ids[i])
+		}
+	}
+
+	panicked := resultados[1]
+	if panicked.Sucesso || panicked.Status != models.StatusErro {
+		t.Errorf("loja com panic: sucesso=%v status=%q, esperado falha com status erro", panicked.Sucesso, panicked.Status)
+	}
+	if panicked.Erro == nil || *panicked.Erro != models.ErroInternoServidor {
+		t.Errorf("loja com panic: erro=%v, esperado %s", panicked.Erro, models.ErroInternoServidor)
+	}
+	for _, i := range []int{0, 2} {
+		if !resultados[i].Sucesso {
+			t.Errorf("loja %s deveria ser processada apesar do panic em outra loja: %+v", ids[i], resultados[i])
+		}
+	}
+}
+
+func TestBulkGatewayErrorIsReportedAsErro(t *testing.T) {
+	ps, anotaAi, deliveryVip := newTestService(t, func(cfg *config.Config) { cfg.Bulk.MaxRetries = 0 })
+	gatewayError := fakeplatform.Response{Status: http.StatusBadGateway, Body: "<html>Bad Gateway</html>", ContentType: "text/html"}
+	for _, route := range []string{fakeplatform.RouteAnotaAiActivate, fakeplatform.RouteAnotaAiBlock} {
+		anotaAi.SetResponse(route, gatewayError)
+	}
+	for _, route := range []string{fakeplatform.RouteDeliveryVipUnblock, fakeplatform.RouteDeliveryVipBlock} {
+		deliveryVip.SetResponse(route, gatewayError)
+	}
+
+	operacoes := map[string]func(plataforma string) (*models.RespostaOperacaoMultiplasLojas, error){
+		"ativar": func(plataforma string) (*models.RespostaOperacaoMultiplasLojas, error) {
+			return ps.ActivateMultipleStores(context.Background(), plataforma, []string{"loja-1"})
+		},
+		"desativar": func(plataforma string) (*models.RespostaOperacaoMultiplasLojas, error) {
+			return ps.DeactivateMultipleStores(context.Background(), plataforma, []string{"loja-1"}, "")
+		},
+	}
+
+	for nome, operacao := range operacoes {
+		for _, plataforma := range []models.Plataforma{models.PlataformaAnotaAi, models.PlataformaDeliveryVip} {
+			t.Run(nome+"/"+string(plataforma), func(t *testing.T) {
+				resposta, err := operacao(string(plataforma))
+				if err != nil {
+					t.Fatalf("operação em lote retornou erro: %v", err)
+				}
+				resultado := resposta.Resultados[0]
+				if resultado.Sucesso || resultado.Status != models.StatusErro {
+					t.Errorf("sucesso=%v status=%q, esperado falha com status %q (e não %q)", resultado.Sucesso, resultado.Status, models.StatusErro, models.StatusNaoEncontrado)
+				}
+				if resultado.Erro == nil || *resultado.Erro == models.ErroNaoEncontrado {
+					t.Errorf("erro = %v, esperado um erro de gateway", resultado.Erro)
+				}
+			})
+		}
+	}
+}
+
+func TestRunBulkInterruptedMidBatch(t *testing.T) {
+	tests := []struct {
+		name string
+		bulk config.BulkConfig
+		// interrompe encerra o lote enquanto a segunda e a terceira lojas estão em andamento
+		interrompe func(cancel context.CancelFunc)
+	}{
+		{
+			name:       "requisição cancelada pelo cliente",
+			bulk:       config.BulkConfig{Concurrency: 2},
+			interrompe: func(cancel context.CancelFunc) { cancel() },
+		},
+		{
+			name:       "prazo do lote esgotado",
+			bulk:       config.BulkConfig{Concurrency: 2, Deadline: 50 * time.Millisecond},
+			interrompe: func(context.CancelFunc) {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ps := newBulkTestService(tt.bulk)
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			ids := []string{"loja-concluida", "loja-interrompida", "loja-concluida-na-interrupcao", "loja-pendente-1", "loja-pendente-2"}
+
+			terceiraIniciada := make(chan struct{})
+			resultados := ps.runBulk(ctx, models.PlataformaAnotaAi, ids, func(ctx context.Context, idLoja string) models.ResultadoOperacaoLoja {
+				switch idLoja {
+				case "loja-interrompida":
+					<-terceiraIniciada
+					tt.interrompe(cancel)
+					<-ctx.Done()
+					return newResultadoOperacao(idLoja, ctx.Err(), "ativar", models.StatusAtivo, "Loja ativada com sucesso")
+				case "loja-concluida-na-interrupcao":
+					close(terceiraIniciada)
+					<-ctx.Done()
+				}
+				return newResultadoOperacao(idLoja, nil, "ativar", models.StatusAtivo, "Loja ativada com sucesso")
+			})
+
+			if len(resultados) != len(ids) {
+				t.Fatalf("runBulk() retornou %d resultados, esperado %d", len(resultados), len(ids))
+			}
+			for _, r := range []models.ResultadoOperacaoLoja{resultados[0], resultados[2]} {
+				// Uma loja iniciada mantém o resultado obtido, mesmo que o lote seja interrompido
+				if !r.Sucesso || r.Status != models.StatusAtivo {
+					t.Errorf("loja %s concluída: %+v, esperado o sucesso obtido", r.IdLoja, r)
+				}
+			}
+			if r := resultados[1]; r.Sucesso || r.Status != models.StatusTempoEsgotado {
+				t.Errorf("loja em andamento na interrupção: status %q, esperado %q", r.Status, models.StatusTempoEsgotado)
+			}
+			for _, r := range resultados[3:] {
+				if r.Sucesso || r.Status != models.StatusNaoProcessado {
+					t.Errorf("loja %s não iniciada: status %q, esperado %q", r.IdLoja, r.Status, models.StatusNaoProcessado)
+				}
+			}
+			if !HasUnprocessed(resultados) {
+				t.Error("HasUnprocessed() = false, esperado true com lojas não iniciadas")
+			}
+		})
+	}
+}
+
+func TestRetryBudgetOnlyReportsConfiguredBudget(t *testing.T) {
+	tests := []struct {
+		name          string
+		retryBudget   float64
+		wantTakes     int
+		wantExhausted bool
+	}{
+		{name: "sem orçamento", retryBudget: 0, wantTakes: 0, wantExhausted: false},
+		{name: "orçamento esgotado", retryBudget: 0.5, wantTakes: 2, wantExhausted: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			budget := newRetryBudget(config.BulkConfig{MaxRetries: 2, RetryBudget: tt.retryBudget}, 4)
+			takes := 0
+			for range 5 {
+				if budget.take() {
+					takes++
+				}
+			}
+			// exhausted marca o log de orçamento esgotado, que não cabe quando não havia orçamento
+			if takes != tt.wantTakes || budget.exhausted.Load() != tt.wantExhausted {
+				t.Errorf("tentativas=%d esgotado=%v, esperado tentativas=%d esgotado=%v", takes, budget.exhausted.Load(), tt.wantTakes, tt.wantExhausted)
+			}
+		})
+	}
+}
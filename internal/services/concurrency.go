@@ -0,0 +1,126 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"delivery-control/internal/config"
+	"delivery-control/internal/metrics"
+	"delivery-control/internal/models"
+)
+
+// concurrencyLimiter controla quantas lojas de uma plataforma são processadas ao mesmo tempo
+// nas operações em lote. Com BULK_ADAPTIVE=true o limite se ajusta no estilo AIMD: cresce
+// aditivamente (cerca de +1 a cada rodada de lojas bem-sucedidas) enquanto a plataforma responde
+// dentro de BULK_LATENCY_TARGET, e cai pela metade em respostas lentas ou falhas. O limite adaptativo
+// é compartilhado pelos lotes simultâneos da plataforma e mantido entre lotes. Sem BULK_ADAPTIVE,
+// cada lote usa um limitador próprio fixo em BULK_CONCURRENCY
+type concurrencyLimiter struct {
+	plataforma    models.Plataforma
+	latencyTarget time.Duration
+	minLimit      float64
+	maxLimit      float64
+	// report publica o limite nas métricas; nil nos serviços de URLs alternativas
+	report func(plataforma string, limite int)
+
+	mu           sync.Mutex
+	cond         *sync.Cond
+	limit        float64
+	inFlight     int
+	lastDecrease time.Time
+}
+
+// newConcurrencyLimiters cria os limitadores adaptativos compartilhados, um por plataforma
+// suportada. Sem BULK_ADAPTIVE não há limitador compartilhado e as métricas trazem o limite fixo
+func newConcurrencyLimiters(cfg config.BulkConfig, reportMetrics bool) map[models.Plataforma]*concurrencyLimiter {
+	limiters := make(map[models.Plataforma]*concurrencyLimiter, len(platformOperations))
+	for plataforma := range platformOperations {
+		if cfg.Adaptive {
+			limiters[plataforma] = newConcurrencyLimiter(plataforma, cfg, reportMetrics)
+		} else if reportMetrics {
+			metrics.SetBulkConcurrency(string(plataforma), max(cfg.Concurrency, 1))
+		}
+	}
+	return limiters
+}
+
+func newConcurrencyLimiter(plataforma models.Plataforma, cfg config.BulkConfig, reportMetrics bool) *concurrencyLimiter {
+	base := float64(max(cfg.Concurrency, 1))
+	l := &concurrencyLimiter{
+		plataforma:    plataforma,
+		latencyTarget: cfg.LatencyTarget,
+		minLimit:      base,
+		maxLimit:      base,
+		limit:         base,
+	}
+	if cfg.Adaptive {
+		l.minLimit = 1
+		l.maxLimit = float64(max(cfg.MaxConcurrency, cfg.Concurrency, 1))
+	}
+	if reportMetrics {
+		l.report = metrics.SetBulkConcurrency
+		l.report(string(plataforma), int(l.limit))
+	}
+	l.cond = sync.NewCond(&l.mu)
+	return l
+}
+
+// acquire aguarda uma vaga dentro do limite atual, retornando false se o contexto terminar antes
+func (l *concurrencyLimiter) acquire(ctx context.Context) bool {
+	// Acorda a espera quando o contexto termina, já que sync.Cond não observa contextos
+	stop := context.AfterFunc(ctx, func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		l.cond.Broadcast()
+	})
+	defer stop()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for l.inFlight >= int(l.limit) && ctx.Err() == nil {
+		l.cond.Wait()
+	}
+	if ctx.Err() != nil {
+		return false
+	}
+	l.inFlight++
+	return true
+}
+
+// releaseUnused libera a vaga de uma loja que não chegou a ser processada (o lote terminou antes
+// do início do worker), sem ajustar o limite: não houve latência nem resultado da plataforma
+func (l *concurrencyLimiter) releaseUnused() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.inFlight--
+	l.cond.Broadcast()
+}
+
+// release libera a vaga e ajusta o limite pela latência e pelo resultado observados. A redução
+// acontece no máximo uma vez por BULK_LATENCY_TARGET, para que as falhas de uma mesma rodada
+// (que chegam juntas) não derrubem o limite direto para 1
+func (l *concurrencyLimiter) release(latencia time.Duration, falhou bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.inFlight--
+	defer l.cond.Broadcast()
+
+	if l.minLimit == l.maxLimit {
+		return
+	}
+
+	anterior := int(l.limit)
+	if falhou || latencia > l.latencyTarget {
+		if time.Since(l.lastDecrease) > l.latencyTarget {
+			l.limit = max(l.minLimit, l.limit/2)
+			l.lastDecrease = time.Now()
+		}
+	} else {
+		l.limit = min(l.maxLimit, l.limit+1/l.limit)
+	}
+
+	if atual := int(l.limit); atual != anterior && l.report != nil {
+		l.report(string(l.plataforma), atual)
+	}
+}
@@ -0,0 +1,40 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"delivery-control/internal/config"
+	"delivery-control/internal/models"
+)
+
+func TestConcurrencyLimiterUnusedReleaseKeepsLimit(t *testing.T) {
+	l := newConcurrencyLimiter(models.PlataformaAnotaAi, config.BulkConfig{
+		Concurrency:    4,
+		Adaptive:       true,
+		MaxConcurrency: 8,
+		LatencyTarget:  time.Second,
+	}, false)
+
+	// Uma falha reduz o limite pela metade
+	if !l.acquire(context.Background()) {
+		t.Fatal("acquire() = false, esperado true")
+	}
+	l.release(0, true)
+	if l.limit != 2 {
+		t.Fatalf("limite após falha = %v, esperado 2", l.limit)
+	}
+
+	// Um worker que não chegou a processar a loja não desfaz a redução
+	for range 2 {
+		if !l.acquire(context.Background()) {
+			t.Fatal("acquire() = false, esperado true")
+		}
+	}
+	l.releaseUnused()
+	l.releaseUnused()
+	if l.limit != 2 || l.inFlight != 0 {
+		t.Errorf("limite = %v, em andamento = %d após liberar vagas não usadas, esperado limite 2 e nenhuma em andamento", l.limit, l.inFlight)
+	}
+}
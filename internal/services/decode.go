@@ -0,0 +1,156 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// maxResponseBodySize limita o tamanho das respostas lidas das plataformas (32 MiB)
+const maxResponseBodySize = 32 << 20
+
+// errRespostaSemConteudo indica uma resposta da plataforma sem corpo, aceita como sucesso
+// pelas operações que não dependem do corpo
+var errRespostaSemConteudo = errors.New("resposta da plataforma sem conteúdo")
+
+// errRespostaHTML indica que a plataforma respondeu HTML (ex.: página de erro de gateway) em vez de JSON
+var errRespostaHTML = errors.New("plataforma retornou HTML em vez de JSON")
+
+// hasBody indica se a resposta pode ter corpo a decodificar: 204, 205 e Content-Length 0 não têm.
+// Respostas chunked (Content-Length desconhecido) podem ainda assim chegar vazias, o que
+// decodeJSON informa com errRespostaSemConteudo
+func hasBody(resp *http.Response) bool {
+	return resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusResetContent && resp.ContentLength != 0
+}
+
+// decodeJSON decodifica o corpo de uma resposta da plataforma em out, validando o
+// content type e limitando o tamanho lido. Retorna um erro claro quando a plataforma
+// responde com HTML (ex.: página de erro de gateway) em vez de JSON
+func decodeJSON[T any](resp *http.Response, out *T) error {
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBodySize+1))
+	if err != nil {
+		return fmt.Errorf("erro ao ler resposta da plataforma: %w", err)
+	}
+	if len(body) > maxResponseBodySize {
+		return fmt.Errorf("resposta da plataforma excede o limite de %d bytes", maxResponseBodySize)
+	}
+
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) == 0 {
+		return fmt.Errorf("%w (status %d)", errRespostaSemConteudo, resp.StatusCode)
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	if isHTML(contentType, trimmed) {
+		return fmt.Errorf("%w (status %d): %s", errRespostaHTML, resp.StatusCode, truncateBody(trimmed))
+	}
+
+	if contentType != "" {
+		mediaType, _, err := mime.ParseMediaType(contentType)
+		if err != nil || !isJSONMediaType(mediaType) {
+			return fmt.Errorf("plataforma retornou conteúdo inesperado %q (status %d)", contentType, resp.StatusCode)
+		}
+	}
+
+	if err := json.Unmarshal(trimmed, out); err != nil {
+		return fmt.Errorf("erro ao decodificar resposta da plataforma: %w", err)
+	}
+
+	return nil
+}
+
+// isJSONMediaType verifica se o media type representa JSON
+func isJSONMediaType(mediaType string) bool {
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// isHTML detecta respostas HTML pelo content type ou pelo primeiro caractere do corpo
+func isHTML(contentType string, body []byte) bool {
+	return strings.Contains(strings.ToLower(contentType), "html") || body[0] == '<'
+}
+
+// truncateBody limita o trecho do corpo incluído em mensagens de erro
+func truncateBody(body []byte) string {
+	const maxLen = 200
+	if len(body) > maxLen {
+		return string(body[:maxLen]) + "..."
+	}
+	return string(body)
+}
+
+// jsonField retorna o valor do campo no caminho separado por "." (ex.: "data.success") e se ele existe
+func jsonField(body map[string]any, path string) (any, bool) {
+	var value any = body
+	for campo := range strings.SplitSeq(path, ".") {
+		objeto, ok := value.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if value, ok = objeto[campo]; !ok {
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// firstJSONField retorna o primeiro dos campos com valor não vazio, como texto, ou vazio se nenhum tiver
+func firstJSONField(body map[string]any, paths []string) string {
+	for _, path := range paths {
+		if value, ok := jsonField(body, path); ok && value != nil && value != "" {
+			return fmt.Sprint(value)
+		}
+	}
+	return ""
+}
+
+// tolerantList decodifica um array JSON elemento a elemento, descartando os elementos que não
+// puderem ser decodificados em T, para que um único registro malformado não invalide a lista inteira.
+// Os elementos descartados ficam em Skipped para que quem chamou os registre com o devido contexto
+type tolerantList[T any] struct {
+	Items   []T
+	Skipped []skippedItem
+}
+
+// skippedItem descreve um elemento descartado por tolerantList
+type skippedItem struct {
+	Indice int
+	Err    error
+}
+
+// UnmarshalJSON percorre o array com json.Decoder, decodificando cada elemento separadamente.
+// Apenas um array sintaticamente inválido faz a decodificação inteira falhar
+func (l *tolerantList[T]) UnmarshalJSON(data []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	if token == nil {
+		return nil
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '[' {
+		return fmt.Errorf("esperado um array, recebido %v", token)
+	}
+
+	for indice := 0; decoder.More(); indice++ {
+		var raw json.RawMessage
+		if err := decoder.Decode(&raw); err != nil {
+			return err
+		}
+
+		var item T
+		if err := json.Unmarshal(raw, &item); err != nil {
+			l.Skipped = append(l.Skipped, skippedItem{Indice: indice, Err: err})
+			continue
+		}
+		l.Items = append(l.Items, item)
+	}
+
+	_, err = decoder.Token()
+	return err
+}
@@ -1,10 +1,14 @@
 package services
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
+	"maps"
 	"net/http"
 	"net/url"
 	"strings"
@@ -12,6 +16,7 @@ import (
 	"time"
 
 	"delivery-control/internal/config"
+	"delivery-control/internal/metrics"
 	"delivery-control/internal/models"
 	"delivery-control/internal/utils"
 )
@@ -21,38 +26,81 @@ type DeliveryVipError struct {
 	HTTPStatus int
 	TipoErro   models.TipoErro
 	Mensagem   string
+	// Codigo é o código de erro informado no corpo da resposta, quando houver, mesmo que desconhecido
+	Codigo string
 }
 
 func (e *DeliveryVipError) Error() string {
 	return e.Mensagem
 }
 
-// NewDeliveryVipError cria um novo erro específico do DeliveryVip baseado no status HTTP
-func NewDeliveryVipError(httpStatus int, responseBody string) error {
+// deliveryVipErrorCodes mapeia os códigos de erro do corpo das respostas do DeliveryVip para o
+// TipoErro. Códigos desconhecidos são classificados pelo status HTTP
+var deliveryVipErrorCodes = map[string]models.TipoErro{
+	"MERCHANT_NOT_FOUND": models.ErroNaoEncontrado,
+	"UNAUTHORIZED":       models.ErroNaoAutorizado,
+	"INVALID_TOKEN":      models.ErroNaoAutorizado,
+	"VALIDATION_ERROR":   models.ErroRequisicaoInvalida,
+}
+
+// deliveryVipAlreadyInStateCodes mapeia os códigos que indicam que a loja já estava no status
+// pretendido pelo block/unblock, tratados como sucesso
+var deliveryVipAlreadyInStateCodes = map[string]models.Status{
+	"MERCHANT_ALREADY_BLOCKED":   models.StatusBloqueado,
+	"MERCHANT_ALREADY_UNBLOCKED": models.StatusAtivo,
+	"MERCHANT_ALREADY_ACTIVE":    models.StatusAtivo,
+}
+
+// deliveryVipErrorCode extrai o código de erro do corpo da resposta, lido dos campos de erro da
+// semântica de respostas, retornando vazio se não houver
+func deliveryVipErrorCode(responseBody string, errorFields []string) string {
+	var body map[string]any
+	if err := json.Unmarshal([]byte(responseBody), &body); err != nil {
+		return ""
+	}
+	return firstJSONField(body, errorFields)
+}
+
+// NewDeliveryVipError cria um novo erro específico do DeliveryVip baseado no código de erro do
+// corpo da resposta (codigo), quando conhecido, ou no status HTTP
+func NewDeliveryVipError(httpStatus int, codigo, responseBody string) error {
+	if tipoErro, ok := deliveryVipErrorCodes[codigo]; ok {
+		return &DeliveryVipError{
+			HTTPStatus: httpStatus,
+			TipoErro:   tipoErro,
+			Mensagem:   fmt.Sprintf("Erro reportado pela plataforma: %s", codigo),
+			Codigo:     codigo,
+		}
+	}
+
 	switch httpStatus {
 	case http.StatusNotFound:
 		return &DeliveryVipError{
 			HTTPStatus: httpStatus,
 			TipoErro:   models.ErroNaoEncontrado,
 			Mensagem:   "Loja não encontrada na plataforma",
+			Codigo:     codigo,
 		}
 	case http.StatusUnauthorized:
 		return &DeliveryVipError{
 			HTTPStatus: httpStatus,
 			TipoErro:   models.ErroNaoAutorizado,
 			Mensagem:   "Erro de autenticação com a plataforma",
+			Codigo:     codigo,
 		}
 	case http.StatusUnprocessableEntity:
 		return &DeliveryVipError{
 			HTTPStatus: httpStatus,
 			TipoErro:   models.ErroRequisicaoInvalida,
 			Mensagem:   "Dados inválidos para a operação",
+			Codigo:     codigo,
 		}
 	default:
 		return &DeliveryVipError{
 			HTTPStatus: httpStatus,
 			TipoErro:   models.ErroBadGateway,
 			Mensagem:   fmt.Sprintf("Erro na comunicação com a plataforma - Status: %d, Resposta: %s", httpStatus, responseBody),
+			Codigo:     codigo,
 		}
 	}
 }
@@ -62,6 +110,7 @@ type DeliveryVipService struct {
 	config      *config.Config
 	accessToken string
 	tokenMutex  sync.RWMutex
+	tokenReady  *tokenReady
 	httpClient  *http.Client
 }
 
@@ -90,6 +139,48 @@ type DeliveryVipMerchant struct {
 	} `json:"subscription"`
 }
 
+// DeliveryVipPagination representa os metadados de paginação da listagem de merchants
+type DeliveryVipPagination struct {
+	Page       int `json:"page"`
+	Limit      int `json:"limit"`
+	Total      int `json:"total"`
+	TotalPages int `json:"totalPages"`
+}
+
+// DeliveryVipMerchantsResponse representa a listagem de merchants. A API pode responder
+// tanto com um array simples quanto com um objeto {"data": [...], "pagination": {...}}
+type DeliveryVipMerchantsResponse = DeliveryVipListResponse[DeliveryVipMerchant]
+
+// DeliveryVipListResponse representa uma listagem do DeliveryVip com os itens decodificados em T,
+// permitindo obter tanto os merchants tipados quanto o JSON bruto de cada um
+type DeliveryVipListResponse[T any] struct {
+	Data       []T                    `json:"data"`
+	Pagination *DeliveryVipPagination `json:"pagination,omitempty"`
+}
+
+// UnmarshalJSON aceita os dois formatos da listagem, detectando-os pelo primeiro caractere
+func (r *DeliveryVipListResponse[T]) UnmarshalJSON(data []byte) error {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		r.Pagination = nil
+		return json.Unmarshal(trimmed, &r.Data)
+	}
+
+	// Alias evita a recursão infinita em UnmarshalJSON
+	type wrapper DeliveryVipListResponse[T]
+	var decoded wrapper
+	if err := json.Unmarshal(trimmed, &decoded); err != nil {
+		return err
+	}
+	*r = DeliveryVipListResponse[T](decoded)
+	return nil
+}
+
+// DeliveryVipBlockRequest representa o corpo opcional da requisição de bloqueio
+type DeliveryVipBlockRequest struct {
+	Reason string `json:"reason"`
+}
+
 // DeliveryVipBlockResponse representa a resposta de block/unblock
 type DeliveryVipBlockResponse struct {
 	MerchantID string `json:"merchantId"`
@@ -98,56 +189,73 @@ type DeliveryVipBlockResponse struct {
 // NewDeliveryVipService cria um novo serviço DeliveryVip
 func NewDeliveryVipService(cfg *config.Config) *DeliveryVipService {
 	service := &DeliveryVipService{
-		config: cfg,
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+		config:     cfg,
+		tokenReady: newTokenReady(),
+		httpClient: newPlatformClient(cfg.Platforms.DeliveryVip.ExtraHeaders),
 	}
 
 	// Inicia a rotina de renovação automática de token
 	// A primeira autenticação será feita dentro da goroutine
+	metrics.RegisterToken(string(models.PlataformaDeliveryVip), deliveryVipAccount)
 	go service.startTokenRenewal()
 
 	return service
 }
 
+// deliveryVipSubscriptionStatus mapeia o status da subscription do DeliveryVip para os status do
+// modelo. Também é exposto em GET /plataformas/deliveryvip/mapeamento-status, então a tabela
+// publicada é sempre a usada na classificação
+var deliveryVipSubscriptionStatus = map[string]models.Status{
+	"TRIAL":         models.StatusEmTeste,
+	"TRIAL_EXPIRED": models.StatusTesteExpirado,
+	"CANCELLED":     models.StatusCancelado,
+	"DEMO":          models.StatusDemonstracao,
+	"ACTIVATED":     models.StatusAtivo,
+}
+
+// deliveryVipStatusPadrao é o status das subscriptions desconhecidas e das lojas ativas com
+// subscription.blocked=true, bloqueado por segurança
+const deliveryVipStatusPadrao = models.StatusBloqueado
+
 // mapSubscriptionToStatus mapeia o status e blocked da subscription para os status do modelo
 func (s *DeliveryVipService) mapSubscriptionToStatus(subscriptionStatus string, blocked bool) models.Status {
-	switch subscriptionStatus {
-	case "TRIAL":
-		return models.StatusEmTeste
-	case "TRIAL_EXPIRED":
-		return models.StatusTesteExpirado
-	case "CANCELLED":
-		return models.StatusCancelado
-	case "DEMO":
-		return models.StatusDemonstracao
-	case "ACTIVATED":
-		if blocked {
-			return models.StatusBloqueado
-		}
-		return models.StatusAtivo
-	default:
-		// Para status desconhecidos, retorna bloqueado por segurança
-		return models.StatusBloqueado
+	status, ok := deliveryVipSubscriptionStatus[subscriptionStatus]
+	if !ok || (status == models.StatusAtivo && blocked) {
+		return deliveryVipStatusPadrao
 	}
+	return status
 }
 
-// startTokenRenewal inicia a rotina que renova o token a cada 6 horas (token expira em 24h)
+// StatusMapping retorna a tabela de classificação dos status de subscription do DeliveryVip
+func (s *DeliveryVipService) StatusMapping() *models.RespostaMapeamentoStatus {
+	return &models.RespostaMapeamentoStatus{
+		Plataforma:   models.PlataformaDeliveryVip,
+		Campo:        "subscription.status",
+		Mapeamento:   maps.Clone(deliveryVipSubscriptionStatus),
+		StatusPadrao: deliveryVipStatusPadrao,
+		Observacoes: []string{
+			fmt.Sprintf("Lojas com subscription.status que resulta em %s e subscription.blocked=true são reportadas como %s", models.StatusAtivo, deliveryVipStatusPadrao),
+			fmt.Sprintf("Valores de subscription.status fora do mapeamento são reportados como %s", deliveryVipStatusPadrao),
+		},
+	}
+}
+
+// startTokenRenewal inicia a rotina que renova o token no intervalo configurado (padrão 6 horas, token expira em 24h)
 func (s *DeliveryVipService) startTokenRenewal() {
 	log.Printf("[DeliveryVip] Iniciando serviço de renovação de token...")
 	log.Printf("[DeliveryVip] URL configurada: %s", s.config.Platforms.DeliveryVipURL)
 
 	// Faz o primeiro login imediatamente
 	log.Printf("[DeliveryVip] [%s] Tentando autenticação inicial...", time.Now().Format("2006-01-02 15:04:05"))
-	if err := s.renewToken(); err != nil {
+	if err := s.renewTokenWithRetry(); err != nil {
 		log.Printf("[DeliveryVip] [%s] ERRO na autenticação inicial: %v", time.Now().Format("2006-01-02 15:04:05"), err)
 	} else {
 		log.Printf("[DeliveryVip] [%s] Autenticação inicial realizada com sucesso!", time.Now().Format("2006-01-02 15:04:05"))
 	}
 
-	// Configura renovação a cada 6 horas (margem de segurança maior)
-	renewalInterval := 6 * time.Hour
+	// Configura renovação periódica (padrão 6 horas, margem de segurança maior), com variação
+	// aleatória por instância para espalhar as renovações entre réplicas
+	renewalInterval := jitteredInterval(s.config.Platforms.DeliveryVip.TokenRenewal)
 	ticker := time.NewTicker(renewalInterval)
 	defer ticker.Stop()
 
@@ -156,7 +264,7 @@ func (s *DeliveryVipService) startTokenRenewal() {
 
 	for range ticker.C {
 		log.Printf("[DeliveryVip] [%s] Iniciando renovação automática de token...", time.Now().Format("2006-01-02 15:04:05"))
-		if err := s.renewToken(); err != nil {
+		if err := s.renewTokenWithRetry(); err != nil {
 			log.Printf("[DeliveryVip] [%s] ERRO na renovação automática: %v", time.Now().Format("2006-01-02 15:04:05"), err)
 		} else {
 			log.Printf("[DeliveryVip] [%s] Token renovado com sucesso!", time.Now().Format("2006-01-02 15:04:05"))
@@ -167,8 +275,18 @@ func (s *DeliveryVipService) startTokenRenewal() {
 	}
 }
 
-// renewToken faz o login OAuth e atualiza o token de acesso
-func (s *DeliveryVipService) renewToken() error {
+// deliveryVipAccount identifica a única conta do DeliveryVip nas métricas
+const deliveryVipAccount = "padrao"
+
+// renewTokenWithRetry renova o token, tentando novamente enquanto a autenticação estiver indisponível
+func (s *DeliveryVipService) renewTokenWithRetry() error {
+	return renewWithRetry("[DeliveryVip]", loginRetryDelay, s.renewToken)
+}
+
+// renewToken faz o login OAuth e atualiza o token de acesso, registrando o resultado nas métricas
+func (s *DeliveryVipService) renewToken() (err error) {
+	defer func() { metrics.RecordTokenRenewal(string(models.PlataformaDeliveryVip), deliveryVipAccount, err) }()
+
 	tokenURL := fmt.Sprintf("%s/authentication/v1/oauth/token", s.config.Platforms.DeliveryVipURL)
 
 	// Prepara os dados do formulário
@@ -177,7 +295,11 @@ func (s *DeliveryVipService) renewToken() error {
 	data.Set("client_id", s.config.Platforms.DeliveryVip.ClientID)
 	data.Set("client_secret", s.config.Platforms.DeliveryVip.ClientSecret)
 
-	req, err := http.NewRequest("POST", tokenURL, strings.NewReader(data.Encode()))
+	// A requisição de token tem um timeout próprio, menor que o do cliente
+	ctx, cancel := context.WithTimeout(context.Background(), s.config.Platforms.DeliveryVip.LoginTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "POST", tokenURL, strings.NewReader(data.Encode()))
 	if err != nil {
 		return fmt.Errorf("erro ao criar requisição de token: %w", err)
 	}
@@ -191,17 +313,12 @@ func (s *DeliveryVipService) renewToken() error {
 	}
 	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return fmt.Errorf("erro ao ler resposta do token: %w", err)
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("erro de autenticação OAuth - Status: %d, Resposta: %s", resp.StatusCode, string(body))
+	if err := checkLoginResponse(resp); err != nil {
+		return fmt.Errorf("erro de autenticação OAuth: %w", err)
 	}
 
 	var tokenResp DeliveryVipTokenResponse
-	if err := json.Unmarshal(body, &tokenResp); err != nil {
+	if err := decodeLoginResponse(resp, &tokenResp); err != nil {
 		return fmt.Errorf("erro ao decodificar resposta do token: %w", err)
 	}
 
@@ -209,6 +326,7 @@ func (s *DeliveryVipService) renewToken() error {
 	s.tokenMutex.Lock()
 	s.accessToken = tokenResp.AccessToken
 	s.tokenMutex.Unlock()
+	s.tokenReady.notify()
 
 	expiresAt := time.Now().Add(time.Duration(tokenResp.ExpiresIn) * time.Second)
 	log.Printf("[DeliveryVip] [%s] Token obtido com sucesso! Expira em: %d segundos (%s)",
@@ -230,35 +348,86 @@ func (s *DeliveryVipService) getAccessToken() string {
 	return s.accessToken
 }
 
+// waitAccessToken retorna o token de acesso, aguardando até TOKEN_WAIT_TIMEOUT pela primeira
+// autenticação quando ele ainda não está disponível. Com TOKEN_WAIT_TIMEOUT=0 a renovação é feita
+// na hora, dentro da requisição
+func (s *DeliveryVipService) waitAccessToken() string {
+	if token := s.getAccessToken(); token != "" {
+		return token
+	}
+	if s.config.Platforms.TokenWaitTimeout == 0 {
+		if err := s.tokenReady.renewNow(s.renewToken); err != nil {
+			log.Printf("%s ERRO na renovação síncrona do token: %v", "[DeliveryVip]", err)
+			return ""
+		}
+		return s.getAccessToken()
+	}
+	if s.tokenReady.wait(s.config.Platforms.TokenWaitTimeout) {
+		return s.getAccessToken()
+	}
+	return ""
+}
+
+// setTokenHeader envia o token no header e formato declarados em TokenHeader
+func (s *DeliveryVipService) setTokenHeader(req *http.Request, token string) {
+	tokenHeader := s.config.Platforms.DeliveryVip.TokenHeader
+	req.Header.Set(tokenHeader.Name, tokenHeader.Value(token))
+}
+
+// checkBlockResponse interpreta a resposta do block/unblock conforme a semântica de respostas do
+// DeliveryVip. Um código de "já está no status" no corpo retorna ErrLojaJaNoStatus, mesmo com status
+// HTTP de erro, e um código de erro conhecido é tratado como falha, mesmo com status HTTP de sucesso
+func checkBlockResponse(resp *http.Response, merchantID string, alvo models.Status, semantics config.ResponseSemantics) error {
+	body, _ := io.ReadAll(resp.Body)
+	codigo := deliveryVipErrorCode(string(body), semantics.ErrorFields)
+
+	if status, ok := deliveryVipAlreadyInStateCodes[codigo]; ok && status == alvo {
+		log.Printf("[DeliveryVip] Loja %s já estava com status %s (%s)", merchantID, alvo, codigo)
+		return ErrLojaJaNoStatus
+	}
+
+	_, erroConhecido := deliveryVipErrorCodes[codigo]
+	if !semantics.IsSuccessStatus(resp.StatusCode) || erroConhecido {
+		return NewDeliveryVipError(resp.StatusCode, codigo, string(body))
+	}
+	return nil
+}
+
 // ActivateStore desbloqueia uma loja no DeliveryVip
-func (s *DeliveryVipService) ActivateStore(merchantID string) error {
-	token := s.getAccessToken()
+func (s *DeliveryVipService) ActivateStore(ctx context.Context, merchantID string) error {
+	if err := checkStoreID(merchantID); err != nil {
+		return err
+	}
+
+	token := s.waitAccessToken()
 	if token == "" {
 		log.Printf("[DeliveryVip] [%s] ERRO: Tentativa de desbloquear loja %s sem token válido", time.Now().Format("2006-01-02 15:04:05"), merchantID)
-		return fmt.Errorf("token de acesso não disponível")
+		return errTokenIndisponivel()
 	}
 
+	ctx, cancel := context.WithTimeout(ctx, s.config.Platforms.DeliveryVip.MutationTimeout)
+	defer cancel()
+
 	unblockURL := fmt.Sprintf("%s/partner/v2/merchants/%s/unblock", s.config.Platforms.DeliveryVipURL, merchantID)
 
-	req, err := http.NewRequest("POST", unblockURL, nil)
+	req, err := http.NewRequestWithContext(ctx, "POST", unblockURL, nil)
 	if err != nil {
 		return fmt.Errorf("erro ao criar requisição de desbloqueio: %w", err)
 	}
 
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+	s.setTokenHeader(req, token)
 	req.Header.Set("Accept", "*/*")
 
 	log.Printf("[DeliveryVip] Desbloqueando loja: %s", merchantID)
 
 	resp, err := s.httpClient.Do(req)
 	if err != nil {
-		return fmt.Errorf("erro ao fazer requisição de desbloqueio: %w", err)
+		return wrapCall(models.EtapaOperacao, req, 0, fmt.Errorf("erro ao fazer requisição de desbloqueio: %w", err))
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusAccepted {
-		body, _ := io.ReadAll(resp.Body)
-		return NewDeliveryVipError(resp.StatusCode, string(body))
+	if err := checkBlockResponse(resp, merchantID, models.StatusAtivo, s.config.Platforms.DeliveryVip.Responses); err != nil {
+		return wrapCall(models.EtapaOperacao, req, resp.StatusCode, err)
 	}
 
 	log.Printf("[DeliveryVip] Loja %s desbloqueada com sucesso", merchantID)
@@ -266,138 +435,201 @@ func (s *DeliveryVipService) ActivateStore(merchantID string) error {
 }
 
 // DeactivateStore bloqueia uma loja no DeliveryVip
-func (s *DeliveryVipService) DeactivateStore(merchantID string) error {
-	token := s.getAccessToken()
+// Se motivo for informado, é enviado no corpo da requisição de bloqueio
+func (s *DeliveryVipService) DeactivateStore(ctx context.Context, merchantID, motivo string) error {
+	if err := checkStoreID(merchantID); err != nil {
+		return err
+	}
+
+	token := s.waitAccessToken()
 	if token == "" {
 		log.Printf("[DeliveryVip] [%s] ERRO: Tentativa de bloquear loja %s sem token válido", time.Now().Format("2006-01-02 15:04:05"), merchantID)
-		return fmt.Errorf("token de acesso não disponível")
+		return errTokenIndisponivel()
 	}
 
+	ctx, cancel := context.WithTimeout(ctx, s.config.Platforms.DeliveryVip.MutationTimeout)
+	defer cancel()
+
 	blockURL := fmt.Sprintf("%s/partner/v2/merchants/%s/block", s.config.Platforms.DeliveryVipURL, merchantID)
 
-	req, err := http.NewRequest("POST", blockURL, nil)
+	var body io.Reader
+	if motivo != "" {
+		payload, err := json.Marshal(DeliveryVipBlockRequest{Reason: motivo})
+		if err != nil {
+			return fmt.Errorf("erro ao serializar motivo do bloqueio: %w", err)
+		}
+		body = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", blockURL, body)
 	if err != nil {
 		return fmt.Errorf("erro ao criar requisição de bloqueio: %w", err)
 	}
 
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+	s.setTokenHeader(req, token)
 	req.Header.Set("Accept", "*/*")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
 
 	log.Printf("[DeliveryVip] Bloqueando loja: %s", merchantID)
 
 	resp, err := s.httpClient.Do(req)
 	if err != nil {
-		return fmt.Errorf("erro ao fazer requisição de bloqueio: %w", err)
+		return wrapCall(models.EtapaOperacao, req, 0, fmt.Errorf("erro ao fazer requisição de bloqueio: %w", err))
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusAccepted {
-		body, _ := io.ReadAll(resp.Body)
-		log.Printf("[DeliveryVip] Erro ao bloquear loja %s - Status: %d, Body: %s", merchantID, resp.StatusCode, string(body))
-		return NewDeliveryVipError(resp.StatusCode, string(body))
+	if err := checkBlockResponse(resp, merchantID, models.StatusBloqueado, s.config.Platforms.DeliveryVip.Responses); err != nil {
+		if !errors.Is(err, ErrLojaJaNoStatus) {
+			log.Printf("[DeliveryVip] Erro ao bloquear loja %s - Status: %d: %v", merchantID, resp.StatusCode, err)
+		}
+		return wrapCall(models.EtapaOperacao, req, resp.StatusCode, err)
 	}
 
 	log.Printf("[DeliveryVip] Loja %s bloqueada com sucesso", merchantID)
 	return nil
 }
 
-// StoreStatusResult representa o resultado do status de uma loja
-type StoreStatusResult struct {
-	Found    bool
-	IsActive bool
-}
-
-// GetMultipleStoreStatus consulta o status de múltiplas lojas no DeliveryVip
-// Se merchantIDs for nil ou vazio, retorna o status de todas as lojas
-func (s *DeliveryVipService) GetMultipleStoreStatus(merchantIDs []string) (map[string]models.StoreInfo, error) {
-	token := s.getAccessToken()
+// Ping faz uma consulta mínima de merchants para validar o token e a conectividade,
+// retornando o status HTTP recebido da plataforma
+func (s *DeliveryVipService) Ping() (int, error) {
+	token := s.waitAccessToken()
 	if token == "" {
-		log.Printf("[DeliveryVip] [%s] ERRO: Tentativa de consultar status das lojas sem token válido", time.Now().Format("2006-01-02 15:04:05"))
-		return nil, fmt.Errorf("token de acesso não disponível")
+		return 0, errTokenIndisponivel()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.config.Platforms.DeliveryVip.StatusTimeout)
+	defer cancel()
+
+	merchantsURL := fmt.Sprintf("%s/partner/v2/merchants?limit=1", s.config.Platforms.DeliveryVipURL)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", merchantsURL, nil)
+	if err != nil {
+		return 0, fmt.Errorf("erro ao criar requisição de ping: %w", err)
 	}
 
+	s.setTokenHeader(req, token)
+	req.Header.Set("Accept", "*/*")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("erro ao fazer requisição de ping: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	return resp.StatusCode, nil
+}
+
+// listDeliveryVipMerchants busca a listagem de merchants, decodificando cada merchant em T
+func listDeliveryVipMerchants[T any](s *DeliveryVipService, token string) (*DeliveryVipListResponse[T], error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.config.Platforms.DeliveryVip.StatusTimeout)
+	defer cancel()
+
 	merchantsURL := fmt.Sprintf("%s/partner/v2/merchants", s.config.Platforms.DeliveryVipURL)
 
-	req, err := http.NewRequest("GET", merchantsURL, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", merchantsURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("erro ao criar requisição de consulta: %w", err)
 	}
 
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+	s.setTokenHeader(req, token)
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept", "*/*")
 
-	if len(merchantIDs) == 0 {
-		log.Printf("[DeliveryVip] Consultando todas as lojas da plataforma")
-	} else {
-		log.Printf("[DeliveryVip] Consultando todas as lojas para filtrar %d IDs solicitados", len(merchantIDs))
-	}
-
 	resp, err := s.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("erro ao fazer requisição de consulta: %w", err)
+		return nil, wrapCall(models.EtapaStatus, req, 0, fmt.Errorf("erro ao fazer requisição de consulta: %w", err))
 	}
 	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("erro ao ler resposta de consulta: %w", err)
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, wrapCall(models.EtapaStatus, req, resp.StatusCode, fmt.Errorf("erro ao consultar merchants - Status: %d, Resposta: %s", resp.StatusCode, string(body)))
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("erro ao consultar merchants - Status: %d, Resposta: %s", resp.StatusCode, string(body))
+	var merchantsResp DeliveryVipListResponse[T]
+	if err := decodeJSON(resp, &merchantsResp); err != nil {
+		return nil, wrapCall(models.EtapaStatus, req, resp.StatusCode, fmt.Errorf("erro ao decodificar resposta de merchants: %w", err))
 	}
+	return &merchantsResp, nil
+}
 
-	var merchants []DeliveryVipMerchant
-	if err := json.Unmarshal(body, &merchants); err != nil {
-		return nil, fmt.Errorf("erro ao decodificar resposta de merchants: %w", err)
+// GetRawMerchant retorna o JSON do merchant exatamente como listado pelo DeliveryVip, para
+// diagnóstico. Retorna ErrLojaNaoEncontrada se o merchant não estiver na listagem
+func (s *DeliveryVipService) GetRawMerchant(merchantID string) (json.RawMessage, error) {
+	token := s.waitAccessToken()
+	if token == "" {
+		return nil, errTokenIndisponivel()
 	}
 
-	storeMap := make(map[string]models.StoreInfo)
+	merchantsResp, err := listDeliveryVipMerchants[json.RawMessage](s, token)
+	if err != nil {
+		return nil, err
+	}
 
-	// Se nenhum ID específico foi solicitado, retorna todas as lojas
-	if len(merchantIDs) == 0 {
-		for _, merchant := range merchants {
-			// Usa o novo mapeamento de status
-			status := s.mapSubscriptionToStatus(merchant.Subscription.Status, merchant.Subscription.Blocked)
-			// Considera ativo apenas se o status for realmente ativo
-			isActive := status == models.StatusAtivo
-			storeMap[merchant.ID] = models.StoreInfo{
-				Found:        true,
-				IsActive:     isActive,
-				Status:       status,
-				Documento:    utils.CleanDocument(merchant.Identifier),
-				NomeFantasia: merchant.Name,
-			}
+	for _, raw := range merchantsResp.Data {
+		var merchant struct {
+			ID string `json:"id"`
 		}
-		log.Printf("[DeliveryVip] Status consultado: %d lojas encontradas", len(storeMap))
-		return storeMap, nil
+		if err := json.Unmarshal(raw, &merchant); err == nil && merchant.ID == merchantID {
+			return raw, nil
+		}
+	}
+	return nil, ErrLojaNaoEncontrada
+}
+
+// GetMultipleStoreStatus consulta o status de múltiplas lojas no DeliveryVip
+// Se merchantIDs for nil ou vazio, retorna o status de todas as lojas
+func (s *DeliveryVipService) GetMultipleStoreStatus(merchantIDs []string) (map[string]models.StoreInfo, error) {
+	token := s.waitAccessToken()
+	if token == "" {
+		log.Printf("[DeliveryVip] [%s] ERRO: Tentativa de consultar status das lojas sem token válido", time.Now().Format("2006-01-02 15:04:05"))
+		return nil, errTokenIndisponivel()
+	}
+
+	if len(merchantIDs) == 0 {
+		log.Printf("[DeliveryVip] Consultando todas as lojas da plataforma")
+	} else {
+		log.Printf("[DeliveryVip] Consultando todas as lojas para filtrar %d IDs solicitados", len(merchantIDs))
 	}
 
-	// Cria um set dos IDs solicitados para busca rápida
-	requestedIDs := make(map[string]bool)
+	merchantsResp, err := listDeliveryVipMerchants[DeliveryVipMerchant](s, token)
+	if err != nil {
+		return nil, err
+	}
+	merchants := merchantsResp.Data
+
+	if pagination := merchantsResp.Pagination; pagination != nil && pagination.TotalPages > 1 {
+		log.Printf("[DeliveryVip] AVISO: listagem de merchants paginada (página %d de %d, %d merchants no total) - apenas a página retornada foi considerada",
+			pagination.Page, pagination.TotalPages, pagination.Total)
+	}
+
+	// Um catálogo vazio é válido (ex.: parceiro recém-criado), mas é registrado à parte para não
+	// ser confundido com um filtro que não encontrou as lojas solicitadas
+	if len(merchants) == 0 {
+		log.Printf("[DeliveryVip] AVISO: a plataforma retornou um catálogo vazio (nenhum merchant cadastrado para o parceiro)")
+	}
+
+	// Cria um set dos IDs solicitados para busca rápida; sem IDs, todas as lojas são retornadas
+	requestedIDs := make(map[string]bool, len(merchantIDs))
 	for _, id := range merchantIDs {
 		requestedIDs[id] = true
 	}
 
-	// Filtra apenas as lojas solicitadas e constrói mapa de status
+	storeMap := make(map[string]models.StoreInfo)
 	for _, merchant := range merchants {
-		// Só processa se o ID foi solicitado
-		if requestedIDs[merchant.ID] {
-			// Usa o novo mapeamento de status
-			status := s.mapSubscriptionToStatus(merchant.Subscription.Status, merchant.Subscription.Blocked)
-			// Considera ativo apenas se o status for realmente ativo
-			isActive := status == models.StatusAtivo
-			storeMap[merchant.ID] = models.StoreInfo{
-				Found:        true,
-				IsActive:     isActive,
-				Status:       status,
-				Documento:    utils.CleanDocument(merchant.Identifier),
-				NomeFantasia: merchant.Name,
-			}
+		if len(merchantIDs) == 0 || requestedIDs[merchant.ID] {
+			storeMap[merchant.ID] = s.storeInfoFromMerchant(merchant)
 		}
 	}
 
+	if len(merchantIDs) == 0 {
+		log.Printf("[DeliveryVip] Status consultado: %d lojas encontradas", len(storeMap))
+		return storeMap, nil
+	}
+
 	// Adiciona lojas não encontradas
 	for _, id := range merchantIDs {
 		if _, exists := storeMap[id]; !exists {
@@ -415,6 +647,23 @@ func (s *DeliveryVipService) GetMultipleStoreStatus(merchantIDs []string) (map[s
 	return storeMap, nil
 }
 
+// storeInfoFromMerchant monta as informações da loja a partir do merchant listado pelo DeliveryVip.
+// A loja só é considerada ativa quando o status mapeado da assinatura é realmente ativo
+func (s *DeliveryVipService) storeInfoFromMerchant(merchant DeliveryVipMerchant) models.StoreInfo {
+	status := s.mapSubscriptionToStatus(merchant.Subscription.Status, merchant.Subscription.Blocked)
+	documento := utils.CleanDocument(merchant.Identifier)
+	return models.StoreInfo{
+		Found:              true,
+		IsActive:           status == models.StatusAtivo,
+		Status:             status,
+		Documento:          documento,
+		DocumentoValido:    utils.IsValidDocument(documento, ""),
+		NomeFantasia:       merchant.Name,
+		SubscriptionStatus: merchant.Subscription.Status,
+		Blocked:            merchant.Subscription.Blocked,
+	}
+}
+
 // countNotFoundStores conta quantas lojas não foram encontradas
 func countNotFoundStores(storeMap map[string]models.StoreInfo) int {
 	count := 0
@@ -0,0 +1,174 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"delivery-control/internal/config"
+	"delivery-control/internal/models"
+	"delivery-control/internal/testutil/fakeplatform"
+)
+
+func TestDeliveryVipMerchantsResponseShapes(t *testing.T) {
+	merchants := []map[string]any{
+		fakeplatform.DeliveryVipMerchant("merchant-ativo", "Loja Ativa", "12345678000190", "ACTIVATED", false),
+		fakeplatform.DeliveryVipMerchant("merchant-teste", "Loja em Teste", "12345678000190", "TRIAL", false),
+	}
+
+	tests := []struct {
+		name string
+		body any
+	}{
+		{name: "array simples", body: merchants},
+		{name: "objeto com data e pagination", body: fakeplatform.DeliveryVipMerchantsPage(1, 1, len(merchants), merchants...)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ps, _, deliveryVip := newTestService(t)
+			deliveryVip.SetResponse(fakeplatform.RouteDeliveryVipMerchants, fakeplatform.JSON(http.StatusOK, tt.body))
+
+			resposta, err := ps.GetMultipleStoreStatus(context.Background(), models.PlataformaDeliveryVip, nil, false, models.OrdenarPorID)
+			if err != nil {
+				t.Fatalf("GetMultipleStoreStatus() erro: %v", err)
+			}
+
+			got := statusByID(resposta.Lojas)
+			want := map[string]models.Status{
+				"merchant-ativo": models.StatusAtivo,
+				"merchant-teste": models.StatusEmTeste,
+			}
+			if len(got) != len(want) {
+				t.Fatalf("lojas = %v, esperado %v", got, want)
+			}
+			for id, status := range want {
+				if got[id] != status {
+					t.Errorf("status do merchant %s = %q, esperado %q", id, got[id], status)
+				}
+			}
+		})
+	}
+}
+
+func TestDeliveryVipBlockResponseStatuses(t *testing.T) {
+	tests := []struct {
+		name    string
+		resp    fakeplatform.Response
+		wantErr bool
+	}{
+		{name: "200", resp: fakeplatform.JSON(http.StatusOK, map[string]any{"merchantId": "merchant-1"})},
+		{name: "202", resp: fakeplatform.Response{Status: http.StatusAccepted}},
+		{name: "204", resp: fakeplatform.Response{Status: http.StatusNoContent}},
+		{name: "404", resp: fakeplatform.JSON(http.StatusNotFound, map[string]any{"message": "merchant not found"}), wantErr: true},
+		{name: "422", resp: fakeplatform.JSON(http.StatusUnprocessableEntity, map[string]any{"message": "invalid merchant"}), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ps, _, deliveryVip := newTestService(t)
+			deliveryVip.SetResponse(fakeplatform.RouteDeliveryVipBlock, tt.resp)
+			deliveryVip.SetResponse(fakeplatform.RouteDeliveryVipUnblock, tt.resp)
+
+			operacoes := map[string]func() error{
+				"block":   func() error { return ps.deliveryVipService.DeactivateStore(context.Background(), "merchant-1", "") },
+				"unblock": func() error { return ps.deliveryVipService.ActivateStore(context.Background(), "merchant-1") },
+			}
+			for nome, operacao := range operacoes {
+				err := operacao()
+				if tt.wantErr && err == nil {
+					t.Errorf("%s com status %s deveria falhar", nome, tt.name)
+				}
+				if !tt.wantErr && err != nil {
+					t.Errorf("%s com status %s deveria ter sucesso, erro: %v", nome, tt.name, err)
+				}
+			}
+		})
+	}
+}
+
+func TestDeliveryVipEmptyMerchants(t *testing.T) {
+	ps, _, deliveryVip := newTestService(t)
+	deliveryVip.SetResponse(fakeplatform.RouteDeliveryVipMerchants, fakeplatform.JSON(http.StatusOK, []any{}))
+
+	resposta, err := ps.GetMultipleStoreStatus(context.Background(), models.PlataformaDeliveryVip, []string{"merchant-1", "merchant-2"}, false, models.OrdenarPorID)
+	if err != nil {
+		t.Fatalf("GetMultipleStoreStatus() erro: %v", err)
+	}
+	if !resposta.CatalogoVazio {
+		t.Error("catalogo_vazio deveria ser true quando a plataforma não lista nenhum merchant")
+	}
+	for id, status := range statusByID(resposta.Lojas) {
+		if status != models.StatusNaoEncontrado {
+			t.Errorf("status do merchant %s = %q, esperado %q", id, status, models.StatusNaoEncontrado)
+		}
+	}
+
+	resposta, err = ps.GetMultipleStoreStatus(context.Background(), models.PlataformaDeliveryVip, nil, false, models.OrdenarPorID)
+	if err != nil {
+		t.Fatalf("GetMultipleStoreStatus() sem IDs erro: %v", err)
+	}
+	if !resposta.CatalogoVazio || len(resposta.Lojas) != 0 {
+		t.Errorf("sem IDs: catalogo_vazio=%v lojas=%d, esperado catálogo vazio sem lojas", resposta.CatalogoVazio, len(resposta.Lojas))
+	}
+}
+
+func TestDeliveryVipAlreadyInDesiredStateIsSuccess(t *testing.T) {
+	tests := []struct {
+		name       string
+		route      string
+		codigo     string
+		operacao   func(ps *PlatformService) (*models.RespostaOperacaoMultiplasLojas, error)
+		wantStatus models.Status
+		wantOK     bool
+	}{
+		{
+			name:   "bloqueio de loja já bloqueada",
+			route:  fakeplatform.RouteDeliveryVipBlock,
+			codigo: "MERCHANT_ALREADY_BLOCKED",
+			operacao: func(ps *PlatformService) (*models.RespostaOperacaoMultiplasLojas, error) {
+				return ps.DeactivateMultipleStores(context.Background(), string(models.PlataformaDeliveryVip), []string{"merchant-1"}, "")
+			},
+			wantStatus: models.StatusBloqueado,
+			wantOK:     true,
+		},
+		{
+			name:   "desbloqueio de loja já desbloqueada",
+			route:  fakeplatform.RouteDeliveryVipUnblock,
+			codigo: "MERCHANT_ALREADY_UNBLOCKED",
+			operacao: func(ps *PlatformService) (*models.RespostaOperacaoMultiplasLojas, error) {
+				return ps.ActivateMultipleStores(context.Background(), string(models.PlataformaDeliveryVip), []string{"merchant-1"})
+			},
+			wantStatus: models.StatusAtivo,
+			wantOK:     true,
+		},
+		{
+			name:   "código de outro status não é sucesso",
+			route:  fakeplatform.RouteDeliveryVipBlock,
+			codigo: "MERCHANT_ALREADY_UNBLOCKED",
+			operacao: func(ps *PlatformService) (*models.RespostaOperacaoMultiplasLojas, error) {
+				return ps.DeactivateMultipleStores(context.Background(), string(models.PlataformaDeliveryVip), []string{"merchant-1"}, "")
+			},
+			wantStatus: models.StatusErro,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ps, _, deliveryVip := newTestService(t, func(cfg *config.Config) { cfg.Bulk.MaxRetries = 0 })
+			deliveryVip.SetResponse(tt.route, fakeplatform.JSON(http.StatusBadRequest, map[string]any{"code": tt.codigo}))
+
+			resposta, err := tt.operacao(ps)
+			if err != nil {
+				t.Fatalf("operação em lote retornou erro: %v", err)
+			}
+			resultado := resposta.Resultados[0]
+			if resultado.Sucesso != tt.wantOK || resultado.Status != tt.wantStatus {
+				t.Errorf("sucesso=%v status=%q, esperado sucesso=%v status=%q", resultado.Sucesso, resultado.Status, tt.wantOK, tt.wantStatus)
+			}
+			if tt.wantOK && resultado.Mensagem != mensagensJaNoStatus[tt.wantStatus] {
+				t.Errorf("mensagem = %q, esperado %q", resultado.Mensagem, mensagensJaNoStatus[tt.wantStatus])
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package services
+
+import (
+	"slices"
+	"strings"
+
+	"delivery-control/internal/models"
+	"delivery-control/internal/utils"
+)
+
+// FindDuplicateDocuments agrupa as lojas de cada plataforma pelo documento (CPF/CNPJ) normalizado
+// (utils.NormalizeDocument, que completa os zeros à esquerda perdidos) e retorna os documentos presentes
+// em mais de uma plataforma, ordenados pelo documento. Lojas sem documento ou com documento inválido
+// são ignoradas; um documento repetido apenas dentro da mesma plataforma não é duplicado. As lojas são
+// devolvidas no DTO Loja; LegacyDuplicates converte para o formato anterior
+func FindDuplicateDocuments(lojasPorPlataforma map[models.Plataforma][]models.StatusLojaDetalhes) []models.DocumentoDuplicado {
+	porDocumento := make(map[string][]models.Loja)
+	for plataforma, lojas := range lojasPorPlataforma {
+		for _, loja := range lojas {
+			documento, err := utils.NormalizeDocument(loja.Documento, "")
+			if err != nil {
+				continue
+			}
+			porDocumento[documento] = append(porDocumento[documento], NewLoja(plataforma, loja))
+		}
+	}
+
+	duplicadas := []models.DocumentoDuplicado{}
+	for documento, lojas := range porDocumento {
+		if !inMultiplePlatforms(lojas) {
+			continue
+		}
+		slices.SortFunc(lojas, func(a, b models.Loja) int {
+			if c := strings.Compare(string(a.Plataforma), string(b.Plataforma)); c != 0 {
+				return c
+			}
+			return strings.Compare(a.Id, b.Id)
+		})
+		duplicadas = append(duplicadas, models.DocumentoDuplicado{Documento: documento, Lojas: lojas})
+	}
+	slices.SortFunc(duplicadas, func(a, b models.DocumentoDuplicado) int {
+		return strings.Compare(a.Documento, b.Documento)
+	})
+	return duplicadas
+}
+
+// inMultiplePlatforms indica se as lojas pertencem a mais de uma plataforma
+func inMultiplePlatforms(lojas []models.Loja) bool {
+	for _, loja := range lojas[1:] {
+		if loja.Plataforma != lojas[0].Plataforma {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,32 @@
+package services
+
+import (
+	"testing"
+
+	"delivery-control/internal/models"
+)
+
+func TestFindDuplicateDocumentsMatchesNormalizedDocuments(t *testing.T) {
+	lojas := map[models.Plataforma][]models.StatusLojaDetalhes{
+		models.PlataformaAnotaAi: {
+			{IdLoja: "page-1", Documento: "1234567000190"},
+			{IdLoja: "page-2", Documento: "123"},
+			{IdLoja: "page-3", Documento: ""},
+		},
+		models.PlataformaDeliveryVip: {
+			{IdLoja: "merchant-1", Documento: "01.234.567/0001-90"},
+			{IdLoja: "merchant-2", Documento: "123"},
+		},
+	}
+
+	duplicadas := FindDuplicateDocuments(lojas)
+	if len(duplicadas) != 1 {
+		t.Fatalf("FindDuplicateDocuments() = %+v, esperado apenas o CNPJ completado com zero", duplicadas)
+	}
+	if duplicadas[0].Documento != "01234567000190" {
+		t.Errorf("documento = %q, esperado o CNPJ normalizado", duplicadas[0].Documento)
+	}
+	if len(duplicadas[0].Lojas) != 2 {
+		t.Errorf("lojas = %+v, esperadas page-1 e merchant-1", duplicadas[0].Lojas)
+	}
+}
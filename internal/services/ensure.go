@@ -0,0 +1,89 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"delivery-control/internal/models"
+)
+
+// EnsureStoresStatus leva as lojas ao status alvo (ativo ou bloqueado) chamando a plataforma apenas
+// para as que ainda não estão nele. O status atual é consultado sem cache; lojas já no status alvo e
+// lojas que não existem na plataforma são devolvidas com acao "ignorada", e as demais passam pela
+// ativação/desativação em lote normal, com acao "executada"
+func (ps *PlatformService) EnsureStoresStatus(ctx context.Context, plataforma string, idsLojas []string, alvo models.Status, motivo string) (*models.RespostaOperacaoMultiplasLojas, error) {
+	operacao := models.OperacaoAtivar
+	if alvo == models.StatusBloqueado {
+		operacao = models.OperacaoDesativar
+	}
+	if err := ps.checkOperation(models.Plataforma(plataforma), models.OperacaoStatus); err != nil {
+		return nil, err
+	}
+	if err := ps.checkOperation(models.Plataforma(plataforma), operacao); err != nil {
+		return nil, err
+	}
+	if err := ps.checkConfigured(models.Plataforma(plataforma)); err != nil {
+		return nil, err
+	}
+
+	catalog, err := ps.fetchCatalog(ctx, models.Plataforma(plataforma))
+	if err != nil {
+		return nil, fmt.Errorf("erro ao consultar status das lojas em %s: %w", plataforma, err)
+	}
+
+	resultados := make(map[string]models.ResultadoOperacaoLoja, len(idsLojas))
+	var pendentes []string
+	for _, idLoja := range idsLojas {
+		switch statusLojaFromCatalog(catalog, idLoja).Status {
+		case alvo:
+			resultados[idLoja] = models.ResultadoOperacaoLoja{
+				IdLoja:   idLoja,
+				Status:   alvo,
+				Sucesso:  true,
+				Mensagem: mensagensJaNoStatus[alvo],
+				Acao:     models.AcaoIgnorada,
+			}
+		case models.StatusNaoEncontrado:
+			errType := models.ErroNaoEncontrado
+			resultados[idLoja] = models.ResultadoOperacaoLoja{
+				IdLoja:   idLoja,
+				Status:   models.StatusNaoEncontrado,
+				Mensagem: "Loja não encontrada na plataforma",
+				Erro:     &errType,
+				Acao:     models.AcaoIgnorada,
+			}
+		default:
+			pendentes = append(pendentes, idLoja)
+		}
+	}
+
+	if len(pendentes) > 0 {
+		var executadas *models.RespostaOperacaoMultiplasLojas
+		if alvo == models.StatusBloqueado {
+			executadas, err = ps.DeactivateMultipleStores(ctx, plataforma, pendentes, motivo)
+		} else {
+			executadas, err = ps.ActivateMultipleStores(ctx, plataforma, pendentes)
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, resultado := range executadas.Resultados {
+			resultado.Acao = models.AcaoExecutada
+			resultados[resultado.IdLoja] = resultado
+		}
+	}
+
+	response := &models.RespostaOperacaoMultiplasLojas{
+		Plataforma: models.Plataforma(plataforma),
+		Resultados: make([]models.ResultadoOperacaoLoja, 0, len(idsLojas)),
+	}
+	for _, idLoja := range idsLojas {
+		response.Resultados = append(response.Resultados, resultados[idLoja])
+	}
+	attachLojas(response)
+
+	log.Printf("[Garantir] plataforma=%s status=%s total=%d executadas=%d ignoradas=%d",
+		plataforma, alvo, len(idsLojas), len(pendentes), len(idsLojas)-len(pendentes))
+	return response, nil
+}
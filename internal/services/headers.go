@@ -0,0 +1,32 @@
+package services
+
+import (
+	"net/http"
+)
+
+// headerTransport adiciona os headers extras configurados para a plataforma a todas as requisições
+type headerTransport struct {
+	base    http.RoundTripper
+	headers map[string]string
+}
+
+// newPlatformClient cria o cliente HTTP de uma plataforma, enviando os headers extras em todas as requisições.
+// O cliente não tem timeout próprio: cada requisição usa o prazo do seu contexto (login, status ou alteração)
+func newPlatformClient(headers map[string]string) *http.Client {
+	client := &http.Client{}
+	if len(headers) > 0 {
+		client.Transport = &headerTransport{base: http.DefaultTransport, headers: headers}
+	}
+	return client
+}
+
+// RoundTrip implementa http.RoundTripper. Os headers definidos pelo serviço têm precedência
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	for name, value := range t.headers {
+		if req.Header.Get(name) == "" {
+			req.Header.Set(name, value)
+		}
+	}
+	return t.base.RoundTrip(req)
+}
@@ -0,0 +1,69 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"delivery-control/internal/models"
+)
+
+// IDMapper traduz os identificadores internos das lojas para os IDs de cada plataforma
+// (page_id no AnotaAI, merchant_id no DeliveryVip) e vice-versa.
+// IDs sem mapeamento são repassados sem alteração
+type IDMapper struct {
+	toPlatform   map[models.Plataforma]map[string]string
+	fromPlatform map[models.Plataforma]map[string]string
+}
+
+// NewIDMapper carrega o mapeamento de IDs do arquivo JSON informado no formato
+// {"anotaai": {"id-interno": "page_id"}, "deliveryvip": {"id-interno": "merchant_id"}}.
+// Se o caminho for vazio, retorna um mapeamento vazio (todos os IDs são repassados)
+func NewIDMapper(path string) (*IDMapper, error) {
+	mapper := &IDMapper{
+		toPlatform:   make(map[models.Plataforma]map[string]string),
+		fromPlatform: make(map[models.Plataforma]map[string]string),
+	}
+
+	if path == "" {
+		return mapper, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler arquivo de mapeamento de IDs: %w", err)
+	}
+
+	var raw map[string]map[string]string
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("erro ao decodificar arquivo de mapeamento de IDs: %w", err)
+	}
+
+	for plataforma, ids := range raw {
+		p := models.Plataforma(plataforma)
+		mapper.toPlatform[p] = make(map[string]string, len(ids))
+		mapper.fromPlatform[p] = make(map[string]string, len(ids))
+		for interno, externo := range ids {
+			mapper.toPlatform[p][interno] = externo
+			mapper.fromPlatform[p][externo] = interno
+		}
+	}
+
+	return mapper, nil
+}
+
+// ToPlatform converte um ID interno para o ID da plataforma
+func (m *IDMapper) ToPlatform(plataforma models.Plataforma, id string) string {
+	if externo, ok := m.toPlatform[plataforma][id]; ok {
+		return externo
+	}
+	return id
+}
+
+// FromPlatform converte um ID da plataforma para o ID interno
+func (m *IDMapper) FromPlatform(plataforma models.Plataforma, id string) string {
+	if interno, ok := m.fromPlatform[plataforma][id]; ok {
+		return interno
+	}
+	return id
+}
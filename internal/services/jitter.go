@@ -0,0 +1,16 @@
+package services
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+// renewalJitter é a variação máxima (para mais ou para menos) aplicada ao intervalo de
+// renovação de token, para que réplicas iniciadas juntas não renovem ao mesmo tempo
+const renewalJitter = 0.10
+
+// jitteredInterval retorna o intervalo base com uma variação aleatória de até ±renewalJitter
+func jitteredInterval(base time.Duration) time.Duration {
+	variacao := (rand.Float64()*2 - 1) * renewalJitter
+	return base + time.Duration(float64(base)*variacao)
+}
@@ -0,0 +1,67 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+)
+
+// ErrAutenticacaoIndisponivel indica que o endpoint de login/token da plataforma está fora do ar
+// (ex.: o gateway respondeu uma página HTML de erro). A renovação do token tenta novamente com backoff
+var ErrAutenticacaoIndisponivel = errors.New("autenticação indisponível")
+
+// Novas tentativas do login quando a autenticação está indisponível, com intervalo que dobra a cada falha
+const (
+	loginMaxRetries = 3
+	loginRetryDelay = 2 * time.Second
+)
+
+// checkLoginResponse valida o status da resposta do login antes da decodificação. Páginas HTML e
+// respostas 5xx retornam ErrAutenticacaoIndisponivel; os demais status são erros de autenticação
+// definitivos (ex.: credenciais inválidas), que não são repetidos
+func checkLoginResponse(resp *http.Response) error {
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBodySize))
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) > 0 && isHTML(resp.Header.Get("Content-Type"), trimmed) {
+		return fmt.Errorf("%w (gateway retornou HTML, status %d)", ErrAutenticacaoIndisponivel, resp.StatusCode)
+	}
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("%w (status %d): %s", ErrAutenticacaoIndisponivel, resp.StatusCode, truncateBody(trimmed))
+	}
+	return fmt.Errorf("status %d, resposta: %s", resp.StatusCode, truncateBody(trimmed))
+}
+
+// decodeLoginResponse decodifica a resposta do login, tratando uma página HTML com status 200
+// (alguns gateways respondem assim) como autenticação indisponível
+func decodeLoginResponse[T any](resp *http.Response, out *T) error {
+	if err := decodeJSON(resp, out); err != nil {
+		if errors.Is(err, errRespostaHTML) {
+			return fmt.Errorf("%w (gateway retornou HTML, status %d)", ErrAutenticacaoIndisponivel, resp.StatusCode)
+		}
+		return err
+	}
+	return nil
+}
+
+// renewWithRetry executa a renovação do token, tentando novamente até loginMaxRetries vezes
+// enquanto a autenticação estiver indisponível. Outros erros retornam imediatamente
+func renewWithRetry(logPrefix string, delay time.Duration, renew func() error) error {
+	for tentativa := 0; ; tentativa++ {
+		err := renew()
+		if err == nil || !errors.Is(err, ErrAutenticacaoIndisponivel) || tentativa >= loginMaxRetries {
+			return err
+		}
+
+		log.Printf("%s Autenticação indisponível (tentativa %d), nova tentativa em %s: %v", logPrefix, tentativa+1, delay, err)
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
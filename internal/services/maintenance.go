@@ -0,0 +1,63 @@
+package services
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"delivery-control/internal/models"
+)
+
+// MaintenanceMode guarda o estado do modo de manutenção, em que as operações de ativação e
+// desativação são recusadas e as consultas continuam funcionando. Pode ser alterado em tempo
+// de execução, por isso o acesso é protegido por mutex
+type MaintenanceMode struct {
+	mu     sync.RWMutex
+	estado models.EstadoManutencao
+}
+
+// NewMaintenanceMode cria o modo de manutenção com o estado inicial de MAINTENANCE_MODE
+func NewMaintenanceMode(ativo bool) *MaintenanceMode {
+	m := &MaintenanceMode{}
+	if ativo {
+		m.Set(true, "MAINTENANCE_MODE=true")
+	}
+	return m
+}
+
+// Active indica se o modo de manutenção está ativo
+func (m *MaintenanceMode) Active() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.estado.Ativo
+}
+
+// State retorna uma cópia do estado atual
+func (m *MaintenanceMode) State() models.EstadoManutencao {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.estado
+}
+
+// Set ativa ou desativa o modo de manutenção, retornando o novo estado. Reativar o modo já
+// ativo apenas atualiza o motivo, mantendo o horário de início
+func (m *MaintenanceMode) Set(ativo bool, motivo string) models.EstadoManutencao {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	switch {
+	case !ativo:
+		m.estado = models.EstadoManutencao{}
+	case m.estado.Ativo:
+		m.estado.Motivo = motivo
+	default:
+		m.estado = models.EstadoManutencao{Ativo: true, Motivo: motivo, Desde: time.Now().UTC().Format(time.RFC3339)}
+	}
+
+	if ativo {
+		log.Printf("[Manutencao] Modo de manutenção ativo: operações de ativação e desativação suspensas (motivo: %q)", motivo)
+	} else {
+		log.Printf("[Manutencao] Modo de manutenção desativado")
+	}
+	return m.estado
+}
@@ -0,0 +1,95 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"delivery-control/internal/config"
+	"delivery-control/internal/models"
+)
+
+// maxURLOverrides limita a quantidade de URLs alternativas em uso, já que cada uma mantém
+// a própria renovação de token enquanto o processo estiver em execução
+const maxURLOverrides = 10
+
+// overrideKey identifica uma URL alternativa de uma plataforma
+type overrideKey struct {
+	plataforma models.Plataforma
+	baseURL    string
+}
+
+// AllowURLOverride indica se as requisições podem escolher outra URL para a plataforma
+func (ps *PlatformService) AllowURLOverride() bool {
+	return ps.config.Debug.AllowURLOverride
+}
+
+// WithBaseURL retorna um serviço que envia as operações da plataforma para baseURL em vez da
+// URL configurada, usando as credenciais de sandbox (SANDBOX_*). O host de baseURL precisa estar em
+// URL_OVERRIDE_ALLOWED_HOSTS, e as credenciais de produção nunca são enviadas para a URL alternativa.
+// O serviço é criado na primeira requisição para cada URL e reaproveitado nas seguintes, com token
+// e cache de status próprios. As demais plataformas continuam usando os serviços originais
+func (ps *PlatformService) WithBaseURL(plataforma models.Plataforma, baseURL string) (*PlatformService, error) {
+	if !ps.config.Debug.AllowURLOverride {
+		return nil, fmt.Errorf("substituição da URL da plataforma desabilitada (ALLOW_URL_OVERRIDE=false)")
+	}
+	if !config.IsValidPlatformURL(baseURL) {
+		return nil, fmt.Errorf("URL da plataforma inválida: %q", baseURL)
+	}
+	if !ps.config.Debug.IsURLOverrideHostAllowed(baseURL) {
+		return nil, fmt.Errorf("host da URL da plataforma não permitido (URL_OVERRIDE_ALLOWED_HOSTS): %q", baseURL)
+	}
+	if !ps.isValidPlatform(plataforma) {
+		// A própria operação responde que a plataforma não é suportada
+		return ps, nil
+	}
+
+	key := overrideKey{plataforma, strings.TrimRight(baseURL, "/")}
+
+	ps.overridesMu.Lock()
+	defer ps.overridesMu.Unlock()
+
+	if override, ok := ps.overrides[key]; ok {
+		return override, nil
+	}
+	if len(ps.overrides) >= maxURLOverrides {
+		return nil, fmt.Errorf("limite de %d URLs alternativas atingido; reinicie o serviço para liberar", maxURLOverrides)
+	}
+
+	cfg := *ps.config
+	sandbox := cfg.Debug.Sandbox
+	override := &PlatformService{
+		config:             &cfg,
+		repositories:       ps.repositories,
+		anotaAiService:     ps.anotaAiService,
+		deliveryVipService: ps.deliveryVipService,
+		statusCache:        NewStatusCache(cfg.Cache.StatusTTL),
+		limiters:           newConcurrencyLimiters(cfg.Bulk, false),
+		// As operações feitas na URL alternativa também aparecem nas operações recentes
+		recentOps: ps.recentOps,
+	}
+	switch plataforma {
+	case models.PlataformaAnotaAi:
+		if !sandbox.HasAnotaAi() {
+			return nil, fmt.Errorf("credenciais de sandbox do AnotaAI não configuradas (SANDBOX_ANOTAAI_EMAIL e SANDBOX_ANOTAAI_PASSWORD)")
+		}
+		cfg.Platforms.AnotaAiURL = key.baseURL
+		// Apenas a conta de sandbox: as contas adicionais de ANOTAAI_ACCOUNTS são de produção
+		cfg.Platforms.AnotaAi.Email = sandbox.AnotaAiEmail
+		cfg.Platforms.AnotaAi.Password = sandbox.AnotaAiPassword
+		cfg.Platforms.AnotaAi.Accounts = nil
+		override.anotaAiService = NewAnotaAiAccounts(&cfg)
+	case models.PlataformaDeliveryVip:
+		if !sandbox.HasDeliveryVip() {
+			return nil, fmt.Errorf("credenciais de sandbox do DeliveryVip não configuradas (SANDBOX_DELIVERYVIP_CLIENT_ID e SANDBOX_DELIVERYVIP_CLIENT_SECRET)")
+		}
+		cfg.Platforms.DeliveryVipURL = key.baseURL
+		cfg.Platforms.DeliveryVip.ClientID = sandbox.DeliveryVipClientID
+		cfg.Platforms.DeliveryVip.ClientSecret = sandbox.DeliveryVipClientSecret
+		override.deliveryVipService = NewDeliveryVipService(&cfg)
+	}
+
+	ps.overrides[key] = override
+	log.Printf("[Override] Serviço criado para a plataforma %s na URL %s", plataforma, key.baseURL)
+	return override, nil
+}
@@ -0,0 +1,108 @@
+package services
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"testing"
+
+	"delivery-control/internal/config"
+	"delivery-control/internal/models"
+	"delivery-control/internal/testutil/fakeplatform"
+)
+
+// withURLOverride habilita X-Platform-Base-URL para o host local dos servidores falsos
+func withURLOverride(sandbox config.SandboxCredentials) func(*config.Config) {
+	return func(cfg *config.Config) {
+		cfg.Debug.AllowURLOverride = true
+		cfg.Debug.URLOverrideHosts = []string{"127.0.0.1"}
+		cfg.Debug.Sandbox = sandbox
+	}
+}
+
+func TestWithBaseURLUsesSandboxCredentials(t *testing.T) {
+	sandbox := config.SandboxCredentials{
+		AnotaAiEmail:            "sandbox@example.com",
+		AnotaAiPassword:         "senha-sandbox",
+		DeliveryVipClientID:     "sandbox-client-id",
+		DeliveryVipClientSecret: "sandbox-client-secret",
+	}
+	ps, anotaAi, deliveryVip := newTestService(t, withURLOverride(sandbox))
+	anotaAiSandbox := fakeplatform.NewAnotaAi()
+	deliveryVipSandbox := fakeplatform.NewDeliveryVip()
+	t.Cleanup(anotaAiSandbox.Close)
+	t.Cleanup(deliveryVipSandbox.Close)
+
+	override, err := ps.WithBaseURL(models.PlataformaAnotaAi, anotaAiSandbox.URL)
+	if err != nil {
+		t.Fatalf("WithBaseURL() do AnotaAI erro: %v", err)
+	}
+	if _, err := override.ActivateStore(context.Background(), models.PlataformaAnotaAi, "page-1"); err != nil {
+		t.Fatalf("ActivateStore() na sandbox erro: %v", err)
+	}
+	if calls := anotaAi.Calls(fakeplatform.RouteAnotaAiActivate); calls != 0 {
+		t.Errorf("ativações no AnotaAI de produção = %d, esperado 0", calls)
+	}
+	for _, login := range anotaAiSandbox.Requests(fakeplatform.RouteAnotaAiLogin) {
+		if !strings.Contains(login.Body, sandbox.AnotaAiEmail) || strings.Contains(login.Body, ps.config.Platforms.AnotaAi.Email) {
+			t.Errorf("login na sandbox do AnotaAI = %s, esperado apenas o email de sandbox", login.Body)
+		}
+	}
+
+	override, err = ps.WithBaseURL(models.PlataformaDeliveryVip, deliveryVipSandbox.URL)
+	if err != nil {
+		t.Fatalf("WithBaseURL() do DeliveryVip erro: %v", err)
+	}
+	if _, err := override.DeactivateStore(context.Background(), models.PlataformaDeliveryVip, "merchant-1", ""); err != nil {
+		t.Fatalf("DeactivateStore() na sandbox erro: %v", err)
+	}
+	if calls := deliveryVip.Calls(fakeplatform.RouteDeliveryVipBlock); calls != 0 {
+		t.Errorf("bloqueios no DeliveryVip de produção = %d, esperado 0", calls)
+	}
+	for _, login := range deliveryVipSandbox.Requests(fakeplatform.RouteDeliveryVipToken) {
+		form, err := url.ParseQuery(login.Body)
+		if err != nil || form.Get("client_id") != sandbox.DeliveryVipClientID || form.Get("client_secret") != sandbox.DeliveryVipClientSecret {
+			t.Errorf("login na sandbox do DeliveryVip = %s, esperado apenas as credenciais de sandbox", login.Body)
+		}
+	}
+	if len(anotaAiSandbox.Requests(fakeplatform.RouteAnotaAiLogin)) == 0 || len(deliveryVipSandbox.Requests(fakeplatform.RouteDeliveryVipToken)) == 0 {
+		t.Error("as sandboxes deveriam receber o login com as credenciais de sandbox")
+	}
+}
+
+func TestWithBaseURLRejectsUnsafeOverrides(t *testing.T) {
+	tests := []struct {
+		name       string
+		sandbox    config.SandboxCredentials
+		plataforma models.Plataforma
+		baseURL    string
+	}{
+		{
+			name:       "host fora de URL_OVERRIDE_ALLOWED_HOSTS",
+			sandbox:    config.SandboxCredentials{AnotaAiEmail: "sandbox@example.com", AnotaAiPassword: "senha"},
+			plataforma: models.PlataformaAnotaAi,
+			baseURL:    "https://atacante.example.com",
+		},
+		{
+			name:       "AnotaAI sem credenciais de sandbox",
+			sandbox:    config.SandboxCredentials{DeliveryVipClientID: "id", DeliveryVipClientSecret: "secret"},
+			plataforma: models.PlataformaAnotaAi,
+			baseURL:    "http://127.0.0.1:9090",
+		},
+		{
+			name:       "DeliveryVip sem credenciais de sandbox",
+			sandbox:    config.SandboxCredentials{AnotaAiEmail: "sandbox@example.com", AnotaAiPassword: "senha"},
+			plataforma: models.PlataformaDeliveryVip,
+			baseURL:    "http://127.0.0.1:9090",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ps, _, _ := newTestService(t, withURLOverride(tt.sandbox))
+			if _, err := ps.WithBaseURL(tt.plataforma, tt.baseURL); err == nil {
+				t.Error("WithBaseURL() deveria recusar a URL alternativa")
+			}
+		})
+	}
+}
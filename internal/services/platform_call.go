@@ -0,0 +1,54 @@
+package services
+
+import (
+	"errors"
+	"net/http"
+
+	"delivery-control/internal/models"
+)
+
+// PlatformCallError associa um erro à chamada à plataforma que falhou, para diagnosticar se a
+// falha veio do token, da consulta de status ou da operação na loja
+type PlatformCallError struct {
+	Chamada models.ChamadaPlataforma
+	Err     error
+}
+
+func (e *PlatformCallError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *PlatformCallError) Unwrap() error {
+	return e.Err
+}
+
+// wrapCall anota err com a chamada da etapa informada. Apenas o método e o caminho da URL são
+// guardados: host, query string e headers (onde ficam os tokens) nunca entram no detalhe.
+// statusHTTP é 0 quando a plataforma não chegou a responder. Retorna nil se err for nil
+func wrapCall(etapa models.EtapaChamada, req *http.Request, statusHTTP int, err error) error {
+	if err == nil {
+		return nil
+	}
+	chamada := models.ChamadaPlataforma{Etapa: etapa, StatusHTTP: statusHTTP}
+	if req != nil {
+		chamada.Metodo = req.Method
+		chamada.Caminho = req.URL.Path
+	}
+	return &PlatformCallError{Chamada: chamada, Err: err}
+}
+
+// errTokenIndisponivel retorna ErrTokenIndisponivel anotado com a etapa do token, sem chamada
+// associada: a falha é do login, que roda em segundo plano
+func errTokenIndisponivel() error {
+	return wrapCall(models.EtapaToken, nil, 0, ErrTokenIndisponivel)
+}
+
+// PlatformCall retorna a chamada à plataforma que originou o erro, ou nil se o erro não veio de uma
+func PlatformCall(err error) *models.ChamadaPlataforma {
+	var callErr *PlatformCallError
+	if errors.As(err, &callErr) {
+		chamada := callErr.Chamada
+		return &chamada
+	}
+	return nil
+}
@@ -1,349 +1,931 @@
 package services
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
+	"net/http"
+	"runtime/debug"
+	"slices"
 	"strings"
+	"sync"
+	"time"
 
 	"delivery-control/internal/config"
+	"delivery-control/internal/metrics"
 	"delivery-control/internal/models"
+	"delivery-control/internal/repository"
+
+	"golang.org/x/sync/singleflight"
 )
 
+// ErrTokenIndisponivel indica que a plataforma ainda não possui token de acesso válido
+var ErrTokenIndisponivel = errors.New("token de acesso não disponível")
+
+// ErrPlataformaMalConfigurada indica que a URL base da plataforma não está configurada corretamente
+var ErrPlataformaMalConfigurada = errors.New("plataforma mal configurada")
+
+// ErrLojaNaoEncontrada indica que a loja não existe na listagem da plataforma
+var ErrLojaNaoEncontrada = errors.New("loja não encontrada na plataforma")
+
+// ErrLojaJaNoStatus indica que a plataforma recusou a operação porque a loja já estava no status
+// pretendido. O estado final desejado foi alcançado, então a operação é reportada como sucesso.
+// Apenas o DeliveryVip informa isso (códigos MERCHANT_ALREADY_*); o AnotaAI aplica o PUT de ativação
+// e bloqueio mesmo com a página já no status e responde sucesso normal. Para o AnotaAI, "já estava no
+// status" só é detectado pelas operações que consultam o catálogo antes (garantir-* e PUT .../status)
+var ErrLojaJaNoStatus = errors.New("loja já estava no status desejado")
+
+// mensagensJaNoStatus são as mensagens de sucesso das lojas que já estavam no status pretendido
+var mensagensJaNoStatus = map[models.Status]string{
+	models.StatusAtivo:     "Loja já estava ativa",
+	models.StatusBloqueado: "Loja já estava bloqueada",
+}
+
+// ErrPrazoEsgotado indica que o prazo da requisição (X-Request-Timeout) terminou antes de a
+// plataforma responder
+var ErrPrazoEsgotado = errors.New("prazo da requisição esgotado")
+
+// ErrOperacaoNaoSuportada indica que a plataforma não oferece a operação solicitada
+var ErrOperacaoNaoSuportada = errors.New("operação não suportada pela plataforma")
+
+// platformOperations declara as operações suportadas por cada plataforma. Plataformas com
+// capacidades limitadas (ex.: somente leitura) devem listar apenas o que oferecem
+var platformOperations = map[models.Plataforma][]models.Operacao{
+	models.PlataformaAnotaAi:     {models.OperacaoAtivar, models.OperacaoDesativar, models.OperacaoStatus, models.OperacaoPing},
+	models.PlataformaDeliveryVip: {models.OperacaoAtivar, models.OperacaoDesativar, models.OperacaoStatus, models.OperacaoPing},
+}
+
 // PlatformService gerencia a comunicação com plataformas externas
 type PlatformService struct {
-	anotaAiService     *AnotaAiService
+	config             *config.Config
+	anotaAiService     *AnotaAiAccounts
 	deliveryVipService *DeliveryVipService
+	statusCache        *StatusCache
+	repositories       *repository.Repositories
+	limiters           map[models.Plataforma]*concurrencyLimiter
+	recentOps          *recentOperations
+
+	// catalogFetches agrupa as buscas simultâneas do catálogo de uma mesma plataforma
+	catalogFetches singleflight.Group
+
+	// overrides guarda os serviços criados para URLs alternativas (X-Platform-Base-URL)
+	overridesMu sync.Mutex
+	overrides   map[overrideKey]*PlatformService
 }
 
 // NewPlatformService cria um novo serviço de plataforma
-func NewPlatformService(cfg *config.Config) *PlatformService {
-	return &PlatformService{
-		anotaAiService:     NewAnotaAiService(cfg),
-		deliveryVipService: NewDeliveryVipService(cfg),
+func NewPlatformService(cfg *config.Config, repositories *repository.Repositories) *PlatformService {
+	metrics.SetFanoutConcurrency(cfg.Server.FanoutConcurrency)
+	ps := &PlatformService{
+		config:       cfg,
+		repositories: repositories,
+		statusCache:  NewStatusCache(cfg.Cache.StatusTTL),
+		limiters:     newConcurrencyLimiters(cfg.Bulk, true),
+		recentOps:    newRecentOperations(cfg.Debug.RecentOperations),
+		overrides:    make(map[overrideKey]*PlatformService),
+	}
+
+	// Plataformas desabilitadas não fazem login nem renovam token; as chamadas a elas são
+	// recusadas por checkOperation antes de chegar aos serviços específicos
+	if ps.isValidPlatform(models.PlataformaAnotaAi) {
+		ps.anotaAiService = NewAnotaAiAccounts(cfg)
+	}
+	if ps.isValidPlatform(models.PlataformaDeliveryVip) {
+		ps.deliveryVipService = NewDeliveryVipService(cfg)
+	}
+	return ps
+}
+
+// loadCatalog retorna o catálogo completo de lojas da plataforma, usando o cache quando disponível
+func (ps *PlatformService) loadCatalog(ctx context.Context, plataforma models.Plataforma) (map[string]models.StoreInfo, error) {
+	entry, err := ps.loadCatalogEntry(ctx, plataforma)
+	return entry.lojas, err
+}
+
+// loadCatalogEntry é loadCatalog com o instante em que o catálogo retornado foi buscado, para que a
+// idade informada ao cliente seja a do mesmo catálogo usado na resposta
+func (ps *PlatformService) loadCatalogEntry(ctx context.Context, plataforma models.Plataforma) (catalogEntry, error) {
+	entry, ok := ps.statusCache.Get(plataforma)
+	metrics.RecordCacheLookup(string(plataforma), ok)
+	if ok {
+		return entry, nil
+	}
+	return ps.fetchCatalogEntry(ctx, plataforma)
+}
+
+// fetchCatalog busca o catálogo completo de lojas diretamente na plataforma e atualiza o cache.
+// Chamadas simultâneas para a mesma plataforma compartilham uma única busca e o mesmo resultado,
+// evitando que várias consultas com o cache expirado disparem a mesma listagem em paralelo.
+// Com o contexto encerrado, a chamada deixa de aguardar e retorna ErrPrazoEsgotado; a busca
+// compartilhada continua até o fim e atualiza o cache para as próximas consultas
+func (ps *PlatformService) fetchCatalog(ctx context.Context, plataforma models.Plataforma) (map[string]models.StoreInfo, error) {
+	entry, err := ps.fetchCatalogEntry(ctx, plataforma)
+	return entry.lojas, err
+}
+
+// fetchCatalogEntry é fetchCatalog com o instante em que o catálogo foi buscado
+func (ps *PlatformService) fetchCatalogEntry(ctx context.Context, plataforma models.Plataforma) (catalogEntry, error) {
+	ch := ps.catalogFetches.DoChan(string(plataforma), func() (_ any, err error) {
+		// Com DoChan a busca roda em outra goroutine, fora do alcance do middleware Recover
+		defer func() {
+			if r := recover(); r != nil {
+				log.Printf("[Catalog] PANIC ao buscar o catálogo de %s: %v\n%s", plataforma, r, debug.Stack())
+				err = fmt.Errorf("erro interno ao buscar o catálogo: %v", r)
+			}
+		}()
+
+		generation := ps.statusCache.Generation(plataforma)
+		var lojas map[string]models.StoreInfo
+		switch plataforma {
+		case models.PlataformaAnotaAi:
+			lojas, err = ps.anotaAiService.GetMultipleStoreStatus(nil)
+		case models.PlataformaDeliveryVip:
+			lojas, err = ps.deliveryVipService.GetMultipleStoreStatus(nil)
+		default:
+			return nil, fmt.Errorf("plataforma não implementada: %s", plataforma)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		entry := catalogEntry{lojas: lojas, fetchedAt: time.Now()}
+		ps.statusCache.Set(plataforma, entry, generation)
+		return entry, nil
+	})
+
+	select {
+	case <-ctx.Done():
+		return catalogEntry{}, fmt.Errorf("%w: %w", ErrPrazoEsgotado, ctx.Err())
+	case result := <-ch:
+		if result.Err != nil {
+			return catalogEntry{}, result.Err
+		}
+		return result.Val.(catalogEntry), nil
+	}
+}
+
+// ClearStatusCache descarta o catálogo em cache da plataforma informada ou, com plataforma
+// vazia, de todas as plataformas. Usado quando há alterações feitas fora desta API
+func (ps *PlatformService) ClearStatusCache(plataforma models.Plataforma) (*models.RespostaLimpezaCache, error) {
+	plataformas := ps.SupportedPlatforms()
+	if plataforma != "" {
+		if !ps.isValidPlatform(plataforma) {
+			return nil, fmt.Errorf("plataforma não suportada: %s", plataforma)
+		}
+		plataformas = []models.Plataforma{plataforma}
+	}
+
+	response := &models.RespostaLimpezaCache{Plataformas: plataformas}
+	for _, p := range plataformas {
+		removidas := ps.statusCache.Invalidate(p)
+		response.LojasRemovidas += removidas
+		log.Printf("[Cache] Limpeza manual plataforma=%s lojas_removidas=%d", p, removidas)
+	}
+
+	return response, nil
+}
+
+// GetStoreStatus consulta o status atual de uma única loja diretamente na plataforma, sem usar o cache
+func (ps *PlatformService) GetStoreStatus(ctx context.Context, plataforma models.Plataforma, idLoja string) (*models.StatusLojaDetalhes, error) {
+	return ps.PollStoreStatus(ctx, plataforma, idLoja, 0)
+}
+
+// PollStoreStatus consulta o status de uma única loja aceitando um catálogo buscado há no máximo
+// maxAge, mesmo com o cache de status desabilitado (maxAge zero sempre busca na plataforma). Usado
+// nas consultas repetidas de ?aguardar=, para que esperas simultâneas compartilhem uma única busca
+// do catálogo por intervalo em vez de listar o catálogo inteiro a cada consulta
+func (ps *PlatformService) PollStoreStatus(ctx context.Context, plataforma models.Plataforma, idLoja string, maxAge time.Duration) (*models.StatusLojaDetalhes, error) {
+	if err := ps.checkOperation(plataforma, models.OperacaoStatus); err != nil {
+		return nil, err
+	}
+	if err := ps.checkConfigured(plataforma); err != nil {
+		return nil, err
+	}
+
+	catalog, ok := ps.statusCache.GetWithin(plataforma, maxAge)
+	if !ok {
+		var err error
+		catalog, err = ps.fetchCatalog(ctx, plataforma)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao consultar status da loja: %w", err)
+		}
+	}
+
+	loja := statusLojaFromCatalog(catalog, idLoja)
+	return &loja, nil
+}
+
+// SyncStoreStatus reconcilia uma loja alterada fora desta API: consulta o status atual na plataforma,
+// atualizando o cache do catálogo, e registra o status no histórico. Retorna ErrLojaNaoEncontrada se a
+// loja não existir na plataforma
+func (ps *PlatformService) SyncStoreStatus(ctx context.Context, plataforma models.Plataforma, idLoja string) (*models.StatusLojaDetalhes, error) {
+	loja, err := ps.GetStoreStatus(ctx, plataforma, idLoja)
+	if err != nil {
+		return nil, err
+	}
+	if loja.Status == models.StatusNaoEncontrado {
+		return nil, fmt.Errorf("%w: %s", ErrLojaNaoEncontrada, idLoja)
+	}
+
+	registro := models.RegistroStatus{Timestamp: time.Now().UTC(), Status: loja.Status}
+	if err := ps.repositories.History.Append(plataforma, idLoja, registro); err != nil {
+		log.Printf("[Sync] Erro ao gravar o histórico da loja %s: %v", idLoja, err)
+	}
+
+	log.Printf("[Sync] Loja %s sincronizada na plataforma %s: %s", idLoja, plataforma, loja.Status)
+	return loja, nil
+}
+
+// StreamStoreStatus busca o catálogo da plataforma uma única vez e entrega o status de cada
+// loja solicitada para emit, na ordem recebida, com a idade do catálogo em cache usado (nil com o
+// cache desabilitado). Interrompe no primeiro erro retornado por emit
+func (ps *PlatformService) StreamStoreStatus(ctx context.Context, plataforma models.Plataforma, idsLojas []string, emit func(models.StatusLojaDetalhes, *models.IdadeCache) error) error {
+	if err := ps.checkOperation(plataforma, models.OperacaoStatus); err != nil {
+		return err
+	}
+	if err := ps.checkConfigured(plataforma); err != nil {
+		return err
+	}
+
+	entry, err := ps.loadCatalogEntry(ctx, plataforma)
+	if err != nil {
+		return fmt.Errorf("erro ao consultar status das lojas: %w", err)
+	}
+
+	cache := ps.statusCache.Age(entry)
+	for _, idLoja := range idsLojas {
+		if err := emit(statusLojaFromCatalog(entry.lojas, idLoja), cache); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetRawStore retorna o JSON da loja exatamente como a plataforma o lista (página do AnotaAI
+// ou merchant do DeliveryVip), sem cache, para diagnosticar divergências de mapeamento
+func (ps *PlatformService) GetRawStore(plataforma models.Plataforma, idLoja string) (json.RawMessage, error) {
+	if err := ps.checkOperation(plataforma, models.OperacaoStatus); err != nil {
+		return nil, err
+	}
+	if err := ps.checkConfigured(plataforma); err != nil {
+		return nil, err
+	}
+
+	switch plataforma {
+	case models.PlataformaAnotaAi:
+		return ps.anotaAiService.GetRawPage(idLoja)
+	case models.PlataformaDeliveryVip:
+		return ps.deliveryVipService.GetRawMerchant(idLoja)
+	default:
+		return nil, fmt.Errorf("plataforma não implementada: %s", plataforma)
+	}
+}
+
+// sortLojas ordena a listagem completa, que vem de um mapa, para que a resposta seja estável
+// entre chamadas. Na ordenação por nome, lojas com o mesmo nome são desempatadas pelo ID
+func sortLojas(lojas []models.StatusLojaDetalhes, ordenacao models.OrdenacaoLojas) {
+	slices.SortFunc(lojas, func(a, b models.StatusLojaDetalhes) int {
+		if ordenacao == models.OrdenarPorNome {
+			if c := strings.Compare(strings.ToLower(a.NomeFantasia), strings.ToLower(b.NomeFantasia)); c != 0 {
+				return c
+			}
+		}
+		return strings.Compare(a.IdLoja, b.IdLoja)
+	})
+}
+
+// statusLojaFromCatalog monta o status de uma loja a partir do catálogo da plataforma
+func statusLojaFromCatalog(catalog map[string]models.StoreInfo, idLoja string) models.StatusLojaDetalhes {
+	if !ValidStoreID(idLoja) {
+		return newStatusLojaInvalida(idLoja)
+	}
+	storeInfo, exists := catalog[idLoja]
+	if !exists || !storeInfo.Found {
+		return models.StatusLojaDetalhes{
+			IdLoja:   idLoja,
+			Status:   models.StatusNaoEncontrado,
+			Detalhes: newDetalhesStatusLoja(storeInfo),
+		}
+	}
+
+	return models.StatusLojaDetalhes{
+		IdLoja:       idLoja,
+		Status:       storeInfo.Status,
+		Documento:    storeInfo.Documento,
+		NomeFantasia: storeInfo.NomeFantasia,
+		Detalhes:     newDetalhesStatusLoja(storeInfo),
+		IsActive:     storeInfo.IsActive,
+	}
+}
+
+// WarmUp pré-carrega em segundo plano o catálogo de cada plataforma no cache de status,
+// tentando novamente com espera crescente enquanto o token inicial não estiver disponível
+func (ps *PlatformService) WarmUp() {
+	for _, plataforma := range ps.SupportedPlatforms() {
+		go func() {
+			const maxTentativas = 6
+			espera := 5 * time.Second
+
+			for tentativa := 1; tentativa <= maxTentativas; tentativa++ {
+				time.Sleep(espera)
+
+				inicio := time.Now()
+				lojas, err := ps.loadCatalog(context.Background(), plataforma)
+				if err == nil {
+					log.Printf("[WarmUp] Catálogo de %s pré-carregado: %d lojas em %dms", plataforma, len(lojas), time.Since(inicio).Milliseconds())
+					return
+				}
+
+				log.Printf("[WarmUp] Tentativa %d/%d de pré-carregar %s falhou: %v", tentativa, maxTentativas, plataforma, err)
+				espera *= 2
+			}
+		}()
+	}
+}
+
+// checkConfigured evita chamadas a URLs inválidas quando a plataforma está mal configurada
+func (ps *PlatformService) checkConfigured(plataforma models.Plataforma) error {
+	var baseURL string
+	switch plataforma {
+	case models.PlataformaAnotaAi:
+		baseURL = ps.config.Platforms.AnotaAiURL
+	case models.PlataformaDeliveryVip:
+		baseURL = ps.config.Platforms.DeliveryVipURL
+	default:
+		return nil
 	}
+
+	if !config.IsValidPlatformURL(baseURL) {
+		return fmt.Errorf("%w: URL base inválida para %s", ErrPlataformaMalConfigurada, plataforma)
+	}
+	return nil
 }
 
 // ActivateStore ativa uma loja na plataforma especificada
-func (ps *PlatformService) ActivateStore(plataforma models.Plataforma, idLoja string) (*models.RespostaOperacaoLoja, error) {
+func (ps *PlatformService) ActivateStore(ctx context.Context, plataforma models.Plataforma, idLoja string) (*models.RespostaOperacaoLoja, error) {
+	err := ps.activateStore(ctx, plataforma, idLoja)
+	if err != nil && !errors.Is(err, ErrLojaJaNoStatus) {
+		return nil, err
+	}
+	return newRespostaOperacaoLoja(plataforma, idLoja, err, models.StatusAtivo, "Loja ativada com sucesso"), nil
+}
+
+// activateStore executa a ativação de ActivateStore, retornando ErrLojaJaNoStatus quando a
+// plataforma informa que a loja já estava ativa. ctx limita a chamada à plataforma
+func (ps *PlatformService) activateStore(ctx context.Context, plataforma models.Plataforma, idLoja string) error {
 	// Valida a plataforma
-	if !ps.isValidPlatform(plataforma) {
-		return nil, fmt.Errorf("plataforma não suportada: %s", plataforma)
+	if err := ps.checkOperation(plataforma, models.OperacaoAtivar); err != nil {
+		return err
 	}
+	if err := ps.checkConfigured(plataforma); err != nil {
+		return err
+	}
+
+	// O status da loja muda, então o catálogo em cache deixa de ser confiável
+	defer ps.statusCache.Invalidate(plataforma)
 
 	// Chama o serviço específico baseado na plataforma
 	switch plataforma {
 	case models.PlataformaAnotaAi:
-		if err := ps.anotaAiService.ActivateStore(idLoja); err != nil {
-			return nil, fmt.Errorf("erro ao ativar loja no AnotaAI: %w", err)
+		err := ps.anotaAiService.ActivateStore(ctx, idLoja)
+		ps.logAudit(models.OperacaoAtivar, plataforma, idLoja, "", err)
+		if err != nil && !errors.Is(err, ErrLojaJaNoStatus) {
+			return fmt.Errorf("erro ao ativar loja no AnotaAI: %w", err)
 		}
-		return &models.RespostaOperacaoLoja{
-			Plataforma: plataforma,
-			IdLoja:     idLoja,
-			Status:     models.StatusAtivo,
-			Mensagem:   "Loja ativada com sucesso",
-		}, nil
+		return err
 	case models.PlataformaDeliveryVip:
-		if err := ps.deliveryVipService.ActivateStore(idLoja); err != nil {
-			return nil, fmt.Errorf("erro ao ativar loja no DeliveryVip: %w", err)
+		err := ps.deliveryVipService.ActivateStore(ctx, idLoja)
+		ps.logAudit(models.OperacaoAtivar, plataforma, idLoja, "", err)
+		if err != nil && !errors.Is(err, ErrLojaJaNoStatus) {
+			return fmt.Errorf("erro ao ativar loja no DeliveryVip: %w", err)
 		}
-		return &models.RespostaOperacaoLoja{
-			Plataforma: plataforma,
-			IdLoja:     idLoja,
-			Status:     models.StatusAtivo,
-			Mensagem:   "Loja ativada com sucesso",
-		}, nil
+		return err
 	default:
-		return nil, fmt.Errorf("plataforma não implementada: %s", plataforma)
+		return fmt.Errorf("plataforma não implementada: %s", plataforma)
 	}
 }
 
 // DeactivateStore desativa uma loja na plataforma especificada
-func (ps *PlatformService) DeactivateStore(plataforma models.Plataforma, idLoja string) (*models.RespostaOperacaoLoja, error) {
+// O motivo é repassado ao DeliveryVip e registrado no log de auditoria em todas as plataformas
+func (ps *PlatformService) DeactivateStore(ctx context.Context, plataforma models.Plataforma, idLoja, motivo string) (*models.RespostaOperacaoLoja, error) {
+	err := ps.deactivateStore(ctx, plataforma, idLoja, motivo)
+	if err != nil && !errors.Is(err, ErrLojaJaNoStatus) {
+		return nil, err
+	}
+	return newRespostaOperacaoLoja(plataforma, idLoja, err, models.StatusBloqueado, "Loja desativada com sucesso"), nil
+}
+
+// deactivateStore executa a desativação de DeactivateStore, retornando ErrLojaJaNoStatus quando a
+// plataforma informa que a loja já estava bloqueada. ctx limita a chamada à plataforma
+func (ps *PlatformService) deactivateStore(ctx context.Context, plataforma models.Plataforma, idLoja, motivo string) error {
 	// Valida a plataforma
-	if !ps.isValidPlatform(plataforma) {
-		return nil, fmt.Errorf("plataforma não suportada: %s", plataforma)
+	if err := ps.checkOperation(plataforma, models.OperacaoDesativar); err != nil {
+		return err
+	}
+	if err := ps.checkConfigured(plataforma); err != nil {
+		return err
+	}
+	if err := ps.checkProtected(plataforma, idLoja); err != nil {
+		ps.logAudit(models.OperacaoDesativar, plataforma, idLoja, motivo, err)
+		return err
 	}
 
+	// O status da loja muda, então o catálogo em cache deixa de ser confiável
+	defer ps.statusCache.Invalidate(plataforma)
+
 	// Chama o serviço específico baseado na plataforma
 	switch plataforma {
 	case models.PlataformaAnotaAi:
-		if err := ps.anotaAiService.DeactivateStore(idLoja); err != nil {
-			return nil, fmt.Errorf("erro ao desativar loja no AnotaAI: %w", err)
+		err := ps.anotaAiService.DeactivateStore(ctx, idLoja)
+		ps.logAudit(models.OperacaoDesativar, plataforma, idLoja, motivo, err)
+		if err != nil && !errors.Is(err, ErrLojaJaNoStatus) {
+			return fmt.Errorf("erro ao desativar loja no AnotaAI: %w", err)
 		}
-		return &models.RespostaOperacaoLoja{
-			Plataforma: plataforma,
-			IdLoja:     idLoja,
-			Status:     models.StatusBloqueado,
-			Mensagem:   "Loja desativada com sucesso",
-		}, nil
+		return err
 	case models.PlataformaDeliveryVip:
-		if err := ps.deliveryVipService.DeactivateStore(idLoja); err != nil {
-			return nil, fmt.Errorf("erro ao desativar loja no DeliveryVip: %w", err)
+		err := ps.deliveryVipService.DeactivateStore(ctx, idLoja, motivo)
+		ps.logAudit(models.OperacaoDesativar, plataforma, idLoja, motivo, err)
+		if err != nil && !errors.Is(err, ErrLojaJaNoStatus) {
+			return fmt.Errorf("erro ao desativar loja no DeliveryVip: %w", err)
 		}
-		return &models.RespostaOperacaoLoja{
-			Plataforma: plataforma,
-			IdLoja:     idLoja,
-			Status:     models.StatusBloqueado,
-			Mensagem:   "Loja desativada com sucesso",
-		}, nil
+		return err
 	default:
-		return nil, fmt.Errorf("plataforma não implementada: %s", plataforma)
+		return fmt.Errorf("plataforma não implementada: %s", plataforma)
+	}
+}
+
+// newRespostaOperacaoLoja monta a resposta de uma operação bem-sucedida em uma loja. err é nil ou
+// ErrLojaJaNoStatus, quando a loja já estava no status pretendido
+func newRespostaOperacaoLoja(plataforma models.Plataforma, idLoja string, err error, status models.Status, mensagemSucesso string) *models.RespostaOperacaoLoja {
+	mensagem := mensagemSucesso
+	if errors.Is(err, ErrLojaJaNoStatus) {
+		mensagem = mensagensJaNoStatus[status]
+	}
+	return &models.RespostaOperacaoLoja{
+		Plataforma: plataforma,
+		IdLoja:     idLoja,
+		Status:     status,
+		Mensagem:   mensagem,
 	}
 }
 
 // ActivateMultipleStores ativa múltiplas lojas em uma plataforma específica
-func (ps *PlatformService) ActivateMultipleStores(plataforma string, idsLojas []string) (*models.RespostaOperacaoMultiplasLojas, error) {
+// O contexto (normalmente o da requisição) interrompe o lote junto com o prazo de BULK_DEADLINE
+func (ps *PlatformService) ActivateMultipleStores(ctx context.Context, plataforma string, idsLojas []string) (*models.RespostaOperacaoMultiplasLojas, error) {
+	inicio := time.Now()
+	if err := ps.checkOperation(models.Plataforma(plataforma), models.OperacaoAtivar); err != nil {
+		return nil, err
+	}
+	if err := ps.checkConfigured(models.Plataforma(plataforma)); err != nil {
+		return nil, err
+	}
+
+	var activate func(ctx context.Context, idLoja string) error
+	switch plataforma {
+	case "anotaai":
+		activate = ps.anotaAiService.ActivateStore
+	case "deliveryvip":
+		activate = ps.deliveryVipService.ActivateStore
+	default:
+		return nil, fmt.Errorf("plataforma não suportada: %s", plataforma)
+	}
+
+	budget := newRetryBudget(ps.config.Bulk, len(idsLojas))
 	finalResponse := &models.RespostaOperacaoMultiplasLojas{
 		Plataforma: models.Plataforma(plataforma),
-		Resultados: make([]models.ResultadoOperacaoLoja, 0, len(idsLojas)),
+		Resultados: ps.runBulk(ctx, models.Plataforma(plataforma), idsLojas, func(ctx context.Context, idLoja string) models.ResultadoOperacaoLoja {
+			err := ps.withRetry(ctx, budget, idLoja, func() error { return activate(ctx, idLoja) })
+			// Invalida a cada loja para que consultas durante um lote longo não vejam o status anterior
+			ps.statusCache.Invalidate(models.Plataforma(plataforma))
+			ps.logAudit(models.OperacaoAtivar, models.Plataforma(plataforma), idLoja, "", err)
+			return newResultadoOperacao(idLoja, err, "ativar", models.StatusAtivo, "Loja ativada com sucesso")
+		}),
 	}
+	attachLojas(finalResponse)
 
-	// Processa cada loja individualmente
-	for _, idLoja := range idsLojas {
-		var err error
+	logBulkSummary(plataforma, "ativar", finalResponse.Resultados, time.Since(inicio))
+	return finalResponse, nil
+}
 
-		switch plataforma {
-		case "anotaai":
-			err = ps.anotaAiService.ActivateStore(idLoja)
-		case "deliveryvip":
-			err = ps.deliveryVipService.ActivateStore(idLoja)
-		default:
-			return nil, fmt.Errorf("plataforma não suportada: %s", plataforma)
-		}
+// DeactivateMultipleStores desativa múltiplas lojas em uma plataforma específica
+// O motivo é repassado ao DeliveryVip e registrado no log de auditoria em todas as plataformas
+func (ps *PlatformService) DeactivateMultipleStores(ctx context.Context, plataforma string, idsLojas []string, motivo string) (*models.RespostaOperacaoMultiplasLojas, error) {
+	inicio := time.Now()
+	if err := ps.checkOperation(models.Plataforma(plataforma), models.OperacaoDesativar); err != nil {
+		return nil, err
+	}
+	if err := ps.checkConfigured(models.Plataforma(plataforma)); err != nil {
+		return nil, err
+	}
 
-		resultado := models.ResultadoOperacaoLoja{
-			IdLoja: idLoja,
+	var deactivate func(ctx context.Context, idLoja string) error
+	switch plataforma {
+	case "anotaai":
+		deactivate = ps.anotaAiService.DeactivateStore
+	case "deliveryvip":
+		deactivate = func(ctx context.Context, idLoja string) error {
+			return ps.deliveryVipService.DeactivateStore(ctx, idLoja, motivo)
 		}
+	default:
+		return nil, fmt.Errorf("plataforma não suportada: %s", plataforma)
+	}
 
-		if err != nil {
-			// Verifica se é um erro específico do DeliveryVip
-			if deliveryVipErr, ok := err.(*DeliveryVipError); ok {
-				switch deliveryVipErr.TipoErro {
-				case models.ErroNaoEncontrado:
-					resultado.Status = models.StatusNaoEncontrado
-				default:
-					resultado.Status = models.StatusNaoEncontrado
-				}
-				resultado.Sucesso = false
-				resultado.Mensagem = fmt.Sprintf("Erro ao ativar loja: %s", deliveryVipErr.Mensagem)
-				resultado.Erro = &deliveryVipErr.TipoErro
-			} else if strings.Contains(err.Error(), "loja não encontrada") || strings.Contains(err.Error(), "store not found") {
-				resultado.Status = models.StatusNaoEncontrado
-				resultado.Sucesso = false
-				resultado.Mensagem = "Loja não encontrada na plataforma"
-				errType := models.ErroNaoEncontrado
-				resultado.Erro = &errType
-			} else {
-				resultado.Status = models.StatusNaoEncontrado
-				resultado.Sucesso = false
-				resultado.Mensagem = fmt.Sprintf("Erro ao ativar loja: %s", err.Error())
-				errType := models.ErroBadGateway
-				resultado.Erro = &errType
+	budget := newRetryBudget(ps.config.Bulk, len(idsLojas))
+	finalResponse := &models.RespostaOperacaoMultiplasLojas{
+		Plataforma: models.Plataforma(plataforma),
+		Resultados: ps.runBulk(ctx, models.Plataforma(plataforma), idsLojas, func(ctx context.Context, idLoja string) models.ResultadoOperacaoLoja {
+			// Lojas protegidas são recusadas sem chamar a plataforma e o lote segue com as demais
+			if err := ps.checkProtected(models.Plataforma(plataforma), idLoja); err != nil {
+				ps.logAudit(models.OperacaoDesativar, models.Plataforma(plataforma), idLoja, motivo, err)
+				return newResultadoOperacao(idLoja, err, "desativar", models.StatusBloqueado, "")
 			}
-		} else {
-			resultado.Status = models.StatusAtivo
-			resultado.Sucesso = true
-			resultado.Mensagem = "Loja ativada com sucesso"
+			err := ps.withRetry(ctx, budget, idLoja, func() error { return deactivate(ctx, idLoja) })
+			ps.statusCache.Invalidate(models.Plataforma(plataforma))
+			ps.logAudit(models.OperacaoDesativar, models.Plataforma(plataforma), idLoja, motivo, err)
+			return newResultadoOperacao(idLoja, err, "desativar", models.StatusBloqueado, "Loja desativada com sucesso")
+		}),
+	}
+	attachLojas(finalResponse)
+
+	logBulkSummary(plataforma, "desativar", finalResponse.Resultados, time.Since(inicio))
+	return finalResponse, nil
+}
+
+// newResultadoOperacao monta o resultado individual de uma operação em lote a partir do erro retornado pela plataforma
+func newResultadoOperacao(idLoja string, err error, verbo string, statusSucesso models.Status, mensagemSucesso string) models.ResultadoOperacaoLoja {
+	resultado := models.ResultadoOperacaoLoja{
+		IdLoja: idLoja,
+	}
+
+	if err == nil {
+		resultado.Status = statusSucesso
+		resultado.Sucesso = true
+		resultado.Mensagem = mensagemSucesso
+		return resultado
+	}
+
+	// A loja já estava no status pretendido: a operação é idempotente do ponto de vista do cliente
+	if errors.Is(err, ErrLojaJaNoStatus) {
+		resultado.Status = statusSucesso
+		resultado.Sucesso = true
+		resultado.Mensagem = mensagensJaNoStatus[statusSucesso]
+		return resultado
+	}
+
+	// O ID tem caracteres que não podem ir para a URL da plataforma
+	if errors.Is(err, ErrIDInvalido) {
+		resultado.Status = models.StatusInvalido
+		resultado.Sucesso = false
+		resultado.Mensagem = err.Error()
+		errType := models.ErroRequisicaoInvalida
+		resultado.Erro = &errType
+		return resultado
+	}
+
+	// A loja está na lista de lojas protegidas e a plataforma não foi chamada
+	if errors.Is(err, ErrLojaProtegida) {
+		resultado.Status = models.StatusProtegida
+		resultado.Sucesso = false
+		resultado.Mensagem = "Loja protegida: não pode ser desativada por esta API"
+		errType := models.ErroProibido
+		resultado.Erro = &errType
+		return resultado
+	}
+
+	// Identifica a chamada que falhou (token, consulta de status ou a operação na loja)
+	resultado.Chamada = PlatformCall(err)
+
+	// A loja excedeu o tempo limite por loja (PER_STORE_TIMEOUT), sem que se saiba se a plataforma
+	// chegou a aplicar a operação
+	if errors.Is(err, context.DeadlineExceeded) {
+		resultado.Status = models.StatusTempoEsgotado
+		resultado.Sucesso = false
+		resultado.Mensagem = fmt.Sprintf("Tempo limite excedido ao %s loja", verbo)
+		errType := models.ErroTempoEsgotado
+		resultado.Erro = &errType
+		return resultado
+	}
+
+	// Verifica se é um erro específico do DeliveryVip
+	var deliveryVipErr *DeliveryVipError
+	if errors.As(err, &deliveryVipErr) {
+		resultado.Status = statusResultadoErro(deliveryVipErr.TipoErro)
+		resultado.Sucesso = false
+		resultado.Mensagem = fmt.Sprintf("Erro ao %s loja: %s", verbo, deliveryVipErr.Mensagem)
+		resultado.Erro = &deliveryVipErr.TipoErro
+	} else if strings.Contains(err.Error(), "loja não encontrada") || strings.Contains(err.Error(), "store not found") {
+		resultado.Status = models.StatusNaoEncontrado
+		resultado.Sucesso = false
+		resultado.Mensagem = "Loja não encontrada na plataforma"
+		errType := models.ErroNaoEncontrado
+		resultado.Erro = &errType
+	} else {
+		resultado.Status = models.StatusErro
+		resultado.Sucesso = false
+		resultado.Mensagem = fmt.Sprintf("Erro ao %s loja: %s", verbo, err.Error())
+		errType := models.ErroBadGateway
+		if errors.Is(err, ErrTokenIndisponivel) {
+			errType = models.ErroServicoIndisponivel
 		}
+		resultado.Erro = &errType
+	}
+
+	return resultado
+}
 
-		finalResponse.Resultados = append(finalResponse.Resultados, resultado)
+// statusResultadoErro retorna o status de uma operação que falhou: nao_encontrado apenas quando a
+// plataforma não reconheceu a loja, e erro para as demais falhas (autenticação, gateway, etc.)
+func statusResultadoErro(tipoErro models.TipoErro) models.Status {
+	if tipoErro == models.ErroNaoEncontrado {
+		return models.StatusNaoEncontrado
 	}
+	return models.StatusErro
+}
 
-	return finalResponse, nil
-} // DeactivateMultipleStores desativa múltiplas lojas em uma plataforma específica
-func (ps *PlatformService) DeactivateMultipleStores(plataforma string, idsLojas []string) (*models.RespostaOperacaoMultiplasLojas, error) {
-	finalResponse := &models.RespostaOperacaoMultiplasLojas{
-		Plataforma: models.Plataforma(plataforma),
-		Resultados: make([]models.ResultadoOperacaoLoja, 0, len(idsLojas)),
+// logAudit registra no log de auditoria, nas métricas e nas operações recentes uma ativação ou
+// desativação executada em uma loja
+func (ps *PlatformService) logAudit(operacao models.Operacao, plataforma models.Plataforma, idLoja, motivo string, err error) {
+	// Loja que já estava no status pretendido conta como sucesso
+	if errors.Is(err, ErrLojaJaNoStatus) {
+		err = nil
 	}
 
-	// Processa cada loja individualmente
-	for _, idLoja := range idsLojas {
-		var err error
+	resultado := "sucesso"
+	if err != nil {
+		resultado = "falha"
+	}
 
-		switch plataforma {
-		case "anotaai":
-			err = ps.anotaAiService.DeactivateStore(idLoja)
-		case "deliveryvip":
-			err = ps.deliveryVipService.DeactivateStore(idLoja)
+	log.Printf("[Audit] operacao=%s plataforma=%s id_loja=%s motivo=%q resultado=%s", operacao, plataforma, idLoja, motivo, resultado)
+	metrics.RecordOperation(string(plataforma), string(operacao), err)
+
+	registro := models.RegistroAuditoria{
+		Timestamp:  time.Now().UTC(),
+		Operacao:   operacao,
+		Plataforma: plataforma,
+		IdLoja:     idLoja,
+		Motivo:     motivo,
+		Sucesso:    err == nil,
+	}
+	if err != nil {
+		registro.Erro = err.Error()
+	}
+	ps.recentOps.add(registro)
+	if err := ps.repositories.Audit.Record(registro); err != nil {
+		log.Printf("[Audit] Erro ao gravar registro de auditoria da loja %s: %v", idLoja, err)
+	}
+}
+
+// CountBulkResults conta os resultados de uma operação em lote por desfecho
+func CountBulkResults(resultados []models.ResultadoOperacaoLoja) (sucesso, naoEncontrado, falha int) {
+	for _, resultado := range resultados {
+		switch {
+		case resultado.Sucesso:
+			sucesso++
+		case resultado.Erro != nil && *resultado.Erro == models.ErroNaoEncontrado:
+			naoEncontrado++
 		default:
-			return nil, fmt.Errorf("plataforma não suportada: %s", plataforma)
+			falha++
 		}
+	}
+	return sucesso, naoEncontrado, falha
+}
 
-		resultado := models.ResultadoOperacaoLoja{
-			IdLoja: idLoja,
-		}
+// logBulkSummary registra uma linha de resumo por operação em lote, em formato chave=valor
+// para facilitar alertas baseados em log (ex.: "mais de X falhas em um lote")
+func logBulkSummary(plataforma, operacao string, resultados []models.ResultadoOperacaoLoja, duracao time.Duration) {
+	sucesso, naoEncontrado, falha := CountBulkResults(resultados)
+	log.Printf("[Bulk] operacao=%s plataforma=%s total=%d sucesso=%d nao_encontrado=%d falha=%d duracao_ms=%d",
+		operacao, plataforma, len(resultados), sucesso, naoEncontrado, falha, duracao.Milliseconds())
+}
 
-		if err != nil {
-			// Verifica se é um erro específico do DeliveryVip
-			if deliveryVipErr, ok := err.(*DeliveryVipError); ok {
-				switch deliveryVipErr.TipoErro {
-				case models.ErroNaoEncontrado:
-					resultado.Status = models.StatusNaoEncontrado
-				default:
-					resultado.Status = models.StatusNaoEncontrado
-				}
-				resultado.Sucesso = false
-				resultado.Mensagem = fmt.Sprintf("Erro ao desativar loja: %s", deliveryVipErr.Mensagem)
-				resultado.Erro = &deliveryVipErr.TipoErro
-			} else if strings.Contains(err.Error(), "loja não encontrada") || strings.Contains(err.Error(), "store not found") {
-				resultado.Status = models.StatusNaoEncontrado
-				resultado.Sucesso = false
-				resultado.Mensagem = "Loja não encontrada na plataforma"
-				errType := models.ErroNaoEncontrado
-				resultado.Erro = &errType
-			} else {
-				resultado.Status = models.StatusNaoEncontrado
-				resultado.Sucesso = false
-				resultado.Mensagem = fmt.Sprintf("Erro ao desativar loja: %s", err.Error())
-				errType := models.ErroBadGateway
-				resultado.Erro = &errType
+// GetMultipleStoreStatus obtém o status de múltiplas lojas na plataforma especificada
+// Se idsLojas for nil ou vazio, retorna o status de todas as lojas da plataforma, ordenadas
+// conforme ordenacao. Nesse caso, com incluirInativas falso, as páginas arquivadas do AnotaAI
+// (active=false) são omitidas. Com IDs informados, a resposta mantém a ordem recebida
+func (ps *PlatformService) GetMultipleStoreStatus(ctx context.Context, plataforma models.Plataforma, idsLojas []string, incluirInativas bool, ordenacao models.OrdenacaoLojas) (*models.RespostaStatusMultiplasLojas, error) {
+	// Valida a plataforma
+	if err := ps.checkOperation(plataforma, models.OperacaoStatus); err != nil {
+		return nil, err
+	}
+	if err := ps.checkConfigured(plataforma); err != nil {
+		return nil, err
+	}
+
+	entry, err := ps.loadCatalogEntry(ctx, plataforma)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao consultar status das lojas em %s: %w", plataforma, err)
+	}
+
+	return &models.RespostaStatusMultiplasLojas{
+		Plataforma:    plataforma,
+		Lojas:         statusList(plataforma, entry.lojas, idsLojas, incluirInativas, ordenacao),
+		CatalogoVazio: len(entry.lojas) == 0,
+		Cache:         ps.statusCache.Age(entry),
+	}, nil
+}
+
+// statusList monta o status das lojas solicitadas a partir do catálogo da plataforma, ou de todas
+// as lojas do catálogo quando nenhum ID é informado
+func statusList(plataforma models.Plataforma, statusMap map[string]models.StoreInfo, idsLojas []string, incluirInativas bool, ordenacao models.OrdenacaoLojas) []models.StatusLojaDetalhes {
+	// Se IDs específicos foram solicitados, itera sobre eles
+	// Caso contrário, itera sobre todas as chaves do mapa
+	var lojas []models.StatusLojaDetalhes
+	if len(idsLojas) > 0 {
+		lojas = make([]models.StatusLojaDetalhes, 0, len(idsLojas))
+		for _, idLoja := range idsLojas {
+			// Um ID malformado é devolvido como invalido, sem impedir a consulta dos demais
+			if !ValidStoreID(idLoja) {
+				lojas = append(lojas, newStatusLojaInvalida(idLoja))
+				continue
 			}
-		} else {
-			resultado.Status = models.StatusBloqueado
-			resultado.Sucesso = true
-			resultado.Mensagem = "Loja desativada com sucesso"
+			lojas = append(lojas, newStatusLojaDetalhes(idLoja, statusMap[idLoja]))
 		}
+	} else {
+		lojas = make([]models.StatusLojaDetalhes, 0, len(statusMap))
+		for idLoja, storeInfo := range statusMap {
+			// Páginas arquivadas continuam na listagem do AnotaAI
+			if plataforma == models.PlataformaAnotaAi && !incluirInativas && !storeInfo.PageActive {
+				continue
+			}
+			lojas = append(lojas, newStatusLojaDetalhes(idLoja, storeInfo))
+		}
+		sortLojas(lojas, ordenacao)
+	}
+	return lojas
+}
 
-		finalResponse.Resultados = append(finalResponse.Resultados, resultado)
+// newStatusLojaDetalhes monta o status de uma loja a partir das informações normalizadas da
+// plataforma. Lojas ausentes do catálogo (StoreInfo zero) são reportadas como não encontradas
+func newStatusLojaDetalhes(idLoja string, storeInfo models.StoreInfo) models.StatusLojaDetalhes {
+	status := storeInfo.Status
+	if !storeInfo.Found {
+		status = models.StatusNaoEncontrado
+	}
+	return models.StatusLojaDetalhes{
+		IdLoja:       idLoja,
+		Status:       status,
+		Documento:    storeInfo.Documento,
+		NomeFantasia: storeInfo.NomeFantasia,
+		Detalhes:     newDetalhesStatusLoja(storeInfo),
+		IsActive:     storeInfo.Found && storeInfo.IsActive,
 	}
+}
 
-	return finalResponse, nil
+// newDetalhesStatusLoja monta os detalhes verbosos de uma loja a partir das informações da plataforma
+func newDetalhesStatusLoja(storeInfo models.StoreInfo) *models.DetalhesStatusLoja {
+	detalhes := &models.DetalhesStatusLoja{
+		IsActive:         storeInfo.IsActive,
+		StatusAssinatura: storeInfo.SubscriptionStatus,
+		DadosIncompletos: storeInfo.DadosIncompletos,
+	}
+
+	// O flag de bloqueio só existe no modelo do DeliveryVip
+	if storeInfo.SubscriptionStatus != "" {
+		bloqueado := storeInfo.Blocked
+		detalhes.Bloqueado = &bloqueado
+	}
+
+	// Lojas não encontradas não têm documento para validar
+	if storeInfo.Found {
+		documentoValido := storeInfo.DocumentoValido
+		detalhes.DocumentoValido = &documentoValido
+	}
+
+	return detalhes
 }
 
-// GetMultipleStoreStatus obtém o status de múltiplas lojas na plataforma especificada
-// Se idsLojas for nil ou vazio, retorna o status de todas as lojas da plataforma
-func (ps *PlatformService) GetMultipleStoreStatus(plataforma models.Plataforma, idsLojas []string) (*models.RespostaStatusMultiplasLojas, error) {
-	// Valida a plataforma
-	if !ps.isValidPlatform(plataforma) {
-		return nil, fmt.Errorf("plataforma não suportada: %s", plataforma)
+// Ping executa uma chamada autenticada mínima na plataforma para validar credenciais e conectividade
+func (ps *PlatformService) Ping(plataforma models.Plataforma) (*models.RespostaPing, error) {
+	if err := ps.checkOperation(plataforma, models.OperacaoPing); err != nil {
+		return nil, err
+	}
+	if err := ps.checkConfigured(plataforma); err != nil {
+		return nil, err
 	}
 
-	// Chama o serviço específico baseado na plataforma
+	var ping func() (int, error)
 	switch plataforma {
 	case models.PlataformaAnotaAi:
-		statusMap, err := ps.anotaAiService.GetMultipleStoreStatus(idsLojas)
-		if err != nil {
-			return nil, fmt.Errorf("erro ao consultar status no AnotaAI: %w", err)
-		}
+		ping = ps.anotaAiService.Ping
+	case models.PlataformaDeliveryVip:
+		ping = ps.deliveryVipService.Ping
+	default:
+		return nil, fmt.Errorf("plataforma não suportada: %s", plataforma)
+	}
 
-		// Se IDs específicos foram solicitados, itera sobre eles
-		// Caso contrário, itera sobre todas as chaves do mapa
-		var lojas []models.StatusLojaDetalhes
-		if len(idsLojas) > 0 {
-			lojas = make([]models.StatusLojaDetalhes, 0, len(idsLojas))
-			for _, idLoja := range idsLojas {
-				var status models.Status
-				var documento, nomeFantasia string
-
-				if storeInfo, exists := statusMap[idLoja]; exists {
-					if !storeInfo.Found {
-						status = models.StatusNaoEncontrado
-					} else {
-						// Usa o status específico retornado pelo serviço
-						status = storeInfo.Status
-					}
-					documento = storeInfo.Documento
-					nomeFantasia = storeInfo.NomeFantasia
-				} else {
-					status = models.StatusNaoEncontrado
-				}
+	inicio := time.Now()
+	statusCode, err := ping()
+	response := &models.RespostaPing{
+		Plataforma: plataforma,
+		LatenciaMs: time.Since(inicio).Milliseconds(),
+	}
 
-				lojas = append(lojas, models.StatusLojaDetalhes{
-					IdLoja:       idLoja,
-					Status:       status,
-					Documento:    documento,
-					NomeFantasia: nomeFantasia,
-				})
-			}
-		} else {
-			lojas = make([]models.StatusLojaDetalhes, 0, len(statusMap))
-			for idLoja, storeInfo := range statusMap {
-				var status models.Status
-				if !storeInfo.Found {
-					status = models.StatusNaoEncontrado
-				} else {
-					// Usa o status específico retornado pelo serviço
-					status = storeInfo.Status
-				}
+	switch {
+	case errors.Is(err, ErrTokenIndisponivel):
+		response.Mensagem = err.Error()
+	case err != nil:
+		response.Mensagem = "Plataforma inacessível: " + err.Error()
+	case statusCode == http.StatusUnauthorized || statusCode == http.StatusForbidden:
+		response.Alcancavel = true
+		response.Mensagem = fmt.Sprintf("Credenciais recusadas pela plataforma (status %d)", statusCode)
+	case statusCode != http.StatusOK:
+		response.Alcancavel = true
+		response.Mensagem = fmt.Sprintf("Resposta inesperada da plataforma (status %d)", statusCode)
+	default:
+		response.Alcancavel = true
+		response.Autenticado = true
+	}
 
-				lojas = append(lojas, models.StatusLojaDetalhes{
-					IdLoja:       idLoja,
-					Status:       status,
-					Documento:    storeInfo.Documento,
-					NomeFantasia: storeInfo.NomeFantasia,
-				})
-			}
-		}
+	return response, nil
+}
 
-		return &models.RespostaStatusMultiplasLojas{
-			Plataforma: plataforma,
-			Lojas:      lojas,
-		}, nil
-	case models.PlataformaDeliveryVip:
-		statusMap, err := ps.deliveryVipService.GetMultipleStoreStatus(idsLojas)
-		if err != nil {
-			return nil, fmt.Errorf("erro ao consultar status das lojas no DeliveryVip: %w", err)
-		}
+// MaxBulkIDs retorna a quantidade máxima de lojas por requisição em lote (0 sem limite)
+func (ps *PlatformService) MaxBulkIDs() int {
+	return ps.config.Bulk.MaxIDs
+}
 
-		// Se IDs específicos foram solicitados, itera sobre eles
-		// Caso contrário, itera sobre todas as chaves do mapa
-		var lojas []models.StatusLojaDetalhes
-		if len(idsLojas) > 0 {
-			lojas = make([]models.StatusLojaDetalhes, 0, len(idsLojas))
-			for _, idLoja := range idsLojas {
-				var status models.Status
-				var documento, nomeFantasia string
-
-				if storeInfo, exists := statusMap[idLoja]; exists {
-					if !storeInfo.Found {
-						status = models.StatusNaoEncontrado
-					} else {
-						// Usa o status específico retornado pelo serviço
-						status = storeInfo.Status
-					}
-					documento = storeInfo.Documento
-					nomeFantasia = storeInfo.NomeFantasia
-				} else {
-					// Fallback case (não deveria acontecer)
-					status = models.StatusNaoEncontrado
-				}
+// FanoutConcurrency retorna quantas plataformas podem ser consultadas em paralelo (0 sem limite)
+func (ps *PlatformService) FanoutConcurrency() int {
+	return ps.config.Server.FanoutConcurrency
+}
 
-				lojas = append(lojas, models.StatusLojaDetalhes{
-					IdLoja:       idLoja,
-					Status:       status,
-					Documento:    documento,
-					NomeFantasia: nomeFantasia,
-				})
-			}
-		} else {
-			lojas = make([]models.StatusLojaDetalhes, 0, len(statusMap))
-			for idLoja, storeInfo := range statusMap {
-				var status models.Status
-				if !storeInfo.Found {
-					status = models.StatusNaoEncontrado
-				} else {
-					status = storeInfo.Status
-				}
+// StrictInput indica se IDs informados no header e no body ao mesmo tempo devem ser rejeitados
+func (ps *PlatformService) StrictInput() bool {
+	return ps.config.Server.StrictInput
+}
 
-				lojas = append(lojas, models.StatusLojaDetalhes{
-					IdLoja:       idLoja,
-					Status:       status,
-					Documento:    storeInfo.Documento,
-					NomeFantasia: storeInfo.NomeFantasia,
-				})
-			}
+// BulkConfirmThreshold retorna a quantidade de lojas acima da qual o lote exige confirmação (0 desabilita)
+func (ps *PlatformService) BulkConfirmThreshold() int {
+	return ps.config.Bulk.ConfirmThreshold
+}
+
+// StatusStreamInterval retorna o intervalo entre as consultas do stream de status
+func (ps *PlatformService) StatusStreamInterval() time.Duration {
+	return ps.config.Cache.StreamInterval
+}
+
+// StatusStreamBounds retorna os limites do intervalo do stream de status escolhido pelo cliente
+func (ps *PlatformService) StatusStreamBounds() (time.Duration, time.Duration) {
+	return ps.config.Cache.StreamMinInterval, ps.config.Cache.StreamMaxInterval
+}
+
+// SupportedPlatforms retorna as plataformas suportadas e habilitadas em PLATFORMS_ENABLED
+func (ps *PlatformService) SupportedPlatforms() []models.Plataforma {
+	var plataformas []models.Plataforma
+	for _, plataforma := range []models.Plataforma{models.PlataformaAnotaAi, models.PlataformaDeliveryVip} {
+		if ps.isValidPlatform(plataforma) {
+			plataformas = append(plataformas, plataforma)
 		}
+	}
+	return plataformas
+}
 
-		return &models.RespostaStatusMultiplasLojas{
+// Platforms retorna as plataformas suportadas e as operações oferecidas por cada uma
+func (ps *PlatformService) Platforms() []models.RespostaPlataforma {
+	plataformas := ps.SupportedPlatforms()
+	response := make([]models.RespostaPlataforma, 0, len(plataformas))
+	for _, plataforma := range plataformas {
+		response = append(response, models.RespostaPlataforma{
 			Plataforma: plataforma,
-			Lojas:      lojas,
-		}, nil
+			Operacoes:  platformOperations[plataforma],
+		})
+	}
+	return response
+}
+
+// StatusMapping retorna a tabela que classifica o status bruto da plataforma nos status da API.
+// Apenas o DeliveryVip possui um status de assinatura; o AnotaAI é classificado pelos flags da página
+func (ps *PlatformService) StatusMapping(plataforma models.Plataforma) (*models.RespostaMapeamentoStatus, error) {
+	if !ps.isValidPlatform(plataforma) {
+		return nil, fmt.Errorf("plataforma não suportada: %s", plataforma)
+	}
+	switch plataforma {
+	case models.PlataformaDeliveryVip:
+		return ps.deliveryVipService.StatusMapping(), nil
+	case models.PlataformaAnotaAi:
+		return nil, fmt.Errorf("%w: %s não possui status de assinatura para mapear", ErrOperacaoNaoSuportada, plataforma)
 	default:
-		return nil, fmt.Errorf("plataforma não implementada: %s", plataforma)
+		return nil, fmt.Errorf("plataforma não suportada: %s", plataforma)
+	}
+}
+
+// checkOperation valida a plataforma e se ela suporta a operação, antes de qualquer chamada externa
+func (ps *PlatformService) checkOperation(plataforma models.Plataforma, operacao models.Operacao) error {
+	if !ps.isValidPlatform(plataforma) {
+		return fmt.Errorf("plataforma não suportada: %s", plataforma)
 	}
+	if !slices.Contains(platformOperations[plataforma], operacao) {
+		return fmt.Errorf("%w: %s não oferece a operação %s", ErrOperacaoNaoSuportada, plataforma, operacao)
+	}
+	return nil
 }
 
-// isValidPlatform verifica se a plataforma é suportada
+// isValidPlatform verifica se a plataforma é suportada e está habilitada
 func (ps *PlatformService) isValidPlatform(plataforma models.Plataforma) bool {
-	return plataforma == models.PlataformaAnotaAi || plataforma == models.PlataformaDeliveryVip
+	if plataforma != models.PlataformaAnotaAi && plataforma != models.PlataformaDeliveryVip {
+		return false
+	}
+	return ps.config.Platforms.IsEnabled(string(plataforma))
 }
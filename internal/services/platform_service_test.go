@@ -0,0 +1,153 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"delivery-control/internal/config"
+	"delivery-control/internal/models"
+	"delivery-control/internal/repository"
+	"delivery-control/internal/testutil/fakeplatform"
+)
+
+// newTestService cria um PlatformService apontando para servidores falsos novos do AnotaAI e do
+// DeliveryVip. As chamadas aguardam o primeiro login em vez de falhar com token indisponível
+func newTestService(t *testing.T, configure ...func(*config.Config)) (*PlatformService, *fakeplatform.Server, *fakeplatform.Server) {
+	t.Helper()
+
+	anotaAi := fakeplatform.NewAnotaAi()
+	deliveryVip := fakeplatform.NewDeliveryVip()
+	t.Cleanup(anotaAi.Close)
+	t.Cleanup(deliveryVip.Close)
+
+	cfg := fakeplatform.Config(anotaAi, deliveryVip)
+	cfg.Platforms.TokenWaitTimeout = 5 * time.Second
+	for _, fn := range configure {
+		fn(cfg)
+	}
+
+	return NewPlatformService(cfg, repository.NewMemory()), anotaAi, deliveryVip
+}
+
+// statusByID indexa o status das lojas da resposta pelo ID
+func statusByID(lojas []models.StatusLojaDetalhes) map[string]models.Status {
+	status := make(map[string]models.Status, len(lojas))
+	for _, loja := range lojas {
+		status[loja.IdLoja] = loja.Status
+	}
+	return status
+}
+
+func TestGetMultipleStoreStatusAgainstFakePlatforms(t *testing.T) {
+	ps, anotaAi, deliveryVip := newTestService(t)
+	anotaAi.SetResponse(fakeplatform.RouteAnotaAiListPages, fakeplatform.JSON(http.StatusOK, fakeplatform.AnotaAiListPages(
+		fakeplatform.AnotaAiPage("page-ativa", "Loja Ativa", "12345678901", true),
+		fakeplatform.AnotaAiPage("page-bloqueada", "Loja Bloqueada", "12345678901", false),
+	)))
+	deliveryVip.SetResponse(fakeplatform.RouteDeliveryVipMerchants, fakeplatform.JSON(http.StatusOK, []any{
+		fakeplatform.DeliveryVipMerchant("merchant-ativo", "Loja Ativa", "12345678000190", "ACTIVATED", false),
+		fakeplatform.DeliveryVipMerchant("merchant-bloqueado", "Loja Bloqueada", "12345678000190", "ACTIVATED", true),
+	}))
+
+	tests := []struct {
+		plataforma models.Plataforma
+		ids        []string
+		want       map[string]models.Status
+	}{
+		{
+			plataforma: models.PlataformaAnotaAi,
+			ids:        []string{"page-ativa", "page-bloqueada", "page-inexistente"},
+			want: map[string]models.Status{
+				"page-ativa":       models.StatusAtivo,
+				"page-bloqueada":   models.StatusBloqueado,
+				"page-inexistente": models.StatusNaoEncontrado,
+			},
+		},
+		{
+			plataforma: models.PlataformaDeliveryVip,
+			ids:        []string{"merchant-ativo", "merchant-bloqueado", "merchant-inexistente"},
+			want: map[string]models.Status{
+				"merchant-ativo":       models.StatusAtivo,
+				"merchant-bloqueado":   models.StatusBloqueado,
+				"merchant-inexistente": models.StatusNaoEncontrado,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.plataforma), func(t *testing.T) {
+			resposta, err := ps.GetMultipleStoreStatus(context.Background(), tt.plataforma, tt.ids, false, models.OrdenarPorID)
+			if err != nil {
+				t.Fatalf("GetMultipleStoreStatus() erro: %v", err)
+			}
+			got := statusByID(resposta.Lojas)
+			for id, want := range tt.want {
+				if got[id] != want {
+					t.Errorf("status da loja %s = %q, esperado %q", id, got[id], want)
+				}
+			}
+		})
+	}
+}
+
+func TestStoreOperationsSendPlatformToken(t *testing.T) {
+	ps, anotaAi, deliveryVip := newTestService(t)
+
+	if _, err := ps.ActivateStore(context.Background(), models.PlataformaAnotaAi, "page-1"); err != nil {
+		t.Fatalf("ActivateStore() erro: %v", err)
+	}
+	requests := anotaAi.Requests(fakeplatform.RouteAnotaAiActivate)
+	if len(requests) != 1 || requests[0].ID != "page-1" {
+		t.Fatalf("requisições de ativação no AnotaAI = %+v, esperada uma para page-1", requests)
+	}
+	if got := requests[0].Header.Get("authorization"); got != fakeplatform.AnotaAiToken {
+		t.Errorf("header authorization = %q, esperado o token do login", got)
+	}
+
+	if _, err := ps.DeactivateStore(context.Background(), models.PlataformaDeliveryVip, "merchant-1", "inadimplência"); err != nil {
+		t.Fatalf("DeactivateStore() erro: %v", err)
+	}
+	requests = deliveryVip.Requests(fakeplatform.RouteDeliveryVipBlock)
+	if len(requests) != 1 || requests[0].ID != "merchant-1" {
+		t.Fatalf("requisições de bloqueio no DeliveryVip = %+v, esperada uma para merchant-1", requests)
+	}
+	if got := requests[0].Header.Get("Authorization"); got != "Bearer "+fakeplatform.DeliveryVipToken {
+		t.Errorf("header Authorization = %q, esperado o token OAuth com prefixo Bearer", got)
+	}
+	if got := requests[0].Body; got != `{"reason":"inadimplência"}` {
+		t.Errorf("corpo do bloqueio = %s, esperado o motivo informado", got)
+	}
+}
+
+func TestPollStoreStatusSharesRecentCatalog(t *testing.T) {
+	ps, anotaAi, _ := newTestService(t)
+	anotaAi.SetResponse(fakeplatform.RouteAnotaAiListPages, fakeplatform.JSON(http.StatusOK, fakeplatform.AnotaAiListPages(
+		fakeplatform.AnotaAiPage("page-1", "Loja", "12345678901", true),
+	)))
+
+	// O cache de status está desabilitado (TTL zero) e a primeira consulta sempre vai à plataforma
+	if _, err := ps.GetStoreStatus(context.Background(), models.PlataformaAnotaAi, "page-1"); err != nil {
+		t.Fatalf("GetStoreStatus() erro: %v", err)
+	}
+	for range 3 {
+		loja, err := ps.PollStoreStatus(context.Background(), models.PlataformaAnotaAi, "page-1", time.Minute)
+		if err != nil {
+			t.Fatalf("PollStoreStatus() erro: %v", err)
+		}
+		if loja.Status != models.StatusAtivo {
+			t.Fatalf("status = %q, esperado %q", loja.Status, models.StatusAtivo)
+		}
+	}
+	if calls := anotaAi.Calls(fakeplatform.RouteAnotaAiListPages); calls != 1 {
+		t.Errorf("listagens do catálogo = %d, esperada 1 compartilhada pelas consultas seguintes", calls)
+	}
+
+	if _, err := ps.PollStoreStatus(context.Background(), models.PlataformaAnotaAi, "page-1", 0); err != nil {
+		t.Fatalf("PollStoreStatus() erro: %v", err)
+	}
+	if calls := anotaAi.Calls(fakeplatform.RouteAnotaAiListPages); calls != 2 {
+		t.Errorf("listagens do catálogo = %d, esperadas 2 com maxAge zero", calls)
+	}
+}
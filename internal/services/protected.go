@@ -0,0 +1,33 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"delivery-control/internal/models"
+)
+
+// ErrLojaProtegida indica que a loja está em ANOTAAI_PROTECTED_STORE_IDS ou
+// DELIVERYVIP_PROTECTED_STORE_IDS e não pode ser desativada por esta API
+var ErrLojaProtegida = errors.New("loja protegida")
+
+// protectedStoreIDs retorna os IDs (da plataforma) das lojas que nunca são desativadas
+func (ps *PlatformService) protectedStoreIDs(plataforma models.Plataforma) []string {
+	switch plataforma {
+	case models.PlataformaAnotaAi:
+		return ps.config.Platforms.AnotaAi.ProtectedStoreIDs
+	case models.PlataformaDeliveryVip:
+		return ps.config.Platforms.DeliveryVip.ProtectedStoreIDs
+	default:
+		return nil
+	}
+}
+
+// checkProtected recusa a desativação de uma loja protegida antes de qualquer chamada à plataforma
+func (ps *PlatformService) checkProtected(plataforma models.Plataforma, idLoja string) error {
+	if slices.Contains(ps.protectedStoreIDs(plataforma), idLoja) {
+		return fmt.Errorf("%w: a loja %s não pode ser desativada por esta API", ErrLojaProtegida, idLoja)
+	}
+	return nil
+}
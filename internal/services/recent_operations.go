@@ -0,0 +1,67 @@
+package services
+
+import (
+	"sync"
+
+	"delivery-control/internal/models"
+)
+
+// recentOperations guarda as últimas operações executadas em um buffer circular de tamanho fixo
+// (RECENT_OPERATIONS_SIZE), para depuração rápida sem consultar o repositório de auditoria
+type recentOperations struct {
+	mu        sync.Mutex
+	registros []models.RegistroAuditoria
+	// proximo é a posição que recebe o próximo registro, sobrescrevendo o mais antigo quando cheio
+	proximo int
+	cheio   bool
+}
+
+// newRecentOperations cria o buffer com capacidade para tamanho operações (0 não guarda nenhuma)
+func newRecentOperations(tamanho int) *recentOperations {
+	return &recentOperations{registros: make([]models.RegistroAuditoria, max(tamanho, 0))}
+}
+
+// add guarda a operação, descartando a mais antiga quando o buffer está cheio
+func (r *recentOperations) add(registro models.RegistroAuditoria) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.registros) == 0 {
+		return
+	}
+	r.registros[r.proximo] = registro
+	r.proximo = (r.proximo + 1) % len(r.registros)
+	if r.proximo == 0 {
+		r.cheio = true
+	}
+}
+
+// list retorna as operações da mais recente para a mais antiga, até limite (0 retorna todas)
+func (r *recentOperations) list(limite int) []models.RegistroAuditoria {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	total := r.proximo
+	if r.cheio {
+		total = len(r.registros)
+	}
+	if limite > 0 && limite < total {
+		total = limite
+	}
+
+	resultado := make([]models.RegistroAuditoria, 0, total)
+	for i := 1; i <= total; i++ {
+		resultado = append(resultado, r.registros[(r.proximo-i+len(r.registros))%len(r.registros)])
+	}
+	return resultado
+}
+
+// RecentOperations retorna as últimas operações executadas nas lojas, da mais recente para a
+// mais antiga, até limite (0 retorna todas as guardadas)
+func (ps *PlatformService) RecentOperations(limite int) *models.RespostaOperacoesRecentes {
+	operacoes := ps.recentOps.list(limite)
+	return &models.RespostaOperacoesRecentes{
+		Total:      len(operacoes),
+		Capacidade: ps.config.Debug.RecentOperations,
+		Operacoes:  operacoes,
+	}
+}
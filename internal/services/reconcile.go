@@ -0,0 +1,61 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"delivery-control/internal/models"
+)
+
+// ReconcileDiff compara as listas de lojas que deveriam estar ativas e bloqueadas com o status atual
+// na plataforma (consultado sem cache) e retorna o plano de alterações, sem executar nenhuma delas
+func (ps *PlatformService) ReconcileDiff(ctx context.Context, plataforma models.Plataforma, ativas, bloqueadas []string) (*models.RespostaReconciliacaoDiff, error) {
+	if err := ps.checkOperation(plataforma, models.OperacaoStatus); err != nil {
+		return nil, err
+	}
+	if err := ps.checkConfigured(plataforma); err != nil {
+		return nil, err
+	}
+
+	catalog, err := ps.fetchCatalog(ctx, plataforma)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao consultar status das lojas em %s: %w", plataforma, err)
+	}
+
+	diff := reconcileDiff(catalog, ativas, bloqueadas)
+	diff.Plataforma = plataforma
+	return diff, nil
+}
+
+// reconcileDiff classifica cada loja pelo que precisa ser feito para levá-la ao status desejado:
+// lojas que deveriam estar ativas e não estão precisam ser desbloqueadas, e lojas que deveriam
+// estar bloqueadas e não estão precisam ser bloqueadas
+func reconcileDiff(catalog map[string]models.StoreInfo, ativas, bloqueadas []string) *models.RespostaReconciliacaoDiff {
+	diff := &models.RespostaReconciliacaoDiff{
+		Bloquear:       []models.ItemReconciliacao{},
+		Desbloquear:    []models.ItemReconciliacao{},
+		Corretas:       []models.ItemReconciliacao{},
+		NaoEncontradas: []models.ItemReconciliacao{},
+	}
+
+	classify := func(idLoja string, alvo models.Status, pendentes *[]models.ItemReconciliacao) {
+		loja := statusLojaFromCatalog(catalog, idLoja)
+		item := models.ItemReconciliacao{IdLoja: idLoja, StatusAtual: loja.Status}
+		switch loja.Status {
+		case models.StatusNaoEncontrado, models.StatusInvalido:
+			diff.NaoEncontradas = append(diff.NaoEncontradas, item)
+		case alvo:
+			diff.Corretas = append(diff.Corretas, item)
+		default:
+			*pendentes = append(*pendentes, item)
+		}
+	}
+
+	for _, idLoja := range ativas {
+		classify(idLoja, models.StatusAtivo, &diff.Desbloquear)
+	}
+	for _, idLoja := range bloqueadas {
+		classify(idLoja, models.StatusBloqueado, &diff.Bloquear)
+	}
+	return diff
+}
@@ -0,0 +1,78 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"delivery-control/internal/models"
+)
+
+// SetStoreStatus leva a loja ao status alvo (ativo ou bloqueado) de forma idempotente: consulta o
+// status atual diretamente na plataforma e só executa a ativação/desativação quando ele difere do
+// alvo. Com versoes (If-Match), a operação só é aplicada se a loja ainda estiver em uma delas
+func (ps *PlatformService) SetStoreStatus(ctx context.Context, plataforma models.Plataforma, idLoja string, alvo models.Status, motivo string, versoes []string) (*models.RespostaDefinicaoStatus, error) {
+	loja, err := ps.GetStoreStatus(ctx, plataforma, idLoja)
+	if err != nil {
+		return nil, err
+	}
+	if loja.Status == models.StatusNaoEncontrado {
+		return nil, fmt.Errorf("%w: %s", ErrLojaNaoEncontrada, idLoja)
+	}
+	if len(versoes) > 0 {
+		if err := matchVersion(loja, versoes); err != nil {
+			return nil, err
+		}
+	}
+
+	response := &models.RespostaDefinicaoStatus{
+		Plataforma:     plataforma,
+		IdLoja:         idLoja,
+		StatusAnterior: loja.Status,
+		Status:         alvo,
+	}
+	// A loja é devolvida já no status alvo, com a versão que ele terá após a operação
+	dto := NewLoja(plataforma, *loja)
+	dto.Status = alvo
+	dto.IsActive = alvo == models.StatusAtivo
+	dto.Versao = StoreVersion(alvo, loja.Documento)
+	dto.Detalhes = nil
+	response.Loja = &dto
+	if loja.Status == alvo {
+		// Sem chamada à plataforma, mas a operação pedida é registrada como as demais
+		operacao := models.OperacaoAtivar
+		if alvo == models.StatusBloqueado {
+			operacao = models.OperacaoDesativar
+		}
+		ps.logAudit(operacao, plataforma, idLoja, motivo, nil)
+		response.Mensagem = mensagensJaNoStatus[alvo]
+		return response, nil
+	}
+
+	mensagem := "Loja ativada com sucesso"
+	switch alvo {
+	case models.StatusAtivo:
+		err = ps.activateStore(ctx, plataforma, idLoja)
+	case models.StatusBloqueado:
+		mensagem = "Loja desativada com sucesso"
+		err = ps.deactivateStore(ctx, plataforma, idLoja, motivo)
+	default:
+		return nil, fmt.Errorf("status alvo inválido: %s (use %s ou %s)", alvo, models.StatusAtivo, models.StatusBloqueado)
+	}
+
+	// A plataforma pode informar que a loja já estava no alvo se ela mudou após a consulta
+	if errors.Is(err, ErrLojaJaNoStatus) {
+		response.Mensagem = mensagensJaNoStatus[alvo]
+		return response, nil
+	}
+	if err != nil {
+		// O prazo do cliente (X-Request-Timeout) ou a desconexão interrompeu a chamada à plataforma
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("%w: %w", ErrPrazoEsgotado, err)
+		}
+		return nil, err
+	}
+	response.Alterado = true
+	response.Mensagem = mensagem
+	return response, nil
+}
@@ -0,0 +1,75 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"delivery-control/internal/models"
+	"delivery-control/internal/testutil/fakeplatform"
+)
+
+func TestSetStoreStatusAlterado(t *testing.T) {
+	tests := []struct {
+		name         string
+		resp         fakeplatform.Response
+		wantAlterado bool
+		wantMensagem string
+	}{
+		{
+			name:         "plataforma desbloqueia a loja",
+			resp:         fakeplatform.Response{Status: http.StatusAccepted},
+			wantAlterado: true,
+			wantMensagem: "Loja ativada com sucesso",
+		},
+		{
+			name:         "loja desbloqueada depois da consulta",
+			resp:         fakeplatform.JSON(http.StatusBadRequest, map[string]any{"code": "MERCHANT_ALREADY_UNBLOCKED"}),
+			wantMensagem: mensagensJaNoStatus[models.StatusAtivo],
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ps, _, deliveryVip := newTestService(t)
+			deliveryVip.SetResponse(fakeplatform.RouteDeliveryVipMerchants, fakeplatform.JSON(http.StatusOK, []any{
+				fakeplatform.DeliveryVipMerchant("merchant-1", "Loja", "12345678000190", "ACTIVATED", true),
+			}))
+			deliveryVip.SetResponse(fakeplatform.RouteDeliveryVipUnblock, tt.resp)
+
+			resposta, err := ps.SetStoreStatus(context.Background(), models.PlataformaDeliveryVip, "merchant-1", models.StatusAtivo, "", nil)
+			if err != nil {
+				t.Fatalf("SetStoreStatus() erro: %v", err)
+			}
+			if resposta.Alterado != tt.wantAlterado || resposta.Mensagem != tt.wantMensagem {
+				t.Errorf("alterado=%v mensagem=%q, esperado alterado=%v mensagem=%q", resposta.Alterado, resposta.Mensagem, tt.wantAlterado, tt.wantMensagem)
+			}
+		})
+	}
+}
+
+func TestSetStoreStatusStopsWhenContextIsCancelled(t *testing.T) {
+	ps, _, deliveryVip := newTestService(t)
+	deliveryVip.SetResponse(fakeplatform.RouteDeliveryVipMerchants, fakeplatform.JSON(http.StatusOK, []any{
+		fakeplatform.DeliveryVipMerchant("merchant-1", "Loja", "12345678000190", "ACTIVATED", false),
+	}))
+	deliveryVip.SetResponse(fakeplatform.RouteDeliveryVipBlock, fakeplatform.Response{Status: http.StatusAccepted, Delay: 5 * time.Second})
+
+	// O cliente desconecta enquanto o bloqueio aguarda a plataforma
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	inicio := time.Now()
+	_, err := ps.SetStoreStatus(ctx, models.PlataformaDeliveryVip, "merchant-1", models.StatusBloqueado, "", nil)
+	if !errors.Is(err, ErrPrazoEsgotado) || !errors.Is(err, context.Canceled) {
+		t.Fatalf("SetStoreStatus() erro = %v, esperado ErrPrazoEsgotado com context.Canceled", err)
+	}
+	if duracao := time.Since(inicio); duracao > 2*time.Second {
+		t.Errorf("SetStoreStatus() retornou em %s, esperado logo após o cancelamento", duracao)
+	}
+	if calls := deliveryVip.Calls(fakeplatform.RouteDeliveryVipBlock); calls != 1 {
+		t.Errorf("bloqueios na plataforma = %d, esperado 1", calls)
+	}
+}
@@ -0,0 +1,111 @@
+package services
+
+import (
+	"sync"
+	"time"
+
+	"delivery-control/internal/models"
+)
+
+// staleFraction é a fração do TTL a partir da qual o catálogo em cache é considerado desatualizado,
+// informado ao cliente para que saiba que o status pode ter mudado desde a consulta à plataforma
+const staleFraction = 0.8
+
+// catalogEntry representa o catálogo de lojas de uma plataforma armazenado em cache
+type catalogEntry struct {
+	lojas     map[string]models.StoreInfo
+	fetchedAt time.Time
+}
+
+// StatusCache mantém em memória o catálogo completo de lojas de cada plataforma por um TTL,
+// evitando buscar o catálogo inteiro na plataforma a cada consulta de status.
+// Os mapas armazenados são compartilhados entre os leitores e não devem ser modificados
+type StatusCache struct {
+	ttl     time.Duration
+	mu      sync.RWMutex
+	entries map[models.Plataforma]catalogEntry
+	// generations conta as invalidações de cada plataforma, para descartar catálogos buscados
+	// antes de uma alteração que terminou durante a busca
+	generations map[models.Plataforma]uint64
+}
+
+// NewStatusCache cria um novo cache de status. Um TTL zero desabilita o cache para Get e Age; o
+// último catálogo buscado continua disponível para GetWithin
+func NewStatusCache(ttl time.Duration) *StatusCache {
+	return &StatusCache{
+		ttl:         ttl,
+		entries:     make(map[models.Plataforma]catalogEntry),
+		generations: make(map[models.Plataforma]uint64),
+	}
+}
+
+// Get retorna o catálogo da plataforma, com o instante em que foi buscado, se ainda estiver dentro do TTL
+func (c *StatusCache) Get(plataforma models.Plataforma) (catalogEntry, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	entry, ok := c.entries[plataforma]
+	if !ok || c.ttl <= 0 || time.Since(entry.fetchedAt) > c.ttl {
+		return catalogEntry{}, false
+	}
+	return entry, true
+}
+
+// GetWithin retorna o catálogo da plataforma se ele foi buscado há no máximo maxAge, independente
+// do TTL. Permite que consultas repetidas em intervalos curtos compartilhem a mesma busca
+func (c *StatusCache) GetWithin(plataforma models.Plataforma, maxAge time.Duration) (map[string]models.StoreInfo, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	entry, ok := c.entries[plataforma]
+	if !ok || maxAge <= 0 || time.Since(entry.fetchedAt) > maxAge {
+		return nil, false
+	}
+	return entry.lojas, true
+}
+
+// Age retorna há quanto tempo o catálogo da entrada foi consultado e se ele já passou de
+// staleFraction do TTL. Retorna nil com o cache desabilitado, já que o catálogo foi buscado na hora
+func (c *StatusCache) Age(entry catalogEntry) *models.IdadeCache {
+	if c.ttl <= 0 || entry.fetchedAt.IsZero() {
+		return nil
+	}
+	age := max(time.Since(entry.fetchedAt), 0)
+	return &models.IdadeCache{
+		Idade:         age,
+		Desatualizado: age > time.Duration(float64(c.ttl)*staleFraction),
+	}
+}
+
+// Generation retorna a geração atual da plataforma, a ser lida antes de buscar o catálogo e
+// informada a Set
+func (c *StatusCache) Generation(plataforma models.Plataforma) uint64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.generations[plataforma]
+}
+
+// Set armazena o catálogo da plataforma buscado na geração informada. O catálogo é descartado se a
+// plataforma foi invalidada desde então, pois pode não refletir uma alteração feita durante a busca
+func (c *StatusCache) Set(plataforma models.Plataforma, entry catalogEntry, generation uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.generations[plataforma] != generation {
+		return
+	}
+	c.entries[plataforma] = entry
+}
+
+// Invalidate remove o catálogo da plataforma, retornando quantas lojas foram descartadas
+func (c *StatusCache) Invalidate(plataforma models.Plataforma) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generations[plataforma]++
+	entry, ok := c.entries[plataforma]
+	if !ok {
+		return 0
+	}
+	delete(c.entries, plataforma)
+	return len(entry.lojas)
+}
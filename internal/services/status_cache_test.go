@@ -0,0 +1,95 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"delivery-control/internal/config"
+	"delivery-control/internal/models"
+	"delivery-control/internal/testutil/fakeplatform"
+)
+
+func TestStatusCacheDiscardsCatalogFetchedBeforeInvalidate(t *testing.T) {
+	cache := NewStatusCache(time.Minute)
+	lojas := catalogEntry{lojas: map[string]models.StoreInfo{"loja-1": {Found: true, Status: models.StatusAtivo}}, fetchedAt: time.Now()}
+
+	// A busca começa, uma loja é alterada (Invalidate) e só depois a busca termina
+	generation := cache.Generation(models.PlataformaAnotaAi)
+	cache.Invalidate(models.PlataformaAnotaAi)
+	cache.Set(models.PlataformaAnotaAi, lojas, generation)
+
+	if _, ok := cache.Get(models.PlataformaAnotaAi); ok {
+		t.Fatal("catálogo buscado antes da invalidação não deveria ser armazenado")
+	}
+
+	cache.Set(models.PlataformaAnotaAi, lojas, cache.Generation(models.PlataformaAnotaAi))
+	if _, ok := cache.Get(models.PlataformaAnotaAi); !ok {
+		t.Fatal("catálogo buscado na geração atual deveria ser armazenado")
+	}
+}
+
+func TestStatusCacheInvalidateIsPerPlatform(t *testing.T) {
+	cache := NewStatusCache(time.Minute)
+	lojas := catalogEntry{lojas: map[string]models.StoreInfo{"loja-1": {Found: true}}, fetchedAt: time.Now()}
+	cache.Set(models.PlataformaAnotaAi, lojas, cache.Generation(models.PlataformaAnotaAi))
+	cache.Set(models.PlataformaDeliveryVip, lojas, cache.Generation(models.PlataformaDeliveryVip))
+
+	if removidas := cache.Invalidate(models.PlataformaAnotaAi); removidas != 1 {
+		t.Fatalf("Invalidate() = %d, esperado 1", removidas)
+	}
+	if _, ok := cache.Get(models.PlataformaDeliveryVip); !ok {
+		t.Fatal("a invalidação do AnotaAI não deveria afetar o DeliveryVip")
+	}
+}
+
+func TestCatalogAgeComesFromReturnedEntry(t *testing.T) {
+	ps, anotaAi, _ := newTestService(t, func(cfg *config.Config) { cfg.Cache.StatusTTL = time.Minute })
+	anotaAi.SetResponse(fakeplatform.RouteAnotaAiListPages, fakeplatform.JSON(http.StatusOK, fakeplatform.AnotaAiListPages(
+		fakeplatform.AnotaAiPage("page-1", "Loja", "12345678901", true),
+	)))
+
+	resposta, err := ps.GetMultipleStoreStatus(context.Background(), models.PlataformaAnotaAi, []string{"page-1"}, false, models.OrdenarPorID)
+	if err != nil {
+		t.Fatalf("GetMultipleStoreStatus() erro: %v", err)
+	}
+	if resposta.Cache == nil || resposta.Cache.Idade > time.Second || resposta.Cache.Desatualizado {
+		t.Fatalf("cache = %+v, esperado o catálogo recém-buscado", resposta.Cache)
+	}
+
+	// Um catálogo antigo em cache: a idade informada é a dele, mesmo que o cache mude depois
+	antigo := catalogEntry{lojas: map[string]models.StoreInfo{"page-1": {Found: true, Status: models.StatusBloqueado}}, fetchedAt: time.Now().Add(-50 * time.Second)}
+	ps.statusCache.Set(models.PlataformaAnotaAi, antigo, ps.statusCache.Generation(models.PlataformaAnotaAi))
+	resposta, err = ps.GetMultipleStoreStatus(context.Background(), models.PlataformaAnotaAi, []string{"page-1"}, false, models.OrdenarPorID)
+	if err != nil {
+		t.Fatalf("GetMultipleStoreStatus() erro: %v", err)
+	}
+	ps.statusCache.Invalidate(models.PlataformaAnotaAi)
+
+	if resposta.Lojas[0].Status != models.StatusBloqueado {
+		t.Fatalf("status = %q, esperado o do catálogo em cache", resposta.Lojas[0].Status)
+	}
+	if resposta.Cache == nil || resposta.Cache.Idade < 50*time.Second || !resposta.Cache.Desatualizado {
+		t.Errorf("cache = %+v, esperado a idade (50s, desatualizado) do catálogo usado na resposta", resposta.Cache)
+	}
+
+	var streamCache *models.IdadeCache
+	err = ps.StreamStoreStatus(context.Background(), models.PlataformaAnotaAi, []string{"page-1"}, func(_ models.StatusLojaDetalhes, cache *models.IdadeCache) error {
+		streamCache = cache
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("StreamStoreStatus() erro: %v", err)
+	}
+	if streamCache == nil || streamCache.Idade > time.Second {
+		t.Errorf("cache do stream = %+v, esperado o catálogo buscado após a invalidação", streamCache)
+	}
+}
+
+func TestCatalogAgeNilWithCacheDisabled(t *testing.T) {
+	cache := NewStatusCache(0)
+	if age := cache.Age(catalogEntry{fetchedAt: time.Now()}); age != nil {
+		t.Errorf("Age() = %+v, esperado nil com o cache desabilitado", age)
+	}
+}
@@ -0,0 +1,72 @@
+package services
+
+import (
+	"delivery-control/internal/models"
+	"delivery-control/internal/utils"
+)
+
+// NewLoja converte o status de uma loja no DTO Loja, o formato padrão das respostas com lojas. O
+// is_active vem do catálogo da plataforma (loja.IsActive), então não depende de loja.Detalhes, que só
+// é copiado quando presente (?verbose=true). O documento pode já estar formatado
+// (?formato_documento=formatado), por isso o tipo é calculado sobre os dígitos
+func NewLoja(plataforma models.Plataforma, loja models.StatusLojaDetalhes) models.Loja {
+	return models.Loja{
+		Id:            loja.IdLoja,
+		IdPlataforma:  loja.IdPlataforma,
+		Plataforma:    plataforma,
+		NomeFantasia:  loja.NomeFantasia,
+		Documento:     loja.Documento,
+		DocumentoTipo: utils.DocumentType(utils.CleanDocument(loja.Documento)),
+		Status:        loja.Status,
+		IsActive:      loja.IsActive,
+		Versao:        loja.Versao,
+		Detalhes:      loja.Detalhes,
+		Observacao:    loja.Observacao,
+	}
+}
+
+// NewRespostaLojas converte a consulta de status de múltiplas lojas para o formato padrão
+func NewRespostaLojas(response *models.RespostaStatusMultiplasLojas) *models.RespostaLojas {
+	lojas := make([]models.Loja, 0, len(response.Lojas))
+	for _, loja := range response.Lojas {
+		lojas = append(lojas, NewLoja(response.Plataforma, loja))
+	}
+	return &models.RespostaLojas{
+		Plataforma:    response.Plataforma,
+		Lojas:         lojas,
+		CatalogoVazio: response.CatalogoVazio,
+	}
+}
+
+// attachLojas preenche a loja de cada resultado de uma operação em lote. Os resultados não consultam
+// o catálogo, então a loja traz apenas o ID e o status resultante
+func attachLojas(response *models.RespostaOperacaoMultiplasLojas) {
+	for i := range response.Resultados {
+		resultado := &response.Resultados[i]
+		resultado.Loja = &models.Loja{
+			Id:         resultado.IdLoja,
+			Plataforma: response.Plataforma,
+			Status:     resultado.Status,
+			IsActive:   resultado.Status == models.StatusAtivo,
+		}
+	}
+}
+
+// LegacyDuplicates converte os documentos duplicados para o formato anterior ao DTO Loja (?formato=legado)
+func LegacyDuplicates(duplicadas []models.DocumentoDuplicado) []models.DocumentoDuplicadoLegado {
+	legado := make([]models.DocumentoDuplicadoLegado, 0, len(duplicadas))
+	for _, duplicado := range duplicadas {
+		lojas := make([]models.LojaDuplicada, 0, len(duplicado.Lojas))
+		for _, loja := range duplicado.Lojas {
+			lojas = append(lojas, models.LojaDuplicada{
+				Plataforma:   loja.Plataforma,
+				IdLoja:       loja.Id,
+				IdPlataforma: loja.IdPlataforma,
+				Status:       loja.Status,
+				NomeFantasia: loja.NomeFantasia,
+			})
+		}
+		legado = append(legado, models.DocumentoDuplicadoLegado{Documento: duplicado.Documento, Lojas: lojas})
+	}
+	return legado
+}
@@ -0,0 +1,40 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+
+	"delivery-control/internal/models"
+)
+
+// storeIDPattern são os caracteres aceitos em um ID de loja: os IDs das plataformas (ObjectId do
+// AnotaAI, UUID do DeliveryVip) usam apenas letras, dígitos e hífen. Barras, pontos e espaços
+// alterariam o caminho das URLs em que o ID é usado
+var storeIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// ErrIDInvalido indica um ID de loja com caracteres fora de storeIDPattern, que nunca é enviado à plataforma
+var ErrIDInvalido = errors.New("ID de loja inválido")
+
+// ValidStoreID indica se o ID pode ser usado com segurança nas URLs das plataformas
+func ValidStoreID(idLoja string) bool {
+	return storeIDPattern.MatchString(idLoja)
+}
+
+// checkStoreID recusa o ID antes que ele seja usado em uma URL da plataforma
+func checkStoreID(idLoja string) error {
+	if !ValidStoreID(idLoja) {
+		return fmt.Errorf("%w: %q contém caracteres não permitidos (use apenas letras, dígitos, '-' e '_')", ErrIDInvalido, idLoja)
+	}
+	return nil
+}
+
+// newStatusLojaInvalida monta o status de um ID malformado em uma consulta de várias lojas, que é
+// devolvido como invalido sem interromper a consulta das demais
+func newStatusLojaInvalida(idLoja string) models.StatusLojaDetalhes {
+	return models.StatusLojaDetalhes{
+		IdLoja:     idLoja,
+		Status:     models.StatusInvalido,
+		Observacao: "ID contém caracteres não permitidos (use apenas letras, dígitos, '-' e '_')",
+	}
+}
@@ -0,0 +1,40 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"delivery-control/internal/models"
+)
+
+// GetStatusSummary conta as lojas por status. Com IDs, considera apenas as lojas informadas (as
+// ausentes do catálogo contam como nao_encontrado); sem IDs, todas as lojas do catálogo.
+// TotalPlataforma é o tamanho do catálogo completo da plataforma (todas as páginas do AnotaAI,
+// inclusive as arquivadas, e todos os merchants do DeliveryVip), que pode incluir lojas que não
+// são gerenciadas por quem consulta
+func (ps *PlatformService) GetStatusSummary(ctx context.Context, plataforma models.Plataforma, idsLojas []string, incluirInativas bool) (*models.RespostaResumoStatus, error) {
+	if err := ps.checkOperation(plataforma, models.OperacaoStatus); err != nil {
+		return nil, err
+	}
+	if err := ps.checkConfigured(plataforma); err != nil {
+		return nil, err
+	}
+
+	statusMap, err := ps.loadCatalog(ctx, plataforma)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao consultar status das lojas em %s: %w", plataforma, err)
+	}
+
+	lojas := statusList(plataforma, statusMap, idsLojas, incluirInativas, models.OrdenarPorID)
+	porStatus := make(map[models.Status]int)
+	for _, loja := range lojas {
+		porStatus[loja.Status]++
+	}
+
+	return &models.RespostaResumoStatus{
+		Plataforma:      plataforma,
+		Total:           len(lojas),
+		PorStatus:       porStatus,
+		TotalPlataforma: len(statusMap),
+	}, nil
+}
@@ -0,0 +1,58 @@
+package services
+
+import (
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// tokenReady avisa as requisições que aguardam o token (TOKEN_WAIT_TIMEOUT) que o primeiro
+// login foi concluído. O token nunca volta a ficar vazio, então basta um único aviso
+type tokenReady struct {
+	once sync.Once
+	done chan struct{}
+	// renewals agrupa as renovações síncronas pedidas por requisições simultâneas sem token
+	renewals singleflight.Group
+}
+
+func newTokenReady() *tokenReady {
+	return &tokenReady{done: make(chan struct{})}
+}
+
+// notify é chamado pela renovação a cada token obtido; apenas o primeiro aviso tem efeito
+func (t *tokenReady) notify() {
+	t.once.Do(func() { close(t.done) })
+}
+
+// renewNow executa renew em nome de uma requisição que não pode aguardar o token
+// (TOKEN_WAIT_TIMEOUT=0). Requisições simultâneas compartilham um único login
+func (t *tokenReady) renewNow(renew func() error) error {
+	_, err, _ := t.renewals.Do("token", func() (any, error) {
+		select {
+		case <-t.done:
+			// Outro login terminou enquanto a requisição chegava; não há o que renovar
+			return nil, nil
+		default:
+		}
+		return nil, renew()
+	})
+	return err
+}
+
+// wait aguarda o primeiro token por até timeout, retornando false se ele não chegou a tempo
+func (t *tokenReady) wait(timeout time.Duration) bool {
+	if timeout <= 0 {
+		return false
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-t.done:
+		return true
+	case <-timer.C:
+		return false
+	}
+}
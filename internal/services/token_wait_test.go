@@ -0,0 +1,57 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"delivery-control/internal/models"
+	"delivery-control/internal/repository"
+	"delivery-control/internal/testutil/fakeplatform"
+)
+
+// waitCalls aguarda até que a rota tenha recebido ao menos n chamadas
+func waitCalls(t *testing.T, server *fakeplatform.Server, route string, n int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for server.Calls(route) < n {
+		if time.Now().After(deadline) {
+			t.Fatalf("rota %s recebeu %d chamadas, esperadas %d", route, server.Calls(route), n)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestZeroTokenWaitTimeoutRenewsSynchronously(t *testing.T) {
+	anotaAi := fakeplatform.NewAnotaAi()
+	deliveryVip := fakeplatform.NewDeliveryVip()
+	t.Cleanup(anotaAi.Close)
+	t.Cleanup(deliveryVip.Close)
+	// Credenciais inválidas não são repetidas, então o login inicial falha uma única vez
+	anotaAi.SetResponse(fakeplatform.RouteAnotaAiLogin, fakeplatform.JSON(http.StatusUnauthorized, map[string]any{"success": false}))
+	deliveryVip.SetResponse(fakeplatform.RouteDeliveryVipToken, fakeplatform.JSON(http.StatusUnauthorized, map[string]any{"error": "invalid_client"}))
+
+	cfg := fakeplatform.Config(anotaAi, deliveryVip)
+	cfg.Platforms.TokenWaitTimeout = 0
+	ps := NewPlatformService(cfg, repository.NewMemory())
+	waitCalls(t, anotaAi, fakeplatform.RouteAnotaAiLogin, 1)
+	waitCalls(t, deliveryVip, fakeplatform.RouteDeliveryVipToken, 1)
+
+	if _, err := ps.ActivateStore(context.Background(), models.PlataformaAnotaAi, "page-1"); err == nil {
+		t.Fatal("ActivateStore() deveria falhar enquanto o login falha")
+	}
+
+	anotaAi.SetResponse(fakeplatform.RouteAnotaAiLogin, fakeplatform.JSON(http.StatusOK, map[string]any{"success": true, "access_token": fakeplatform.AnotaAiToken}))
+	deliveryVip.SetResponse(fakeplatform.RouteDeliveryVipToken, fakeplatform.JSON(http.StatusOK, map[string]any{"access_token": fakeplatform.DeliveryVipToken, "token_type": "Bearer", "expires_in": 86400}))
+
+	if _, err := ps.ActivateStore(context.Background(), models.PlataformaAnotaAi, "page-1"); err != nil {
+		t.Fatalf("ActivateStore() deveria fazer o login na requisição, erro: %v", err)
+	}
+	if _, err := ps.DeactivateStore(context.Background(), models.PlataformaDeliveryVip, "merchant-1", ""); err != nil {
+		t.Fatalf("DeactivateStore() deveria fazer o login na requisição, erro: %v", err)
+	}
+	if got := anotaAi.Requests(fakeplatform.RouteAnotaAiActivate)[0].Header.Get("authorization"); got != fakeplatform.AnotaAiToken {
+		t.Errorf("header authorization = %q, esperado o token do login síncrono", got)
+	}
+}
@@ -0,0 +1,48 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"delivery-control/internal/models"
+)
+
+// ErrVersaoDivergente indica que a loja mudou desde a versão informada pelo cliente em If-Match
+var ErrVersaoDivergente = errors.New("a loja foi alterada desde a versão informada")
+
+// StoreVersion calcula a versão de uma loja a partir do status e do documento. Lojas no mesmo
+// estado têm sempre a mesma versão, independentemente de quando foram consultadas
+func StoreVersion(status models.Status, documento string) string {
+	sum := sha256.Sum256([]byte(string(status) + "\x00" + documento))
+	return hex.EncodeToString(sum[:8])
+}
+
+// CheckStoreVersion consulta a loja diretamente na plataforma e confirma que o estado atual
+// corresponde a uma das versões aceitas ("*" aceita qualquer loja existente). É uma verificação
+// otimista: a loja ainda pode mudar entre a verificação e a operação
+func (ps *PlatformService) CheckStoreVersion(ctx context.Context, plataforma models.Plataforma, idLoja string, versoes []string) error {
+	loja, err := ps.GetStoreStatus(ctx, plataforma, idLoja)
+	if err != nil {
+		return err
+	}
+	if loja.Status == models.StatusNaoEncontrado {
+		return fmt.Errorf("%w: %s", ErrLojaNaoEncontrada, idLoja)
+	}
+
+	return matchVersion(loja, versoes)
+}
+
+// matchVersion confirma que a loja está em uma das versões informadas em If-Match ("*" aceita
+// qualquer versão), retornando ErrVersaoDivergente com a versão atual caso contrário
+func matchVersion(loja *models.StatusLojaDetalhes, versoes []string) error {
+	atual := StoreVersion(loja.Status, loja.Documento)
+	for _, versao := range versoes {
+		if versao == "*" || versao == atual {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w (loja %s, versão atual %s, status %s)", ErrVersaoDivergente, loja.IdLoja, atual, loja.Status)
+}
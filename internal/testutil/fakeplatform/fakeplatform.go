@@ -0,0 +1,304 @@
+// Package fakeplatform fornece servidores httptest que emulam as APIs do AnotaAI e do
+// DeliveryVip, com respostas configuráveis por endpoint e por loja, para exercitar a
+// camada de serviços sem acessar as plataformas reais
+package fakeplatform
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"time"
+
+	"delivery-control/internal/config"
+)
+
+// Rotas emuladas do AnotaAI
+const (
+	RouteAnotaAiLogin     = "POST /noauth/partner/login"
+	RouteAnotaAiListPages = "GET /partnerauth/partner/listpages/v2"
+	RouteAnotaAiActivate  = "PUT /partnerauth/partner/active/{id}"
+	RouteAnotaAiBlock     = "PUT /partnerauth/partner/block/{id}"
+)
+
+// Rotas emuladas do DeliveryVip
+const (
+	RouteDeliveryVipToken     = "POST /authentication/v1/oauth/token"
+	RouteDeliveryVipMerchants = "GET /partner/v2/merchants"
+	RouteDeliveryVipBlock     = "POST /partner/v2/merchants/{id}/block"
+	RouteDeliveryVipUnblock   = "POST /partner/v2/merchants/{id}/unblock"
+)
+
+// Tokens devolvidos pelos endpoints de autenticação falsos
+const (
+	AnotaAiToken     = "fake-anotaai-token"
+	DeliveryVipToken = "fake-deliveryvip-token"
+)
+
+// Response define a resposta de um endpoint falso
+type Response struct {
+	Status      int
+	Body        string
+	ContentType string
+	Delay       time.Duration
+}
+
+// JSON cria uma resposta com o valor serializado em JSON
+func JSON(status int, v any) Response {
+	body, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	return Response{Status: status, Body: string(body), ContentType: "application/json"}
+}
+
+// RecordedRequest representa uma requisição recebida pelo servidor falso
+type RecordedRequest struct {
+	Method string
+	Path   string
+	ID     string
+	Header http.Header
+	Body   string
+}
+
+// Server emula uma plataforma com respostas configuráveis por rota e, opcionalmente, por loja
+type Server struct {
+	*httptest.Server
+
+	mu        sync.Mutex
+	responses map[string]Response
+	perStore  map[string]map[string]Response
+	requests  map[string][]RecordedRequest
+}
+
+// newServer cria um servidor falso com as respostas padrão informadas
+func newServer(defaults map[string]Response) *Server {
+	s := &Server{
+		responses: defaults,
+		perStore:  make(map[string]map[string]Response),
+		requests:  make(map[string][]RecordedRequest),
+	}
+
+	mux := http.NewServeMux()
+	for route := range defaults {
+		mux.HandleFunc(route, s.handler(route))
+	}
+	s.Server = httptest.NewServer(mux)
+
+	return s
+}
+
+// NewAnotaAi cria um servidor que emula o AnotaAI, com login válido, catálogo vazio
+// e ativação/bloqueio bem-sucedidos por padrão
+func NewAnotaAi() *Server {
+	return newServer(map[string]Response{
+		RouteAnotaAiLogin:     JSON(http.StatusOK, map[string]any{"success": true, "access_token": AnotaAiToken}),
+		RouteAnotaAiListPages: JSON(http.StatusOK, AnotaAiListPages()),
+		RouteAnotaAiActivate:  JSON(http.StatusOK, map[string]any{"success": true}),
+		RouteAnotaAiBlock:     JSON(http.StatusOK, map[string]any{"success": true}),
+	})
+}
+
+// NewDeliveryVip cria um servidor que emula o DeliveryVip, com token válido, catálogo vazio
+// e bloqueio/desbloqueio aceitos (202) por padrão
+func NewDeliveryVip() *Server {
+	return newServer(map[string]Response{
+		RouteDeliveryVipToken:     JSON(http.StatusOK, map[string]any{"access_token": DeliveryVipToken, "token_type": "Bearer", "expires_in": 86400}),
+		RouteDeliveryVipMerchants: JSON(http.StatusOK, []any{}),
+		RouteDeliveryVipBlock:     {Status: http.StatusAccepted},
+		RouteDeliveryVipUnblock:   {Status: http.StatusAccepted},
+	})
+}
+
+// SetResponse substitui a resposta de uma rota
+func (s *Server) SetResponse(route string, resp Response) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.responses[route] = resp
+}
+
+// SetStoreResponse define a resposta de uma rota apenas para a loja informada (rotas com {id})
+func (s *Server) SetStoreResponse(route, id string, resp Response) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.perStore[route] == nil {
+		s.perStore[route] = make(map[string]Response)
+	}
+	s.perStore[route][id] = resp
+}
+
+// Requests retorna as requisições recebidas em uma rota
+func (s *Server) Requests(route string) []RecordedRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]RecordedRequest(nil), s.requests[route]...)
+}
+
+// Calls retorna quantas requisições foram recebidas em uma rota
+func (s *Server) Calls(route string) int {
+	return len(s.Requests(route))
+}
+
+// handler registra a requisição e devolve a resposta configurada para a rota
+func (s *Server) handler(route string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		id := r.PathValue("id")
+
+		s.mu.Lock()
+		s.requests[route] = append(s.requests[route], RecordedRequest{
+			Method: r.Method,
+			Path:   r.URL.RequestURI(),
+			ID:     id,
+			Header: r.Header.Clone(),
+			Body:   string(body),
+		})
+		resp, ok := s.perStore[route][id]
+		if !ok {
+			resp = s.responses[route]
+		}
+		s.mu.Unlock()
+
+		if resp.Delay > 0 {
+			select {
+			case <-time.After(resp.Delay):
+			case <-r.Context().Done():
+				return
+			}
+		}
+
+		if resp.ContentType != "" {
+			w.Header().Set("Content-Type", resp.ContentType)
+		}
+		status := resp.Status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		w.WriteHeader(status)
+		_, _ = io.WriteString(w, resp.Body)
+	}
+}
+
+// AnotaAiPage monta uma página do AnotaAI no formato retornado pela listagem
+func AnotaAiPage(pageID, nome, documento string, ativa bool) map[string]any {
+	return map[string]any{
+		"_id":       pageID,
+		"page_id":   pageID,
+		"page_name": nome,
+		"active":    true,
+		"page": map[string]any{
+			"establishment": map[string]any{
+				"sign": map[string]any{
+					"active":   ativa,
+					"cpf_cnpj": documento,
+				},
+			},
+		},
+	}
+}
+
+// AnotaAiIncompletePage monta uma página do AnotaAI sem o bloco establishment, como as páginas
+// com o cadastro ainda incompleto
+func AnotaAiIncompletePage(pageID, nome string) map[string]any {
+	return map[string]any{
+		"_id":       pageID,
+		"page_id":   pageID,
+		"page_name": nome,
+		"active":    true,
+		"page":      map[string]any{},
+	}
+}
+
+// AnotaAiListPages monta a resposta de listagem do AnotaAI com as páginas informadas
+func AnotaAiListPages(pages ...map[string]any) map[string]any {
+	if pages == nil {
+		pages = []map[string]any{}
+	}
+	return map[string]any{
+		"success": true,
+		"info": map[string]any{
+			"docs":  pages,
+			"limit": 2000,
+			"page":  1,
+		},
+	}
+}
+
+// DeliveryVipMerchant monta um merchant do DeliveryVip no formato retornado pela listagem
+func DeliveryVipMerchant(id, nome, documento, subscriptionStatus string, blocked bool) map[string]any {
+	return map[string]any{
+		"id":         id,
+		"name":       nome,
+		"identifier": documento,
+		"subscription": map[string]any{
+			"status":  subscriptionStatus,
+			"blocked": blocked,
+		},
+	}
+}
+
+// DeliveryVipMerchantsPage monta a listagem do DeliveryVip no formato com wrapper
+// {"data": [...], "pagination": {...}}, usado pela API em algumas respostas
+func DeliveryVipMerchantsPage(page, totalPages, total int, merchants ...map[string]any) map[string]any {
+	if merchants == nil {
+		merchants = []map[string]any{}
+	}
+	return map[string]any{
+		"data": merchants,
+		"pagination": map[string]any{
+			"page":       page,
+			"limit":      len(merchants),
+			"total":      total,
+			"totalPages": totalPages,
+		},
+	}
+}
+
+// Config cria uma configuração apontando para os servidores falsos, com credenciais
+// preenchidas e cache de status desabilitado. Qualquer um dos servidores pode ser nil
+func Config(anotaAi, deliveryVip *Server) *config.Config {
+	cfg := &config.Config{
+		Server: config.ServerConfig{MaxRequestTimeout: time.Minute},
+		Auth:   config.AuthConfig{BearerToken: "test-token", FailMode: config.AuthFailClosed},
+		Platforms: config.PlatformConfig{
+			Enabled:        slices.Clone(config.KnownPlatforms),
+			AnotaAiURL:     "http://anotaai.invalid",
+			DeliveryVipURL: "http://deliveryvip.invalid",
+			AnotaAi: config.AnotaAiConfig{
+				Email:            "teste@example.com",
+				Password:         "senha",
+				TokenRenewal:     time.Hour,
+				LoginTimeout:     5 * time.Second,
+				StatusTimeout:    5 * time.Second,
+				MutationTimeout:  5 * time.Second,
+				Responses:        config.AnotaAiResponses(),
+				TokenHeader:      config.AnotaAiTokenHeader(),
+				IncompleteStatus: config.AnotaAiIncompletoStatus,
+			},
+			DeliveryVip: config.DeliveryVipConfig{
+				ClientID:        "client-id",
+				ClientSecret:    "client-secret",
+				TokenRenewal:    time.Hour,
+				LoginTimeout:    5 * time.Second,
+				StatusTimeout:   5 * time.Second,
+				MutationTimeout: 5 * time.Second,
+				Responses:       config.DeliveryVipResponses(),
+				TokenHeader:     config.DeliveryVipTokenHeader(),
+			},
+		},
+		Bulk:    config.BulkConfig{Concurrency: 5},
+		Cache:   config.CacheConfig{StreamInterval: time.Second, StreamMinInterval: 100 * time.Millisecond, StreamMaxInterval: time.Minute},
+		Storage: config.StorageConfig{Backend: config.StorageMemoria},
+	}
+
+	if anotaAi != nil {
+		cfg.Platforms.AnotaAiURL = anotaAi.URL
+	}
+	if deliveryVip != nil {
+		cfg.Platforms.DeliveryVipURL = deliveryVip.URL
+	}
+
+	return cfg
+}
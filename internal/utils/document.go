@@ -1,10 +1,102 @@
 package utils
 
-import "regexp"
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
 
 var digitOnlyRegex = regexp.MustCompile(`[^0-9]`)
 
+// Tamanhos dos documentos após a limpeza
+const (
+	cpfLength  = 11
+	cnpjLength = 14
+)
+
+// Tamanhos mínimos aceitos por NormalizeDocument antes de completar com zeros à esquerda.
+// Cobrem documentos que perderam até dois zeros iniciais (ex.: gravados como número)
+const (
+	cpfMinLength  = 9
+	cnpjMinLength = 12
+)
+
+// ErrDocumentoInvalido indica um documento que não pode ser normalizado para CPF ou CNPJ
+var ErrDocumentoInvalido = errors.New("documento inválido")
+
 // CleanDocument remove todos os símbolos e deixa apenas números
 func CleanDocument(doc string) string {
 	return digitOnlyRegex.ReplaceAllString(doc, "")
 }
+
+// NormalizeDocument limpa o documento e o completa com zeros à esquerda até o tamanho canônico
+// de CPF ou CNPJ, para comparações entre documentos. Usa o tipo informado ("cpf" ou "cnpj")
+// quando presente; sem tipo, documentos de até 11 dígitos são tratados como CPF e os maiores
+// como CNPJ. Retorna ErrDocumentoInvalido para documentos vazios, curtos demais ou longos demais.
+// Para exibição, use CleanDocument, que não rejeita nenhum valor
+func NormalizeDocument(doc, tipo string) (string, error) {
+	limpo := CleanDocument(doc)
+
+	tamanho := cnpjLength
+	switch strings.ToLower(strings.TrimSpace(tipo)) {
+	case "cpf":
+		tamanho = cpfLength
+	case "cnpj":
+	default:
+		if len(limpo) <= cpfLength {
+			tamanho = cpfLength
+		}
+	}
+
+	minimo := cnpjMinLength
+	if tamanho == cpfLength {
+		minimo = cpfMinLength
+	}
+	if len(limpo) < minimo || len(limpo) > tamanho {
+		return "", fmt.Errorf("%w: %d dígitos (esperado entre %d e %d)", ErrDocumentoInvalido, len(limpo), minimo, tamanho)
+	}
+
+	return strings.Repeat("0", tamanho-len(limpo)) + limpo, nil
+}
+
+// DocumentType retorna o tipo ("cpf" ou "cnpj") do documento já limpo pelo seu tamanho, ou vazio
+// quando ele não tem o tamanho de nenhum dos dois
+func DocumentType(doc string) string {
+	switch len(doc) {
+	case cpfLength:
+		return "cpf"
+	case cnpjLength:
+		return "cnpj"
+	default:
+		return ""
+	}
+}
+
+// IsValidDocument verifica o tamanho do documento já limpo usando o tipo informado pela
+// plataforma ("cpf" ou "cnpj") quando presente. Sem tipo, aceita tanto CPF quanto CNPJ
+func IsValidDocument(doc, tipo string) bool {
+	switch strings.ToLower(strings.TrimSpace(tipo)) {
+	case "cpf":
+		return len(doc) == cpfLength
+	case "cnpj":
+		return len(doc) == cnpjLength
+	default:
+		return len(doc) == cpfLength || len(doc) == cnpjLength
+	}
+}
+
+// FormatDocument aplica a máscara de CPF (000.000.000-00) ou CNPJ (00.000.000/0000-00) conforme o
+// tamanho do documento limpo. Documentos que não têm o tamanho de nenhum dos dois são retornados
+// sem alteração
+func FormatDocument(doc string) string {
+	limpo := CleanDocument(doc)
+	switch len(limpo) {
+	case cpfLength:
+		return limpo[:3] + "." + limpo[3:6] + "." + limpo[6:9] + "-" + limpo[9:]
+	case cnpjLength:
+		return limpo[:2] + "." + limpo[2:5] + "." + limpo[5:8] + "/" + limpo[8:12] + "-" + limpo[12:]
+	default:
+		return doc
+	}
+}
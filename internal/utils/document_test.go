@@ -0,0 +1,68 @@
+package utils
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNormalizeDocument(t *testing.T) {
+	tests := []struct {
+		name    string
+		doc     string
+		tipo    string
+		want    string
+		wantErr bool
+	}{
+		{name: "CPF formatado", doc: "123.456.789-01", want: "12345678901"},
+		{name: "CNPJ formatado", doc: "12.345.678/0001-90", want: "12345678000190"},
+		{name: "CPF sem um zero à esquerda", doc: "2345678901", want: "02345678901"},
+		{name: "CNPJ sem dois zeros à esquerda", doc: "345678000190", want: "00345678000190"},
+		{name: "tipo cpf informado", doc: "2345678901", tipo: "CPF", want: "02345678901"},
+		{name: "tipo cnpj completa documento curto", doc: "1234567000190", tipo: "cnpj", want: "01234567000190"},
+		{name: "vazio", doc: "", wantErr: true},
+		{name: "três dígitos", doc: "123", wantErr: true},
+		{name: "CPF curto demais", doc: "12345678", wantErr: true},
+		{name: "longo demais", doc: "123456780001901", wantErr: true},
+		{name: "tipo cpf com tamanho de CNPJ", doc: "12345678000190", tipo: "cpf", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizeDocument(tt.doc, tt.tipo)
+			if tt.wantErr {
+				if !errors.Is(err, ErrDocumentoInvalido) {
+					t.Fatalf("NormalizeDocument(%q, %q) erro = %v, esperado ErrDocumentoInvalido", tt.doc, tt.tipo, err)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Fatalf("NormalizeDocument(%q, %q) = (%q, %v), esperado %q", tt.doc, tt.tipo, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatDocument(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+		want string
+	}{
+		{name: "CPF", doc: "12345678901", want: "123.456.789-01"},
+		{name: "CNPJ", doc: "12345678000190", want: "12.345.678/0001-90"},
+		{name: "CPF já formatado", doc: "123.456.789-01", want: "123.456.789-01"},
+		{name: "CNPJ com outra pontuação", doc: "12 345 678 0001 90", want: "12.345.678/0001-90"},
+		{name: "vazio", doc: "", want: ""},
+		{name: "tamanho inválido", doc: "123456789", want: "123456789"},
+		{name: "tamanho inválido com pontuação", doc: "12.345-6", want: "12.345-6"},
+		{name: "longo demais", doc: "123456780001901", want: "123456780001901"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FormatDocument(tt.doc); got != tt.want {
+				t.Errorf("FormatDocument(%q) = %q, esperado %q", tt.doc, got, tt.want)
+			}
+		})
+	}
+}
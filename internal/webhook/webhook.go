@@ -0,0 +1,237 @@
+// Package webhook envia notificações assinadas sobre as operações executadas nas lojas
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"delivery-control/internal/config"
+	"delivery-control/internal/metrics"
+)
+
+// Eventos enviados no campo "evento" da notificação
+const (
+	EventoLojaOperacao  = "loja.operacao"
+	EventoLoteConcluido = "lote.concluido"
+	EventoTeste         = "webhook.teste"
+)
+
+// Headers enviados em cada notificação
+const (
+	HeaderEvento     = "X-Webhook-Evento"
+	HeaderAssinatura = "X-Webhook-Assinatura"
+)
+
+// Notificacao é o corpo enviado ao endpoint configurado em WEBHOOK_URL
+type Notificacao struct {
+	Evento    string `json:"evento"`
+	Timestamp string `json:"timestamp"`
+	Dados     any    `json:"dados"`
+}
+
+// Entrega é o resultado da entrega de um evento, considerando todas as tentativas
+type Entrega struct {
+	// StatusHTTP é o status da última resposta recebida (0 se nenhuma resposta chegou)
+	StatusHTTP int
+	Tentativas int
+	Duracao    time.Duration
+	// Err é o erro da última tentativa, nil se o evento foi entregue
+	Err error
+}
+
+// Notifier entrega as notificações em segundo plano, com novas tentativas em caso de falha. Os
+// eventos passam por uma fila limitada (WEBHOOK_QUEUE_SIZE) consumida por WEBHOOK_WORKERS entregas
+// simultâneas, para que um endpoint lento não acumule uma goroutine por evento
+type Notifier struct {
+	config config.WebhookConfig
+	client *http.Client
+
+	// retryDelay é o intervalo antes da primeira nova tentativa, dobrado a cada falha
+	retryDelay time.Duration
+
+	queue   chan envio
+	dropped atomic.Int64
+}
+
+// envio é um evento já serializado aguardando entrega na fila
+type envio struct {
+	evento string
+	body   []byte
+}
+
+// New cria um notificador e inicia as entregas em segundo plano. Sem WEBHOOK_URL o notificador
+// fica desabilitado e descarta os eventos
+func New(cfg config.WebhookConfig) *Notifier {
+	n := &Notifier{
+		config:     cfg,
+		client:     &http.Client{Timeout: cfg.Timeout},
+		retryDelay: time.Second,
+	}
+	if cfg.URL != "" {
+		n.queue = make(chan envio, max(cfg.QueueSize, 1))
+		for range max(cfg.Workers, 1) {
+			go n.work()
+		}
+	}
+	return n
+}
+
+// work entrega os eventos da fila, um de cada vez
+func (n *Notifier) work() {
+	for e := range n.queue {
+		n.deliver(context.Background(), e.evento, e.body)
+	}
+}
+
+// Enabled indica se há uma WEBHOOK_URL configurada
+func (n *Notifier) Enabled() bool {
+	return n.config.URL != ""
+}
+
+// URL retorna o endpoint que recebe as notificações
+func (n *Notifier) URL() string {
+	return n.config.URL
+}
+
+// NotifyStores indica se devem ser enviados eventos individuais por loja
+func (n *Notifier) NotifyStores() bool {
+	return n.config.URL != "" && (n.config.Mode == config.WebhookModoLoja || n.config.Mode == config.WebhookModoAmbos)
+}
+
+// NotifyBatches indica se deve ser enviado um evento ao concluir cada lote
+func (n *Notifier) NotifyBatches() bool {
+	return n.config.URL != "" && (n.config.Mode == config.WebhookModoLote || n.config.Mode == config.WebhookModoAmbos)
+}
+
+// Dropped retorna quantos eventos foram descartados com a fila de entrega cheia
+func (n *Notifier) Dropped() int64 {
+	return n.dropped.Load()
+}
+
+// Send agenda o envio do evento sem bloquear quem chamou. Com a fila cheia, o evento é descartado
+// e contado em Dropped e na métrica control_api_webhook_events_dropped_total
+func (n *Notifier) Send(evento string, dados any) {
+	if n.config.URL == "" {
+		return
+	}
+
+	body, err := encode(evento, dados)
+	if err != nil {
+		log.Printf("[Webhook] %v", err)
+		return
+	}
+
+	select {
+	case n.queue <- envio{evento: evento, body: body}:
+	default:
+		total := n.dropped.Add(1)
+		metrics.RecordWebhookDropped()
+		log.Printf("[Webhook] Fila de entrega cheia (%d eventos), evento %s descartado (%d descartados)", cap(n.queue), evento, total)
+	}
+}
+
+// Deliver envia o evento e aguarda a entrega, com as mesmas assinatura e novas tentativas dos
+// eventos reais. Usado para testar a configuração do webhook; com ctx encerrado (cliente
+// desconectado ou X-Request-Timeout), a entrega é interrompida e Entrega.Err traz o erro do contexto
+func (n *Notifier) Deliver(ctx context.Context, evento string, dados any) (Entrega, error) {
+	if n.config.URL == "" {
+		return Entrega{}, fmt.Errorf("webhook não configurado (WEBHOOK_URL vazia)")
+	}
+
+	body, err := encode(evento, dados)
+	if err != nil {
+		return Entrega{}, err
+	}
+	return n.deliver(ctx, evento, body), nil
+}
+
+// encode serializa a notificação enviada no corpo da requisição
+func encode(evento string, dados any) ([]byte, error) {
+	body, err := json.Marshal(Notificacao{
+		Evento:    evento,
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+		Dados:     dados,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("erro ao serializar evento %s: %w", evento, err)
+	}
+	return body, nil
+}
+
+// deliver envia o evento, tentando novamente com backoff exponencial até WEBHOOK_MAX_RETRIES vezes.
+// O envio e a espera entre as tentativas terminam junto com ctx
+func (n *Notifier) deliver(ctx context.Context, evento string, body []byte) Entrega {
+	inicio := time.Now()
+	delay := n.retryDelay
+	for tentativa := 0; ; tentativa++ {
+		statusCode, err := n.post(ctx, evento, body)
+		if err == nil {
+			return Entrega{StatusHTTP: statusCode, Tentativas: tentativa + 1, Duracao: time.Since(inicio)}
+		}
+		if ctx.Err() != nil || tentativa >= n.config.MaxRetries {
+			log.Printf("[Webhook] Evento %s descartado após %d tentativas: %v", evento, tentativa+1, err)
+			return Entrega{StatusHTTP: statusCode, Tentativas: tentativa + 1, Duracao: time.Since(inicio), Err: err}
+		}
+
+		log.Printf("[Webhook] Falha ao enviar evento %s (tentativa %d), nova tentativa em %s: %v", evento, tentativa+1, delay, err)
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			log.Printf("[Webhook] Evento %s descartado após %d tentativas: %v", evento, tentativa+1, ctx.Err())
+			return Entrega{StatusHTTP: statusCode, Tentativas: tentativa + 1, Duracao: time.Since(inicio), Err: ctx.Err()}
+		case <-timer.C:
+		}
+		delay *= 2
+	}
+}
+
+// post faz uma tentativa de entrega, retornando o status HTTP recebido (0 sem resposta);
+// qualquer resposta fora da faixa 2xx é considerada falha
+func (n *Notifier) post(ctx context.Context, evento string, body []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.config.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("erro ao criar requisição: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvento, evento)
+	if n.config.Secret != "" {
+		req.Header.Set(HeaderAssinatura, Sign(n.config.Secret, body))
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("erro ao fazer requisição: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("status HTTP %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// Sign calcula a assinatura enviada em X-Webhook-Assinatura: "sha256=" seguido do HMAC-SHA256
+// do corpo em hexadecimal, usando WEBHOOK_SECRET como chave
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// NewBatchID gera o identificador de um lote, usado para correlacionar a resposta e os eventos
+func NewBatchID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,45 @@
+package webhook
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"delivery-control/internal/config"
+)
+
+func TestSendDropsEventsWhenQueueIsFull(t *testing.T) {
+	recebidos := make(chan string, 10)
+	liberar := make(chan struct{})
+	destino := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		recebidos <- r.Header.Get(HeaderEvento)
+		<-liberar
+	}))
+	t.Cleanup(destino.Close)
+
+	n := New(config.WebhookConfig{URL: destino.URL, Timeout: 5 * time.Second, QueueSize: 1, Workers: 1})
+
+	// O único worker fica preso no primeiro evento; o segundo ocupa a fila e o terceiro é descartado
+	n.Send("evento.1", nil)
+	select {
+	case <-recebidos:
+	case <-time.After(2 * time.Second):
+		t.Fatal("primeiro evento não chegou ao destino")
+	}
+	n.Send("evento.2", nil)
+	n.Send("evento.3", nil)
+	if dropped := n.Dropped(); dropped != 1 {
+		t.Fatalf("Dropped() = %d, esperado 1", dropped)
+	}
+
+	close(liberar)
+	select {
+	case evento := <-recebidos:
+		if evento != "evento.2" {
+			t.Errorf("segundo evento entregue = %q, esperado evento.2", evento)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("evento da fila não foi entregue")
+	}
+}